@@ -0,0 +1,172 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestCmdAccessorsResp3Double(t *testing.T) {
+	client := NewClientStub([]byte(",3.5\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	cmd := client.Do(ctx, "zscore", "key", "member")
+	if f, err := cmd.Float64(); err != nil || f != 3.5 {
+		t.Fatalf("got %v, %v, expected 3.5", f, err)
+	}
+	if f, err := cmd.Float32(); err != nil || f != 3.5 {
+		t.Fatalf("got %v, %v, expected 3.5", f, err)
+	}
+	if s, err := cmd.Text(); err != nil || s != "3.5" {
+		t.Fatalf("got %q, %v, expected 3.5", s, err)
+	}
+}
+
+func TestCmdAccessorsResp3Bool(t *testing.T) {
+	client := NewClientStub([]byte("#t\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	if b, err := client.Do(ctx, "cmd").Bool(); err != nil || !b {
+		t.Fatalf("got %v, %v, expected true", b, err)
+	}
+}
+
+func TestCmdAccessorsDuration(t *testing.T) {
+	client := NewClientStub([]byte(":1500\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	if d, err := client.Do(ctx, "pttl", "key").Duration(time.Millisecond); err != nil || d != 1500*time.Millisecond {
+		t.Fatalf("got %v, %v, expected 1.5s", d, err)
+	}
+	if d, err := client.PTTL(ctx, "key").Result(); err != nil || d != 1500*time.Millisecond {
+		t.Fatalf("got %v, %v, expected 1.5s", d, err)
+	}
+	if d, err := client.Incr(ctx, "key").Duration(time.Second); err != nil || d != 1500*time.Second {
+		t.Fatalf("got %v, %v, expected 1500s", d, err)
+	}
+	if d, err := client.Get(ctx, "key").Duration(time.Second); err != nil || d != 1500*time.Second {
+		t.Fatalf("got %v, %v, expected 1500s", d, err)
+	}
+}
+
+func TestCmdAccessorsTime(t *testing.T) {
+	client := NewClientStub([]byte(":1700000000\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	if tm, err := client.Do(ctx, "expiretime", "key").Time(); err != nil || !tm.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("got %v, %v", tm, err)
+	}
+}
+
+func TestCmdStringMap(t *testing.T) {
+	for _, resp := range []string{
+		"%2\r\n+a\r\n+1\r\n+b\r\n+2\r\n",
+		"*4\r\n$1\r\na\r\n$1\r\n1\r\n$1\r\nb\r\n$1\r\n2\r\n",
+	} {
+		client := NewClientStub([]byte(resp)).Cmdable.(*Client)
+
+		m, err := client.Do(ctx, "hgetall", "key").StringMap()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(m) != 2 || m["a"] != "1" || m["b"] != "2" {
+			t.Fatalf("got %v", m)
+		}
+		client.Close()
+	}
+}
+
+func TestCmdReset(t *testing.T) {
+	errClient := NewClientStub([]byte("-ERR failed\r\n")).Cmdable.(*Client)
+	defer errClient.Close()
+	client := NewClientStub([]byte("$5\r\nvalue\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	get := NewStringCmd(ctx, "get", "key1")
+	pipe := errClient.Pipeline()
+	_ = pipe.Process(ctx, get)
+	_, _ = pipe.Exec(ctx)
+	if err := get.Err(); err == nil || err.Error() != "ERR failed" {
+		t.Fatalf("got %v, expected ERR failed", err)
+	}
+
+	get.Reset(ctx, "get", "key2")
+	if get.Val() != "" || get.Err() != nil || get.Args()[1] != "key2" {
+		t.Fatalf("got %v, expected a reset command", get)
+	}
+
+	pipe = client.Pipeline()
+	_ = pipe.Process(ctx, get)
+	if _, err := pipe.Exec(ctx); err != nil || get.Val() != "value" {
+		t.Fatalf("got %q, %v, expected value", get.Val(), err)
+	}
+}
+
+func TestCmdAccessorsResp3BigNumber(t *testing.T) {
+	const big = "3492890328409238509324850943850943825024385"
+
+	client := NewClientStub([]byte("(" + big + "\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	cmd := client.Do(ctx, "cmd")
+	if i, err := cmd.BigInt(); err != nil || i.String() != big {
+		t.Fatalf("got %v, %v, expected %s", i, err, big)
+	}
+	if f, err := cmd.Float64(); err != nil || f != 3.492890328409238509324850943850943825024385e42 {
+		t.Fatalf("got %v, %v", f, err)
+	}
+	if _, err := cmd.Int64(); err == nil {
+		t.Fatal("expected an out of range error")
+	}
+}
+
+func TestIncrByDecimal(t *testing.T) {
+	const val = "10.00000000000000001"
+
+	client := NewClientStub([]byte("$20\r\n" + val + "\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	cmd := client.IncrByDecimal(ctx, "balance", "0.00000000000000001")
+	f, err := cmd.BigFloat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := f.Text('f', 17); s != val {
+		t.Fatalf("got %s, expected %s", s, val)
+	}
+	if ff, _ := f.Float64(); ff != 10 {
+		t.Fatalf("got %v, expected 10 once rounded to float64", ff)
+	}
+
+	if err := client.HIncrByDecimal(ctx, "hash", "field", "1").Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStringCmdBytesCopy(t *testing.T) {
+	stub := &ClientStub{resp: []byte("$5\r\nhello\r\n")}
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return stub.stubConn(initHello), nil
+		},
+		DisableIndentity:   true,
+		MaxInternedReplies: 10,
+	})
+	defer client.Close()
+
+	// The interned replies share the same string.
+	get1, get2 := client.Get(ctx, "key1"), client.Get(ctx, "key2")
+	b, err := get1.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[0] = 'j'
+	if get1.Val() != "hello" || get2.Val() != "hello" {
+		t.Fatalf("got %q and %q, expected the replies to be unchanged", get1.Val(), get2.Val())
+	}
+
+	if b, err := get2.BytesUnsafe(); err != nil || string(b) != "hello" {
+		t.Fatalf("got %q, %v, expected hello", b, err)
+	}
+}
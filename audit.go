@@ -0,0 +1,140 @@
+package redis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal/rand"
+)
+
+// AuditRecord describes a sampled command, see Options.AuditSampleRate.
+// The values of the command are never recorded.
+type AuditRecord struct {
+	// Time when the command completed.
+	Time time.Time
+	// Name is the full name of the command, e.g. "get" or "cluster info".
+	Name string
+	// KeyPattern is the first key of the command with the numbers replaced by "*",
+	// e.g. "user:*:profile" for "user:42:profile".
+	KeyPattern string
+	// Latency of the command including retries. Commands sent in a pipeline
+	// share the latency of the whole pipeline.
+	Latency time.Duration
+	// Err is the error of the command or empty on success.
+	Err string
+}
+
+// auditLog keeps the latest sampled commands, shared by all clones of the options.
+type auditLog struct {
+	sampleRate float64
+
+	mu      sync.Mutex
+	records []AuditRecord
+	next    int
+	full    bool
+}
+
+func newAuditLog(sampleRate float64, size int) *auditLog {
+	return &auditLog{
+		sampleRate: sampleRate,
+		records:    make([]AuditRecord, size),
+	}
+}
+
+// add samples the cmd that completed with the err.
+func (l *auditLog) add(cmd Cmder, err error, latency time.Duration) {
+	if l.sampleRate < 1 && rand.Float64() >= l.sampleRate {
+		return
+	}
+	rec := AuditRecord{
+		Time:       time.Now(),
+		Name:       cmd.FullName(),
+		KeyPattern: auditKeyPattern(cmd),
+		Latency:    latency,
+	}
+	if err != nil {
+		rec.Err = err.Error()
+	}
+
+	l.mu.Lock()
+	l.records[l.next] = rec
+	l.next++
+	if l.next == len(l.records) {
+		l.next = 0
+		l.full = true
+	}
+	l.mu.Unlock()
+}
+
+// addCmds samples the cmds of a pipeline.
+func (l *auditLog) addCmds(cmds []Cmder, latency time.Duration) {
+	for _, cmd := range cmds {
+		l.add(cmd, cmd.Err(), latency)
+	}
+}
+
+// recent returns the records from the oldest to the newest.
+func (l *auditLog) recent() []AuditRecord {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.full {
+		return append([]AuditRecord(nil), l.records[:l.next]...)
+	}
+	records := make([]AuditRecord, 0, len(l.records))
+	records = append(records, l.records[l.next:]...)
+	return append(records, l.records[:l.next]...)
+}
+
+func auditKeyPattern(cmd Cmder) string {
+	switch cmd.Name() {
+	case "auth", "hello":
+		// The arguments are credentials.
+		return ""
+	}
+	pos := cmdFirstKeyPos(cmd)
+	if pos == 0 || pos >= len(cmd.Args()) {
+		return ""
+	}
+
+	return keyPattern(cmd.stringArg(pos))
+}
+
+// keyPattern replaces the runs of digits in the key with "*", e.g. "user:*:profile".
+func keyPattern(key string) string {
+	b := make([]byte, 0, len(key))
+	var digits bool
+	for i := 0; i < len(key); i++ {
+		if key[i] >= '0' && key[i] <= '9' {
+			if !digits {
+				b = append(b, '*')
+			}
+			digits = true
+			continue
+		}
+		digits = false
+		b = append(b, key[i])
+	}
+	return string(b)
+}
+
+// RecentCommands returns the latest commands sampled by Options.AuditSampleRate,
+// from the oldest to the newest.
+func (c *Client) RecentCommands() []AuditRecord {
+	return c.opt.audit.recent()
+}
+
+// RecentCommands returns the latest commands sent to all cluster nodes,
+// see Options.AuditSampleRate.
+func (c *ClusterClient) RecentCommands() []AuditRecord {
+	return c.opt.audit.recent()
+}
+
+// RecentCommands returns the latest commands sent to all ring shards,
+// see Options.AuditSampleRate.
+func (c *Ring) RecentCommands() []AuditRecord {
+	return c.opt.audit.recent()
+}
@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestRecentCommands(t *testing.T) {
+	client := NewClient(&Options{
+		Addr: "stub:6379",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &ConnStub{init: initHello, resp: []byte("$-1\r\n")}, nil
+		},
+		AuditSampleRate:  1,
+		AuditBufferSize:  3,
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	for _, key := range []string{"user:1:name", "user:22:name", "order:333", "session:a1b2"} {
+		if err := client.Get(ctx, key).Err(); err != Nil {
+			t.Fatalf("got %v, expected redis.Nil", err)
+		}
+	}
+
+	recent := client.RecentCommands()
+	if len(recent) != 3 {
+		t.Fatalf("got %d records, expected 3", len(recent))
+	}
+	// The oldest GET was overwritten, HELLO was the first.
+	want := []string{"user:*:name", "order:*", "session:a*b*"}
+	for i, rec := range recent {
+		if rec.Name != "get" || rec.KeyPattern != want[i] || rec.Err != Nil.Error() || rec.Latency <= 0 {
+			t.Fatalf("got %+v, expected GET %s", rec, want[i])
+		}
+	}
+}
+
+func TestAuditKeyPattern(t *testing.T) {
+	tests := []struct {
+		cmd  Cmder
+		want string
+	}{
+		{NewStringCmd(ctx, "get", "user:42:profile"), "user:*:profile"},
+		{NewStatusCmd(ctx, "set", "12", "value"), "*"},
+		{NewStatusCmd(ctx, "ping"), ""},
+		{NewStatusCmd(ctx, "auth", "user", "secret"), ""},
+	}
+	for _, tt := range tests {
+		if got := auditKeyPattern(tt.cmd); got != tt.want {
+			t.Errorf("%s: got %q, expected %q", tt.cmd.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestRecentCommandsDisabled(t *testing.T) {
+	client := NewClient(&Options{
+		Addr: "stub:6379",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &ConnStub{init: initHello, resp: []byte("+OK\r\n")}, nil
+		},
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	_ = client.Ping(ctx).Err()
+	if recent := client.RecentCommands(); recent != nil {
+		t.Fatalf("got %+v, expected no records", recent)
+	}
+}
@@ -0,0 +1,152 @@
+package redis
+
+import (
+	"strconv"
+	"strings"
+)
+
+func newAuthorizeCmdInfo(opt *Options, info map[string]*CommandInfo, cmd Cmder) CmdInfo {
+	cmdInfo := info[cmd.Name()]
+	keys, ok := cmdKeys(cmdInfo, cmd)
+	return CmdInfo{
+		Cmd:         cmd,
+		Addr:        opt.Addr,
+		Keys:        keys,
+		KeysUnknown: !ok,
+		Info:        cmdInfo,
+	}
+}
+
+// cmdKeys returns the keys of the command and whether they are all known.
+// The keys of the commands unknown to the server and of the commands with
+// movable keys other than the ones parsed below, e.g. SORT with BY or GET
+// and MIGRATE, are not known.
+func cmdKeys(info *CommandInfo, cmd Cmder) ([]string, bool) {
+	args := cmd.Args()
+
+	switch cmd.Name() {
+	case "eval", "evalsha", "eval_ro", "evalsha_ro", "fcall", "fcall_ro",
+		"blmpop", "bzmpop":
+		// EVAL script numkeys key [key ...]
+		return cmdNumKeys(cmd, 2)
+	case "zunion", "zinter", "zdiff", "zintercard", "sintercard", "lmpop", "zmpop":
+		// ZUNION numkeys key [key ...]
+		return cmdNumKeys(cmd, 1)
+	case "zunionstore", "zinterstore", "zdiffstore":
+		// ZUNIONSTORE destination numkeys key [key ...]
+		keys, ok := cmdNumKeys(cmd, 2)
+		if len(args) < 2 {
+			return keys, false
+		}
+		return append([]string{cmd.stringArg(1)}, keys...), ok
+	case "xread", "xreadgroup":
+		// XREAD ... STREAMS key [key ...] id [id ...]
+		for i := 1; i < len(args); i++ {
+			if strings.EqualFold(cmd.stringArg(i), "streams") {
+				n := len(args) - i - 1
+				if n == 0 || n%2 != 0 {
+					return nil, false
+				}
+				return cmdStringArgs(cmd, i+1, i+n/2, 1), true
+			}
+		}
+		return nil, false
+	case "sort", "sort_ro", "georadius", "georadiusbymember":
+		// The key is followed by the options, and STORE or STOREDIST stores
+		// the result in a key. BY and GET of SORT read the keys of a pattern.
+		if len(args) < 2 {
+			return nil, false
+		}
+		keys := []string{cmd.stringArg(1)}
+		for i := 2; i < len(args); i++ {
+			switch strings.ToLower(cmd.stringArg(i)) {
+			case "store", "storedist":
+				if i+1 == len(args) {
+					return keys, false
+				}
+				i++
+				keys = append(keys, cmd.stringArg(i))
+			case "by", "get":
+				if cmd.Name() == "sort" || cmd.Name() == "sort_ro" {
+					return keys, false
+				}
+			}
+		}
+		return keys, true
+	}
+
+	if info == nil {
+		return nil, false
+	}
+	known := !hasFlag(info.Flags, "movablekeys")
+	if info.FirstKeyPos <= 0 {
+		return nil, known
+	}
+	last := int(info.LastKeyPos)
+	if last < 0 {
+		last += len(args)
+	}
+	step := int(info.StepCount)
+	if step <= 0 {
+		step = 1
+	}
+	return cmdStringArgs(cmd, int(info.FirstKeyPos), last, step), known
+}
+
+// cmdNumKeys returns the keys following the numkeys argument at the position.
+func cmdNumKeys(cmd Cmder, pos int) ([]string, bool) {
+	numKeys, err := strconv.Atoi(cmd.stringArg(pos))
+	if err != nil || numKeys < 0 || pos+1+numKeys > len(cmd.Args()) {
+		return nil, false
+	}
+	if numKeys == 0 {
+		return nil, true
+	}
+	return cmdStringArgs(cmd, pos+1, pos+numKeys, 1), true
+}
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func cmdStringArgs(cmd Cmder, first, last, step int) []string {
+	if n := len(cmd.Args()); last >= n {
+		last = n - 1
+	}
+	var keys []string
+	for pos := first; pos <= last; pos += step {
+		keys = append(keys, cmd.stringArg(pos))
+	}
+	return keys
+}
+
+// authorizeCmds returns the first error returned by Options.Authorize.
+func authorizeCmds(opt *Options, info map[string]*CommandInfo, cmds []Cmder) error {
+	for _, cmd := range cmds {
+		if err := opt.Authorize(newAuthorizeCmdInfo(opt, info, cmd)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasKeyPrefix reports whether all keys of the command start with prefix.
+// It can be used by Options.Authorize to keep a tenant within its keyspace.
+// It fails closed: it returns false if the keys are not known, see
+// CmdInfo.KeysUnknown.
+func (info CmdInfo) HasKeyPrefix(prefix string) bool {
+	if info.KeysUnknown {
+		return false
+	}
+	for _, key := range info.Keys {
+		if !strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+	return true
+}
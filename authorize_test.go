@@ -0,0 +1,117 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	errForbidden := errors.New("forbidden")
+
+	var sent int32
+	var authorized []CmdInfo
+	client := NewClient(&Options{
+		Addr: "stub:6379",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return readOnlyServer(&sent), nil
+		},
+		Authorize: func(cmd CmdInfo) error {
+			authorized = append(authorized, cmd)
+			if cmd.Cmd.Name() == "keys" || !cmd.HasKeyPrefix("tenant:") {
+				return errForbidden
+			}
+			return nil
+		},
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	if err := client.Set(ctx, "tenant:a", "value", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Eval(ctx, "return 1", []string{"tenant:a", "tenant:b"}, "arg").Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Get(ctx, "other:a").Err(); err != errForbidden {
+		t.Fatalf("got %v, expected %v", err, errForbidden)
+	}
+	if err := client.Keys(ctx, "*").Err(); err != errForbidden {
+		t.Fatalf("got %v, expected %v", err, errForbidden)
+	}
+
+	wanted := [][]string{
+		{"tenant:a"},
+		{"tenant:a", "tenant:b"},
+		{"other:a"},
+		nil,
+	}
+	if len(authorized) != len(wanted) {
+		t.Fatalf("got %d authorized commands, expected %d", len(authorized), len(wanted))
+	}
+	for i, cmd := range authorized {
+		if !reflect.DeepEqual(cmd.Keys, wanted[i]) {
+			t.Fatalf("%s: got keys %q, expected %q", cmd.Cmd.Name(), cmd.Keys, wanted[i])
+		}
+		if cmd.Addr != "stub:6379" {
+			t.Fatalf("got addr %q", cmd.Addr)
+		}
+	}
+	if authorized[0].Info == nil || authorized[3].Info != nil {
+		t.Fatal("expected the info of SET only")
+	}
+
+	// A pipeline with a forbidden command is not sent.
+	_, err := client.Pipelined(ctx, func(pipe Pipeliner) error {
+		pipe.Get(ctx, "tenant:a")
+		pipe.Get(ctx, "other:a")
+		return nil
+	})
+	if err != errForbidden {
+		t.Fatalf("got %v, expected %v", err, errForbidden)
+	}
+
+	if n := atomic.LoadInt32(&sent); n != 2 {
+		t.Fatalf("got %d commands sent, expected SET and EVAL", n)
+	}
+}
+
+func TestCmdKeys(t *testing.T) {
+	movable := &CommandInfo{Flags: []string{"write", "movablekeys"}, FirstKeyPos: 1, LastKeyPos: 1, StepCount: 1}
+	for _, test := range []struct {
+		cmd   Cmder
+		info  *CommandInfo
+		keys  []string
+		known bool
+	}{
+		{NewStringCmd(ctx, "get", "a"), &CommandInfo{FirstKeyPos: 1, LastKeyPos: 1, StepCount: 1}, []string{"a"}, true},
+		{NewStatusCmd(ctx, "ping"), &CommandInfo{}, nil, true},
+		{NewStringCmd(ctx, "get", "a"), nil, nil, false},
+		{NewCmd(ctx, "eval", "return 1", 0), nil, nil, true},
+		{NewIntCmd(ctx, "zunionstore", "dst", 2, "a", "b", "weights", 1, 2), movable, []string{"dst", "a", "b"}, true},
+		{NewIntCmd(ctx, "zintercard", 2, "a", "b"), movable, []string{"a", "b"}, true},
+		{NewIntCmd(ctx, "zunionstore", "dst", 3, "a", "b"), movable, []string{"dst"}, false},
+		{NewXStreamSliceCmd(ctx, "xread", "count", 1, "streams", "a", "b", "0", "0"), movable, []string{"a", "b"}, true},
+		{NewXStreamSliceCmd(ctx, "xreadgroup", "group", "g", "c", "streams", "a", ">"), movable, []string{"a"}, true},
+		{NewXStreamSliceCmd(ctx, "xread", "streams", "a"), movable, nil, false},
+		{NewIntCmd(ctx, "sort", "a", "store", "b"), movable, []string{"a", "b"}, true},
+		{NewStringSliceCmd(ctx, "sort", "a", "by", "w_*"), movable, []string{"a"}, false},
+		{NewIntCmd(ctx, "georadius", "a", 0, 0, 1, "km", "storedist", "b"), movable, []string{"a", "b"}, true},
+		{NewStatusCmd(ctx, "migrate", "host", 6379, "", 0, 1000, "keys", "a", "b"),
+			&CommandInfo{Flags: []string{"movablekeys"}, FirstKeyPos: 3, LastKeyPos: 3, StepCount: 1}, []string{""}, false},
+	} {
+		keys, known := cmdKeys(test.info, test.cmd)
+		if !reflect.DeepEqual(keys, test.keys) || known != test.known {
+			t.Fatalf("%v: got %q, %v, wanted %q, %v", test.cmd.Args(), keys, known, test.keys, test.known)
+		}
+	}
+
+	// The unknown keys are not within any keyspace.
+	info := CmdInfo{Keys: []string{"tenant:a"}, KeysUnknown: true}
+	if info.HasKeyPrefix("tenant:") {
+		t.Fatal("got the unknown keys within the prefix")
+	}
+}
@@ -0,0 +1,179 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// autoPipeliner coalesces commands that are processed concurrently by
+// different goroutines into pipelines. A batch is flushed when it has
+// AutoPipelineMaxBatch commands or when AutoPipelineWindow has
+// elapsed since the first command was queued, whichever comes first.
+type autoPipeliner struct {
+	window      time.Duration
+	maxBatch    int
+	readTimeout time.Duration
+	process     func(ctx context.Context, cmds []Cmder) error
+	fallback    func(ctx context.Context, cmd Cmder) error
+
+	mu    sync.Mutex
+	batch *autoPipelineBatch
+}
+
+type autoPipelineBatch struct {
+	ctx  context.Context // the context of the first command, for its values
+	cmds []Cmder
+	// The earliest and latest deadlines of the commands, zero without deadlines.
+	minDeadline time.Time
+	maxDeadline time.Time
+	timer       *time.Timer
+	done        chan struct{}
+}
+
+// accepts reports whether a command with the deadline can join the batch.
+// The deadlines of the commands of a batch are at most the window apart,
+// so the batch bounded by the latest deadline does not make a command
+// fail before its own deadline or wait much longer than it.
+func (b *autoPipelineBatch) accepts(deadline time.Time, window time.Duration) bool {
+	if deadline.IsZero() || b.maxDeadline.IsZero() {
+		return deadline.IsZero() && b.maxDeadline.IsZero()
+	}
+	return !deadline.Before(b.maxDeadline.Add(-window)) && !deadline.After(b.minDeadline.Add(window))
+}
+
+func newAutoPipeliner(
+	opt *Options,
+	process func(ctx context.Context, cmds []Cmder) error,
+	fallback func(ctx context.Context, cmd Cmder) error,
+) *autoPipeliner {
+	return &autoPipeliner{
+		window:      opt.AutoPipelineWindow,
+		maxBatch:    opt.AutoPipelineMaxBatch,
+		readTimeout: opt.ReadTimeout,
+		process:     process,
+		fallback:    fallback,
+	}
+}
+
+// processCmd queues the command and waits until the batch it belongs to is executed.
+// Blocking commands and commands with a timeout override are not batched,
+// because they would delay the whole batch or be run with the batch timeouts,
+// and neither are the commands whose deadline is not compatible with the batch.
+// If ctx is done before the batch is executed, the command is removed from
+// the batch and ctx.Err() is returned.
+func (p *autoPipeliner) processCmd(ctx context.Context, cmd Cmder) error {
+	if cmd.readTimeout() != nil || hasTimeoutOverride(ctx) {
+		return p.fallback(ctx, cmd)
+	}
+	deadline, _ := ctx.Deadline()
+
+	p.mu.Lock()
+	b := p.batch
+	if b == nil {
+		b = &autoPipelineBatch{
+			cmds: make([]Cmder, 0, p.maxBatch),
+			done: make(chan struct{}),
+		}
+		p.batch = b
+		b.timer = time.AfterFunc(p.window, func() {
+			p.flush(b)
+		})
+	}
+	switch {
+	case len(b.cmds) == 0:
+		// The batch is new or its commands were removed.
+		b.ctx = ctx
+		b.minDeadline, b.maxDeadline = deadline, deadline
+	case !b.accepts(deadline, p.window):
+		p.mu.Unlock()
+		return p.fallback(ctx, cmd)
+	case deadline.Before(b.minDeadline):
+		b.minDeadline = deadline
+	case deadline.After(b.maxDeadline):
+		b.maxDeadline = deadline
+	}
+	b.cmds = append(b.cmds, cmd)
+	full := len(b.cmds) >= p.maxBatch
+	p.mu.Unlock()
+
+	if full {
+		p.flush(b)
+	}
+
+	select {
+	case <-b.done:
+		return cmd.Err()
+	case <-ctx.Done():
+	}
+
+	if p.remove(b, cmd) {
+		err := ctx.Err()
+		cmd.SetErr(err)
+		return err
+	}
+	// The batch is being executed, which is bounded by its deadline.
+	<-b.done
+	return cmd.Err()
+}
+
+// remove removes the command from the batch and reports whether it did,
+// which it can't once the batch is flushed.
+func (p *autoPipeliner) remove(b *autoPipelineBatch, cmd Cmder) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.batch != b {
+		return false
+	}
+	for i, c := range b.cmds {
+		if c == cmd {
+			b.cmds = append(b.cmds[:i], b.cmds[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// flush executes the batch unless it has already been flushed.
+func (p *autoPipeliner) flush(b *autoPipelineBatch) {
+	p.mu.Lock()
+	if p.batch != b {
+		p.mu.Unlock()
+		return
+	}
+	p.batch = nil
+	p.mu.Unlock()
+
+	b.timer.Stop()
+	defer close(b.done)
+
+	if len(b.cmds) == 0 {
+		return
+	}
+
+	// The batch is shared by commands with different contexts, so none of
+	// them can cancel the others. It carries the values of the context of
+	// the first command, e.g. for the hooks, and is bounded by the latest
+	// deadline of the commands, or by ReadTimeout if they have no deadline.
+	var ctx context.Context = valuesContext{b.ctx}
+	deadline := b.maxDeadline
+	if deadline.IsZero() && p.readTimeout > 0 {
+		deadline = time.Now().Add(p.readTimeout)
+	}
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	attributeErr(b.cmds, p.process(ctx, b.cmds))
+}
+
+// valuesContext carries the values of a context without its deadline and cancellation.
+type valuesContext struct {
+	context.Context
+}
+
+func (valuesContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (valuesContext) Done() <-chan struct{}       { return nil }
+func (valuesContext) Err() error                  { return nil }
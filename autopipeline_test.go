@@ -0,0 +1,212 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingConnStub struct {
+	ConnStub
+	writes *int32
+}
+
+func (c *countingConnStub) Write(b []byte) (int, error) {
+	atomic.AddInt32(c.writes, 1)
+	return len(b), nil
+}
+
+func TestAutoPipeline(t *testing.T) {
+	var writes int32
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &countingConnStub{
+				ConnStub: ConnStub{
+					init: initHello,
+					resp: []byte("+PONG\r\n"),
+				},
+				writes: &writes,
+			}, nil
+		},
+		DisableIndentity:     true,
+		AutoPipelineWindow:   time.Second,
+		AutoPipelineMaxBatch: 10,
+	})
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.Ping(ctx).Err(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// HELLO and a single pipeline.
+	if n := atomic.LoadInt32(&writes); n != 2 {
+		t.Fatalf("got %d writes, expected 2", n)
+	}
+}
+
+func TestAutoPipelineWindow(t *testing.T) {
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &ConnStub{init: initHello, resp: []byte("+PONG\r\n")}, nil
+		},
+		DisableIndentity:   true,
+		AutoPipelineWindow: time.Millisecond,
+	})
+	defer client.Close()
+
+	// A single command is sent when the window elapses.
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAutoPipelineContext(t *testing.T) {
+	var processed int32
+	p := &autoPipeliner{
+		window:   time.Hour,
+		maxBatch: 2,
+		process: func(ctx context.Context, cmds []Cmder) error {
+			atomic.AddInt32(&processed, int32(len(cmds)))
+			return nil
+		},
+	}
+
+	// A cancelled command does not wait for the window and is not executed.
+	ctx1, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := p.processCmd(ctx1, NewStatusCmd(ctx, "ping")); err != context.DeadlineExceeded {
+		t.Fatalf("got %v, expected context.DeadlineExceeded", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := p.processCmd(ctx, NewStatusCmd(ctx, "ping")); err != nil {
+			t.Error(err)
+		}
+	}()
+	if err := p.processCmd(ctx, NewStatusCmd(ctx, "ping")); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&processed); n != 2 {
+		t.Fatalf("got %d commands processed, expected 2", n)
+	}
+}
+
+func TestAutoPipelineDeadline(t *testing.T) {
+	deadlines := make(chan time.Time, 2)
+	p := &autoPipeliner{
+		window:      time.Millisecond,
+		maxBatch:    10,
+		readTimeout: time.Minute,
+		process: func(ctx context.Context, cmds []Cmder) error {
+			deadline, _ := ctx.Deadline()
+			deadlines <- deadline
+			return nil
+		},
+	}
+
+	// The batch is bounded by ReadTimeout without a deadline.
+	if err := p.processCmd(ctx, NewStatusCmd(ctx, "ping")); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(<-deadlines); d <= 0 || d > time.Minute {
+		t.Fatalf("got deadline in %s, expected ReadTimeout", d)
+	}
+
+	// The batch is bounded by the deadline of the command.
+	want := time.Now().Add(time.Second)
+	ctx1, cancel := context.WithDeadline(ctx, want)
+	defer cancel()
+	if err := p.processCmd(ctx1, NewStatusCmd(ctx, "ping")); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-deadlines; !got.Equal(want) {
+		t.Fatalf("got deadline %s, expected %s", got, want)
+	}
+}
+
+func TestAutoPipelineCompatibleDeadlines(t *testing.T) {
+	type ctxKey struct{}
+	var fallbacks int32
+	batches := make(chan context.Context, 1)
+	p := &autoPipeliner{
+		window:   50 * time.Millisecond,
+		maxBatch: 10,
+		process: func(ctx context.Context, cmds []Cmder) error {
+			if len(cmds) != 2 {
+				t.Errorf("got %d commands, expected 2", len(cmds))
+			}
+			batches <- ctx
+			return nil
+		},
+		fallback: func(ctx context.Context, cmd Cmder) error {
+			atomic.AddInt32(&fallbacks, 1)
+			return nil
+		},
+	}
+
+	first := time.Now().Add(10 * time.Second)
+	ctx1, cancel := context.WithDeadline(context.WithValue(ctx, ctxKey{}, "first"), first)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := p.processCmd(ctx1, NewStatusCmd(ctx, "ping")); err != nil {
+			t.Error(err)
+		}
+	}()
+	for {
+		p.mu.Lock()
+		queued := p.batch != nil
+		p.mu.Unlock()
+		if queued {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The commands with a shorter deadline or without a deadline are not batched.
+	ctx2, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	for _, ctx := range []context.Context{ctx2, ctx} {
+		if err := p.processCmd(ctx, NewStatusCmd(ctx, "ping")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&fallbacks); n != 2 {
+		t.Fatalf("got %d commands not batched, expected 2", n)
+	}
+
+	// The batch is bounded by the latest deadline.
+	latest := first.Add(10 * time.Millisecond)
+	ctx3, cancel := context.WithDeadline(ctx, latest)
+	defer cancel()
+	if err := p.processCmd(ctx3, NewStatusCmd(ctx, "ping")); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	batchCtx := <-batches
+	if deadline, _ := batchCtx.Deadline(); !deadline.Equal(latest) {
+		t.Fatalf("got deadline %s, expected %s", deadline, latest)
+	}
+	if v := batchCtx.Value(ctxKey{}); v != "first" {
+		t.Fatalf("got value %v, expected the value of the first command", v)
+	}
+}
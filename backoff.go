@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal"
+)
+
+// ServerErrorBackoff configures the delays before retrying commands that failed,
+// because the server is temporarily unable to serve them. Such commands were not
+// executed by the server, so they are safe to retry. A value of -1 disables
+// retrying the commands that failed with the error.
+type ServerErrorBackoff struct {
+	// Backoff after a LOADING error: the server is loading the dataset in memory.
+	// Default is 1 second.
+	Loading time.Duration
+	// Backoff after a BUSY error: the server is running a slow script or function.
+	// Default is 500 milliseconds.
+	Busy time.Duration
+	// Backoff after a CLUSTERDOWN error: the cluster is down or resharding.
+	// Default is 500 milliseconds.
+	ClusterDown time.Duration
+}
+
+func (b *ServerErrorBackoff) init() {
+	if b.Loading == 0 {
+		b.Loading = time.Second
+	}
+	if b.Busy == 0 {
+		b.Busy = 500 * time.Millisecond
+	}
+	if b.ClusterDown == 0 {
+		b.ClusterDown = 500 * time.Millisecond
+	}
+}
+
+// backoff returns the backoff for the err and reports
+// whether err is one of the errors configured by b.
+func (b *ServerErrorBackoff) backoff(err error) (time.Duration, bool) {
+	if err == nil || !isRedisError(err) {
+		return 0, false
+	}
+	s := err.Error()
+	switch {
+	case strings.HasPrefix(s, "LOADING "):
+		return b.Loading, true
+	case strings.HasPrefix(s, "BUSY "):
+		return b.Busy, true
+	case strings.HasPrefix(s, "CLUSTERDOWN "):
+		return b.ClusterDown, true
+	}
+	return 0, false
+}
+
+// shouldRetry is like shouldRetry, but also applies the server error backoff settings.
+func (b *ServerErrorBackoff) shouldRetry(err error, retryTimeout bool) bool {
+	if d, ok := b.backoff(err); ok {
+		return d >= 0
+	}
+	return shouldRetry(err, retryTimeout)
+}
+
+// retryBackoff returns the backoff before the retry attempt of a command
+// that failed with the err.
+func (b *ServerErrorBackoff) retryBackoff(
+	attempt int, err error, minBackoff, maxBackoff time.Duration,
+) time.Duration {
+	if d, ok := b.backoff(err); ok && d > 0 {
+		return d
+	}
+	return internal.RetryBackoff(attempt, minBackoff, maxBackoff)
+}
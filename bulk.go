@@ -0,0 +1,143 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/redis/go-redis/v9/internal/pool"
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// ReaderArg is a command argument that is streamed from an io.Reader
+// of the known size, e.g.
+//
+//	rdb.HSet(ctx, "key", "field", &redis.ReaderArg{R: f, Size: size})
+//
+// See SetFromReader.
+type ReaderArg = proto.ReaderArg
+
+var errBulkReaderClosed = errors.New("redis: bulk reader is closed")
+
+// GetReader is like Get, but instead of buffering the whole value in memory
+// it returns an io.ReadCloser that streams the value directly from the socket.
+// It returns Nil if the key does not exist.
+//
+// The connection is owned by the returned reader until Close is called:
+// Close returns the connection to the pool if the value was read completely
+// and closes the connection otherwise. The read timeout applies to every
+// Read call rather than to the whole value.
+//
+// Hooks observe the GET command up to the moment the value starts streaming.
+func (c *Client) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	cmd := NewStringCmd(ctx, "get", key)
+
+	var br *bulkReader
+	err := c.withProcessHook(ctx, cmd, func(ctx context.Context, _ Cmder) error {
+		var err error
+		br, err = c.baseClient.getReader(ctx, cmd)
+		return err
+	})
+	cmd.SetErr(err)
+	if err != nil {
+		return nil, err
+	}
+	return br, nil
+}
+
+func (c *baseClient) getReader(ctx context.Context, cmd Cmder) (*bulkReader, error) {
+	cn, err := c.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cn.WithWriter(c.context(ctx), writeTimeout(ctx, c.opt.WriteTimeout), func(wr *proto.Writer) error {
+		return writeCmd(wr, cmd)
+	}); err != nil {
+		c.releaseConn(ctx, cn, err)
+		return nil, err
+	}
+
+	var n int
+	if err := cn.WithReader(c.context(ctx), readTimeout(ctx, c.opt.ReadTimeout), func(rd *proto.Reader) error {
+		n, err = rd.ReadStringLen()
+		if err == nil && n == 0 {
+			var crlf [2]byte
+			_, err = io.ReadFull(rd, crlf[:])
+		}
+		return err
+	}); err != nil {
+		c.releaseConn(ctx, cn, err)
+		return nil, err
+	}
+
+	return &bulkReader{
+		ctx:       ctx,
+		c:         c,
+		cn:        cn,
+		remaining: n,
+	}, nil
+}
+
+type bulkReader struct {
+	ctx context.Context
+	c   *baseClient
+	cn  *pool.Conn
+
+	remaining int
+	err       error
+	closed    bool
+}
+
+var _ io.ReadCloser = (*bulkReader)(nil)
+
+func (r *bulkReader) Read(b []byte) (int, error) {
+	if r.closed {
+		return 0, errBulkReaderClosed
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.remaining == 0 {
+		return 0, io.EOF
+	}
+	if len(b) > r.remaining {
+		b = b[:r.remaining]
+	}
+
+	var n int
+	err := r.cn.WithReader(r.c.context(r.ctx), readTimeout(r.ctx, r.c.opt.ReadTimeout), func(rd *proto.Reader) error {
+		var err error
+		n, err = rd.Read(b)
+		r.remaining -= n
+		if err == nil && r.remaining == 0 {
+			// Consume the trailing \r\n.
+			var crlf [2]byte
+			_, err = io.ReadFull(rd, crlf[:])
+		}
+		return err
+	})
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// Close releases the connection. Closing the reader before the value
+// is read completely closes the connection.
+func (r *bulkReader) Close() error {
+	if r.closed {
+		return errBulkReaderClosed
+	}
+	r.closed = true
+
+	err := r.err
+	if err == nil && r.remaining > 0 {
+		err = errBulkReaderClosed
+	}
+	r.c.releaseConn(r.ctx, r.cn, err)
+	return nil
+}
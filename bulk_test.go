@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"io"
+	"testing"
+)
+
+func TestGetReader(t *testing.T) {
+	client := NewClientStub([]byte("$11\r\nhello world\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	rd, err := client.GetReader(ctx, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := make([]byte, 4)
+	var got []byte
+	for {
+		n, err := rd.Read(b)
+		got = append(got, b[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if string(got) != "hello world" {
+		t.Fatalf("got %q, wanted %q", got, "hello world")
+	}
+	if err := rd.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := client.PoolStats().IdleConns; n != 1 {
+		t.Fatalf("got %d idle conns, wanted 1", n)
+	}
+}
+
+func TestGetReaderNil(t *testing.T) {
+	client := NewClientStub([]byte("$-1\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	_, err := client.GetReader(ctx, "key")
+	if err != Nil {
+		t.Fatalf("got %v, wanted redis.Nil", err)
+	}
+}
+
+func TestGetReaderPartialClose(t *testing.T) {
+	client := NewClientStub([]byte("$11\r\nhello world\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	rd, err := client.GetReader(ctx, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rd.Read(make([]byte, 5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := rd.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The connection with unread data must not be reused.
+	if n := client.PoolStats().TotalConns; n != 0 {
+		t.Fatalf("got %d conns, wanted 0", n)
+	}
+}
@@ -0,0 +1,175 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9/internal/pool"
+)
+
+// ServerCapabilities describes the server the client is connected to.
+// It is detected when a connection is initialized using the HELLO reply or,
+// on servers that don't support HELLO, INFO server. INFO is only sent again
+// when the client connects to a different address, e.g. after a failover.
+type ServerCapabilities struct {
+	// Server name as reported by HELLO, e.g. "redis". Empty if HELLO is not supported.
+	Server string
+	// Server version, e.g. "7.2.4". Empty if the version could not be detected.
+	Version string
+	// Negotiated protocol version, 2 or 3.
+	Protocol int
+	// Server mode as reported by HELLO, e.g. "standalone", "cluster" or "sentinel".
+	Mode string
+	// Names of the loaded modules as reported by HELLO.
+	Modules []string
+	// Whether the server supports the HELLO command.
+	Hello bool
+
+	major, minor, patch int
+}
+
+// AtLeast reports whether the server version is at least major.minor.
+// It reports true when the version is unknown so that callers keep
+// the default behaviour for servers that don't report their version.
+func (c *ServerCapabilities) AtLeast(major, minor int) bool {
+	if c.Version == "" {
+		return true
+	}
+	if c.major != major {
+		return c.major > major
+	}
+	return c.minor >= minor
+}
+
+// HasModule reports whether the module with the given name is loaded.
+func (c *ServerCapabilities) HasModule(name string) bool {
+	for _, m := range c.Modules {
+		if strings.EqualFold(m, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsClientSetInfo reports whether the server supports CLIENT SETINFO (Redis 7.2).
+func (c *ServerCapabilities) SupportsClientSetInfo() bool {
+	return c.AtLeast(7, 2)
+}
+
+func (c *ServerCapabilities) setVersion(version string) {
+	c.Version = version
+	parts := strings.SplitN(version, ".", 3)
+	nums := []*int{&c.major, &c.minor, &c.patch}
+	for i, part := range parts {
+		// Strip suffixes like "-rc1".
+		if j := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' }); j >= 0 {
+			part = part[:j]
+		}
+		*nums[i], _ = strconv.Atoi(part)
+	}
+}
+
+func newHelloCapabilities(hello map[string]interface{}) *ServerCapabilities {
+	caps := &ServerCapabilities{Hello: true}
+	caps.Server, _ = hello["server"].(string)
+	caps.Mode, _ = hello["mode"].(string)
+	if version, ok := hello["version"].(string); ok {
+		caps.setVersion(version)
+	}
+	if proto, ok := hello["proto"].(int64); ok {
+		caps.Protocol = int(proto)
+	}
+	if modules, ok := hello["modules"].([]interface{}); ok {
+		for _, m := range modules {
+			if name := moduleName(m); name != "" {
+				caps.Modules = append(caps.Modules, name)
+			}
+		}
+	}
+	return caps
+}
+
+func moduleName(m interface{}) string {
+	switch m := m.(type) {
+	case map[interface{}]interface{}:
+		name, _ := m["name"].(string)
+		return name
+	case []interface{}:
+		// RESP2 flat array: name, value, ...
+		for i := 0; i+1 < len(m); i += 2 {
+			if k, _ := m[i].(string); k == "name" {
+				name, _ := m[i+1].(string)
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func newInfoCapabilities(info string) *ServerCapabilities {
+	caps := &ServerCapabilities{Protocol: 2}
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "redis_version:") {
+			caps.setVersion(line[len("redis_version:"):])
+		} else if strings.HasPrefix(line, "redis_mode:") {
+			caps.Mode = line[len("redis_mode:"):]
+		}
+	}
+	return caps
+}
+
+// capabilitiesHolder stores the capabilities detected by the last initialized
+// connection, shared by all clones of the options.
+type capabilitiesHolder struct {
+	v atomic.Value
+}
+
+type addrCapabilities struct {
+	addr string
+	caps *ServerCapabilities
+}
+
+func (h *capabilitiesHolder) load() *ServerCapabilities {
+	v, _ := h.v.Load().(*addrCapabilities)
+	if v == nil {
+		return nil
+	}
+	return v.caps
+}
+
+// loadAddr returns the capabilities if they were detected on the address.
+func (h *capabilitiesHolder) loadAddr(addr string) *ServerCapabilities {
+	v, _ := h.v.Load().(*addrCapabilities)
+	if v == nil || v.addr != addr {
+		return nil
+	}
+	return v.caps
+}
+
+func (h *capabilitiesHolder) store(addr string, caps *ServerCapabilities) {
+	h.v.Store(&addrCapabilities{addr: addr, caps: caps})
+}
+
+func connAddr(cn *pool.Conn) string {
+	if addr := cn.RemoteAddr(); addr != nil {
+		return addr.String()
+	}
+	return ""
+}
+
+// ServerCapabilities returns the capabilities of the server detected by the last
+// initialized connection; if there is no connection yet, a connection is established.
+func (c *Client) ServerCapabilities(ctx context.Context) (*ServerCapabilities, error) {
+	if caps := c.opt.capabilities.load(); caps != nil {
+		return caps, nil
+	}
+	if err := c.withConn(ctx, func(context.Context, *pool.Conn) error {
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return c.opt.capabilities.load(), nil
+}
@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+)
+
+func newCapabilitiesClient(init string) *Client {
+	return NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &ConnStub{
+				init: []byte(init),
+				resp: []byte("+PONG\r\n"),
+			}, nil
+		},
+		DisableIndentity: true,
+	})
+}
+
+func TestServerCapabilitiesHello(t *testing.T) {
+	client := newCapabilitiesClient("%5\r\n" +
+		"+server\r\n+redis\r\n" +
+		"+version\r\n+7.0.11\r\n" +
+		"+proto\r\n:3\r\n" +
+		"+mode\r\n+standalone\r\n" +
+		"+modules\r\n*1\r\n%1\r\n+name\r\n+search\r\n")
+	defer client.Close()
+
+	caps, err := client.ServerCapabilities(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !caps.Hello || caps.Version != "7.0.11" || caps.Protocol != 3 || caps.Mode != "standalone" {
+		t.Fatalf("got %+v", caps)
+	}
+	if !caps.HasModule("search") {
+		t.Fatalf("got modules %v, expected search", caps.Modules)
+	}
+	if !caps.AtLeast(7, 0) || caps.AtLeast(7, 2) || caps.SupportsClientSetInfo() {
+		t.Fatalf("got wrong feature detection for %s", caps.Version)
+	}
+}
+
+func TestServerCapabilitiesInfo(t *testing.T) {
+	info := "# Server\r\nredis_version:5.0.7\r\nredis_mode:standalone\r\n"
+	client := newCapabilitiesClient("-ERR unknown command 'HELLO'\r\n" +
+		"$" + strconv.Itoa(len(info)) + "\r\n" + info + "\r\n")
+	defer client.Close()
+
+	caps, err := client.ServerCapabilities(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps.Hello || caps.Version != "5.0.7" || caps.Protocol != 2 || caps.Mode != "standalone" {
+		t.Fatalf("got %+v", caps)
+	}
+	if caps.AtLeast(6, 2) || caps.SupportsClientSetInfo() {
+		t.Fatalf("got wrong feature detection for %s", caps.Version)
+	}
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type addrConnStub struct {
+	ConnStub
+	addr net.Addr
+}
+
+func (c *addrConnStub) RemoteAddr() net.Addr { return c.addr }
+
+func TestServerCapabilitiesFailover(t *testing.T) {
+	info := func(version string) string {
+		info := "# Server\r\nredis_version:" + version + "\r\n"
+		return "-ERR unknown command 'HELLO'\r\n" + "$" + strconv.Itoa(len(info)) + "\r\n" + info + "\r\n"
+	}
+	hello := "%1\r\n+version\r\n+7.2.4\r\n"
+	conns := []*addrConnStub{
+		{ConnStub{init: []byte(info("5.0.7"))}, &net.TCPAddr{Port: 6379}},
+		// A conn to the same server doesn't send INFO again.
+		{ConnStub{init: []byte("-ERR unknown command 'HELLO'\r\n")}, &net.TCPAddr{Port: 6379}},
+		// The master failed over to another server.
+		{ConnStub{init: []byte(info("6.2.0"))}, &net.TCPAddr{Port: 6380}},
+		// The server was upgraded.
+		{ConnStub{init: []byte(hello)}, &net.TCPAddr{Port: 6380}},
+	}
+	var dials int
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			cn := conns[dials]
+			cn.resp = []byte("+PONG\r\n")
+			dials++
+			return cn, nil
+		},
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	for _, version := range []string{"5.0.7", "5.0.7", "6.2.0", "7.2.4"} {
+		client.RecycleConns()
+		if err := client.Ping(ctx).Err(); err != nil {
+			t.Fatal(err)
+		}
+		caps, err := client.ServerCapabilities(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if caps.Version != version {
+			t.Fatalf("got version %q, expected %q", caps.Version, version)
+		}
+	}
+	if dials != len(conns) {
+		t.Fatalf("got %d dials, expected %d", dials, len(conns))
+	}
+}
@@ -0,0 +1,94 @@
+package redis
+
+import "context"
+
+// chunkedProcess returns a process function that executes MGET, DEL, UNLINK
+// and EXISTS commands with more than maxKeys keys as a pipeline of chunks,
+// see Options.MaxKeysPerCommand.
+func chunkedProcess(
+	maxKeys int,
+	process func(context.Context, Cmder) error,
+	pipeline func(context.Context, []Cmder) error,
+) func(context.Context, Cmder) error {
+	return func(ctx context.Context, cmd Cmder) error {
+		chunks := splitKeysCmd(ctx, cmd, maxKeys)
+		if chunks == nil {
+			return process(ctx, cmd)
+		}
+		if err := pipeline(ctx, chunks); err != nil {
+			return err
+		}
+		return mergeKeysCmd(cmd, chunks)
+	}
+}
+
+// splitKeysCmd returns the commands executing cmd in chunks of maxKeys keys
+// or nil if cmd is not split.
+func splitKeysCmd(ctx context.Context, cmd Cmder, maxKeys int) []Cmder {
+	args := cmd.Args()
+	if len(args)-1 <= maxKeys {
+		return nil
+	}
+
+	var newCmd func(args []interface{}) Cmder
+	switch cmd.(type) {
+	case *SliceCmd:
+		if cmd.Name() != "mget" {
+			return nil
+		}
+		newCmd = func(args []interface{}) Cmder {
+			return NewSliceCmd(ctx, args...)
+		}
+	case *IntCmd:
+		switch cmd.Name() {
+		case "del", "unlink", "exists":
+		default:
+			return nil
+		}
+		newCmd = func(args []interface{}) Cmder {
+			return NewIntCmd(ctx, args...)
+		}
+	default:
+		return nil
+	}
+
+	keys := args[1:]
+	chunks := make([]Cmder, 0, (len(keys)+maxKeys-1)/maxKeys)
+	for start := 0; start < len(keys); start += maxKeys {
+		end := start + maxKeys
+		if end > len(keys) {
+			end = len(keys)
+		}
+		chunkArgs := make([]interface{}, 0, 1+end-start)
+		chunkArgs = append(chunkArgs, args[0])
+		chunkArgs = append(chunkArgs, keys[start:end]...)
+		chunks = append(chunks, newCmd(chunkArgs))
+	}
+	return chunks
+}
+
+// mergeKeysCmd sets the value of cmd to the merged values of the chunks,
+// or returns the first error of the chunks.
+func mergeKeysCmd(cmd Cmder, chunks []Cmder) error {
+	for _, chunk := range chunks {
+		if err := chunk.Err(); err != nil {
+			return err
+		}
+	}
+
+	switch cmd := cmd.(type) {
+	case *SliceCmd:
+		vals := make([]interface{}, 0, len(cmd.args)-1)
+		for _, chunk := range chunks {
+			vals = append(vals, chunk.(*SliceCmd).Val()...)
+		}
+		cmd.SetVal(vals)
+	case *IntCmd:
+		var n int64
+		for _, chunk := range chunks {
+			n += chunk.(*IntCmd).Val()
+		}
+		cmd.SetVal(n)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMaxKeysPerCommand(t *testing.T) {
+	keys := make(map[string]string)
+	var names []string
+	for i := 0; i < 10; i++ {
+		name := "key" + strconv.Itoa(i)
+		names = append(names, name)
+		if i%2 == 0 {
+			keys[name] = strconv.Itoa(i)
+		}
+	}
+	client := newKVServer(keys).clientWithOptions(&Options{MaxKeysPerCommand: 3})
+	defer client.Close()
+	rec := NewRecorder()
+	client.AddHook(rec)
+
+	vals, err := client.MGet(ctx, names...).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{"0", nil, "2", nil, "4", nil, "6", nil, "8", nil}
+	if !reflect.DeepEqual(vals, want) {
+		t.Fatalf("got %v, wanted %v", vals, want)
+	}
+	if n, err := client.Exists(ctx, names...).Result(); err != nil || n != 5 {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	if n, err := client.Del(ctx, names...).Result(); err != nil || n != 5 {
+		t.Fatalf("got %d, %v", n, err)
+	}
+
+	// The commands with few keys are not split.
+	if n, err := client.Exists(ctx, names[:3]...).Result(); err != nil || n != 0 {
+		t.Fatalf("got %d, %v", n, err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := rec.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for cmd, n := range map[string]int{"mget": 4, "exists": 5, "del": 4} {
+		if got := strings.Count(buf.String(), cmd); got != n {
+			t.Fatalf("got %d %s commands, wanted %d", got, cmd, n)
+		}
+	}
+}
+
+func TestMaxKeysPerCommandError(t *testing.T) {
+	client := newKVServer(map[string]string{}).clientWithOptions(&Options{MaxKeysPerCommand: 1})
+	defer client.Close()
+
+	if err := client.Unlink(ctx, "a", "b").Err(); err == nil || !strings.Contains(err.Error(), "unexpected unlink") {
+		t.Fatalf("got %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package redis
+
+import "time"
+
+// Clock is the source of the time of a client, see Options.Clock.
+// It allows the tests to advance the time virtually instead of sleeping,
+// e.g. to expire the idle connections.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends
+	// the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock advanced by the tests. After advances the clock
+// instead of waiting.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func TestClockConnMaxIdleTime(t *testing.T) {
+	clock := newFakeClock()
+	client := newKVServer(map[string]string{"a": "1"}).clientWithOptions(&Options{
+		ConnMaxIdleTime: time.Minute,
+		Clock:           clock,
+	})
+	defer client.Close()
+
+	get := func() {
+		if err := client.Get(ctx, "a").Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get()
+	clock.Advance(30 * time.Second)
+	get()
+	if stats := client.PoolStats(); stats.Misses != 1 || stats.Hits != 1 {
+		t.Fatalf("got %+v", stats)
+	}
+
+	clock.Advance(2 * time.Minute)
+	get()
+	if stats := client.PoolStats(); stats.Misses != 2 || stats.StaleConns != 1 {
+		t.Fatalf("got %+v", stats)
+	}
+}
+
+func TestClockRetryBackoff(t *testing.T) {
+	clock := newFakeClock()
+	var dials int
+	client := NewClient(&Options{
+		Addr: "stub:6379",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dials++
+			return nil, &net.OpError{Op: "dial", Net: network, Err: errors.New("connection refused")}
+		},
+		PoolSize:        10,
+		MaxRetries:      3,
+		MinRetryBackoff: time.Hour,
+		MaxRetryBackoff: 2 * time.Hour,
+		Clock:           clock,
+	})
+	defer client.Close()
+
+	if err := client.Ping(ctx).Err(); err == nil {
+		t.Fatal("ping succeeded")
+	}
+	if dials != 4 {
+		t.Fatalf("got %d dials, wanted 4", dials)
+	}
+	if len(clock.waits) != 3 {
+		t.Fatalf("got backoffs %v", clock.waits)
+	}
+	for _, d := range clock.waits {
+		if d < time.Hour || d > 2*time.Hour {
+			t.Fatalf("got backoffs %v", clock.waits)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+type nodeLimiter struct {
+	err     error
+	results int
+}
+
+func (l *nodeLimiter) Allow() error       { return l.err }
+func (l *nodeLimiter) ReportResult(error) { l.results++ }
+
+func TestClusterNewLimiter(t *testing.T) {
+	errOpen := errors.New("circuit breaker is open")
+	limiters := make(map[string]*nodeLimiter)
+	opt := &ClusterOptions{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &ConnStub{init: initHello, resp: []byte("+PONG\r\n")}, nil
+		},
+		DisableIndentity: true,
+		NewLimiter: func(addr string) Limiter {
+			l := new(nodeLimiter)
+			if addr == "10.0.0.1:6379" {
+				l.err = errOpen
+			}
+			limiters[addr] = l
+			return l
+		},
+	}
+	opt.init()
+
+	failing := newClusterNode(opt, "10.0.0.1:6379")
+	defer failing.Close()
+	node := newClusterNode(opt, "10.0.0.2:6379")
+	defer node.Close()
+
+	if err := failing.Client.Ping(ctx).Err(); err != errOpen {
+		t.Fatalf("got %v, expected %v", err, errOpen)
+	}
+	// The open circuit breaker of the other node does not reject the command.
+	if err := node.Client.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n := limiters["10.0.0.2:6379"].results; n == 0 {
+		t.Fatal("got no results reported to the limiter of the node")
+	}
+	if n := limiters["10.0.0.1:6379"].results; n != 0 {
+		t.Fatalf("got %d results, expected 0", n)
+	}
+}
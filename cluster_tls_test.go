@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestClusterTLSConfigFor(t *testing.T) {
+	shared := &tls.Config{ServerName: "cluster.example.com"}
+	opt := &ClusterOptions{
+		TLSConfig: shared,
+		TLSConfigFor: func(addr string) *tls.Config {
+			if addr == "10.0.0.2:6379" {
+				return nil
+			}
+			return &tls.Config{ServerName: "node-" + addr}
+		},
+	}
+	opt.init()
+
+	node := newClusterNode(opt, "10.0.0.1:6379")
+	defer node.Close()
+	if got := node.Client.Options().TLSConfig; got == nil || got.ServerName != "node-10.0.0.1:6379" {
+		t.Fatalf("got %+v, expected the config of the node", got)
+	}
+
+	plain := newClusterNode(opt, "10.0.0.2:6379")
+	defer plain.Close()
+	if got := plain.Client.Options().TLSConfig; got != nil {
+		t.Fatalf("got %+v, expected no TLS", got)
+	}
+
+	opt.TLSConfigFor = nil
+	node = newClusterNode(opt, "10.0.0.3:6379")
+	defer node.Close()
+	if got := node.Client.Options().TLSConfig; got != shared {
+		t.Fatalf("got %+v, expected the shared config", got)
+	}
+}
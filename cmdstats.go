@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"time"
+)
+
+// CmdStats holds the timings of an executed command, see Cmder.Stats.
+// The durations are summed over all attempts. Commands sent in a pipeline
+// share the timings of the whole pipeline. They are only recorded with
+// Options.CmdStatsEnabled or Options.OnSlowCommand.
+type CmdStats struct {
+	// Attempts is the number of times the command was sent, including retries.
+	Attempts int
+	// PoolWait is the time spent waiting for a connection from the pool,
+	// including dialing new connections.
+	PoolWait time.Duration
+	// Write is the time spent writing the command to the connection.
+	Write time.Duration
+	// Read is the time spent waiting for and reading the reply. It includes
+	// the server processing time, which can't be measured separately.
+	Read time.Duration
+}
+
+// Network returns the time spent writing the command and reading the reply.
+func (s CmdStats) Network() time.Duration {
+	return s.Write + s.Read
+}
+
+func (s CmdStats) sub(other CmdStats) CmdStats {
+	return CmdStats{
+		Attempts: s.Attempts - other.Attempts,
+		PoolWait: s.PoolWait - other.PoolWait,
+		Write:    s.Write - other.Write,
+		Read:     s.Read - other.Read,
+	}
+}
+
+func (s *CmdStats) add(other CmdStats) {
+	s.Attempts += other.Attempts
+	s.PoolWait += other.PoolWait
+	s.Write += other.Write
+	s.Read += other.Read
+}
+
+// cmdStatsEnabled reports whether the timings of the commands are recorded.
+func (opt *Options) cmdStatsEnabled() bool {
+	return opt.CmdStatsEnabled || opt.OnSlowCommand != nil
+}
+
+func (opt *ClusterOptions) cmdStatsEnabled() bool {
+	return opt.CmdStatsEnabled || opt.OnSlowCommand != nil
+}
+
+// attemptTimer measures an attempt to send commands.
+// A disabled timer only records whether a connection was obtained.
+type attemptTimer struct {
+	enabled                   bool
+	hasConn                   bool
+	start, connected, written time.Time
+}
+
+func newAttemptTimer(enabled bool) attemptTimer {
+	if !enabled {
+		return attemptTimer{}
+	}
+	return attemptTimer{enabled: true, start: time.Now()}
+}
+
+func (t *attemptTimer) connect() {
+	t.hasConn = true
+	if t.enabled {
+		t.connected = time.Now()
+	}
+}
+
+func (t *attemptTimer) write() {
+	if t.enabled {
+		t.written = time.Now()
+	}
+}
+
+// addStats adds the timings of the attempt ending now to the commands.
+func (t *attemptTimer) addStats(cmds ...Cmder) {
+	if !t.enabled {
+		return
+	}
+	stats := t.stats()
+	for _, cmd := range cmds {
+		cmd.addStats(stats)
+	}
+}
+
+// stats returns the timings of the attempt ending now. The attempt may have
+// failed before getting a connection or before writing the commands.
+func (t *attemptTimer) stats() CmdStats {
+	done := time.Now()
+	if t.connected.IsZero() {
+		t.connected = done
+	}
+	if t.written.IsZero() {
+		t.written = done
+	}
+	return CmdStats{
+		Attempts: 1,
+		PoolWait: t.connected.Sub(t.start),
+		Write:    t.written.Sub(t.connected),
+		Read:     done.Sub(t.written),
+	}
+}
@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+type slowReadConn struct {
+	net.Conn
+	delay time.Duration
+}
+
+func (c *slowReadConn) Read(b []byte) (int, error) {
+	time.Sleep(c.delay)
+	return c.Conn.Read(b)
+}
+
+func TestCmdStats(t *testing.T) {
+	client := NewClient(&Options{
+		Addr: "stub:6379",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			time.Sleep(30 * time.Millisecond)
+			conn := &ConnStub{init: initHello, resp: []byte("+OK\r\n")}
+			return &slowReadConn{Conn: conn, delay: 20 * time.Millisecond}, nil
+		},
+		DisableIndentity: true,
+		CmdStatsEnabled:  true,
+	})
+	defer client.Close()
+
+	cmd := client.Ping(ctx)
+	if err := cmd.Err(); err != nil {
+		t.Fatal(err)
+	}
+	stats := cmd.Stats()
+	if stats.Attempts != 1 {
+		t.Fatalf("got %d attempts, expected 1", stats.Attempts)
+	}
+	if stats.PoolWait < 30*time.Millisecond {
+		t.Fatalf("got %s, expected the pool wait to include dialing", stats.PoolWait)
+	}
+	if stats.Read < 20*time.Millisecond {
+		t.Fatalf("got %s, expected the slow read", stats.Read)
+	}
+
+	// The pooled connection is reused.
+	cmds, err := client.Pipelined(ctx, func(pipe Pipeliner) error {
+		pipe.Ping(ctx)
+		pipe.Ping(ctx)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats = cmds[0].Stats()
+	if stats != cmds[1].Stats() {
+		t.Fatalf("got %+v and %+v, expected the stats of the pipeline", stats, cmds[1].Stats())
+	}
+	if stats.Attempts != 1 || stats.PoolWait >= 20*time.Millisecond {
+		t.Fatalf("got %+v, expected a pooled connection", stats)
+	}
+	if stats.Read < 20*time.Millisecond {
+		t.Fatalf("got %s, expected the slow read", stats.Read)
+	}
+}
+
+func TestCmdStatsDisabled(t *testing.T) {
+	client := NewClientStub([]byte("+PONG\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	cmd := client.Ping(ctx)
+	if err := cmd.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if stats := cmd.Stats(); stats != (CmdStats{}) {
+		t.Fatalf("got %+v, expected no stats", stats)
+	}
+}
@@ -0,0 +1,193 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Codec encodes the values stored by SetObject and decodes the values
+// loaded by GetObject, e.g. using JSON, msgpack or protobuf.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec is a Codec using encoding/json. It is the default Options.Codec.
+type JSONCodec struct{}
+
+var _ Codec = JSONCodec{}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func codecOrDefault(codec Codec) Codec {
+	if codec == nil {
+		return JSONCodec{}
+	}
+	return codec
+}
+
+func setObject(
+	ctx context.Context, c StringCmdable, codec Codec, key string, value interface{}, expiration time.Duration,
+) *StatusCmd {
+	b, err := codecOrDefault(codec).Marshal(value)
+	if err != nil {
+		cmd := NewStatusCmd(ctx, "set", key)
+		cmd.SetErr(err)
+		return cmd
+	}
+	return c.Set(ctx, key, b, expiration)
+}
+
+func getObject(ctx context.Context, c StringCmdable, codec Codec, key string, dst interface{}) error {
+	b, err := c.Get(ctx, key).Bytes()
+	if err != nil {
+		return err
+	}
+	return codecOrDefault(codec).Unmarshal(b, dst)
+}
+
+func mgetScan(ctx context.Context, c StringCmdable, codec Codec, keys []string, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("redis: MGetScan(non-slice pointer %T)", dst)
+	}
+
+	vals, err := c.MGet(ctx, keys...).Result()
+	if err != nil {
+		return err
+	}
+
+	codec = codecOrDefault(codec)
+	slice := v.Elem()
+	slice.Set(reflect.MakeSlice(slice.Type(), len(vals), len(vals)))
+	for i, val := range vals {
+		s, ok := val.(string)
+		if !ok {
+			// The key does not exist.
+			continue
+		}
+		if err := unmarshalValue(codec, s, slice.Index(i)); err != nil {
+			return fmt.Errorf("redis: can't decode %q: %w", keys[i], err)
+		}
+	}
+	return nil
+}
+
+func hgetAllScan(ctx context.Context, c HashCmdable, codec Codec, key string, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Map || v.Elem().Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("redis: HGetAllScan(non-map[string] pointer %T)", dst)
+	}
+
+	vals, err := c.HGetAll(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	codec = codecOrDefault(codec)
+	m := v.Elem()
+	m.Set(reflect.MakeMapWithSize(m.Type(), len(vals)))
+	for field, val := range vals {
+		elem := reflect.New(m.Type().Elem()).Elem()
+		if err := unmarshalValue(codec, val, elem); err != nil {
+			return fmt.Errorf("redis: can't decode field %q: %w", field, err)
+		}
+		m.SetMapIndex(reflect.ValueOf(field).Convert(m.Type().Key()), elem)
+	}
+	return nil
+}
+
+// unmarshalValue decodes s into the addressable elem, allocating the pointers.
+func unmarshalValue(codec Codec, s string, elem reflect.Value) error {
+	if elem.Kind() == reflect.Ptr {
+		elem.Set(reflect.New(elem.Type().Elem()))
+		return codec.Unmarshal([]byte(s), elem.Interface())
+	}
+	return codec.Unmarshal([]byte(s), elem.Addr().Interface())
+}
+
+// SetObject encodes the value using Options.Codec and stores it like Set.
+// The encoding error, if any, is returned by the command, which is not sent.
+func (c *Client) SetObject(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	return setObject(ctx, c, c.opt.Codec, key, value, expiration)
+}
+
+// GetObject gets the value of the key and decodes it into dst using Options.Codec.
+// It returns Nil if the key does not exist.
+func (c *Client) GetObject(ctx context.Context, key string, dst interface{}) error {
+	return getObject(ctx, c, c.opt.Codec, key, dst)
+}
+
+// SetObject encodes the value using ClusterOptions.Codec and stores it like Set.
+// The encoding error, if any, is returned by the command, which is not sent.
+func (c *ClusterClient) SetObject(
+	ctx context.Context, key string, value interface{}, expiration time.Duration,
+) *StatusCmd {
+	return setObject(ctx, c, c.opt.Codec, key, value, expiration)
+}
+
+// GetObject gets the value of the key and decodes it into dst using ClusterOptions.Codec.
+// It returns Nil if the key does not exist.
+func (c *ClusterClient) GetObject(ctx context.Context, key string, dst interface{}) error {
+	return getObject(ctx, c, c.opt.Codec, key, dst)
+}
+
+// SetObject encodes the value using RingOptions.Codec and stores it like Set.
+// The encoding error, if any, is returned by the command, which is not sent.
+func (c *Ring) SetObject(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	return setObject(ctx, c, c.opt.Codec, key, value, expiration)
+}
+
+// GetObject gets the value of the key and decodes it into dst using RingOptions.Codec.
+// It returns Nil if the key does not exist.
+func (c *Ring) GetObject(ctx context.Context, key string, dst interface{}) error {
+	return getObject(ctx, c, c.opt.Codec, key, dst)
+}
+
+// MGetScan gets the values of the keys and decodes them using Options.Codec into dst,
+// which must be a pointer to a slice. The values of the missing keys are left zero,
+// e.g. nil for a slice of pointers.
+func (c *Client) MGetScan(ctx context.Context, keys []string, dst interface{}) error {
+	return mgetScan(ctx, c, c.opt.Codec, keys, dst)
+}
+
+// HGetAllScan gets all the fields of the hash and decodes their values using
+// Options.Codec into dst, which must be a pointer to a map with string keys.
+func (c *Client) HGetAllScan(ctx context.Context, key string, dst interface{}) error {
+	return hgetAllScan(ctx, c, c.opt.Codec, key, dst)
+}
+
+// MGetScan gets the values of the keys and decodes them using ClusterOptions.Codec into dst,
+// which must be a pointer to a slice. The values of the missing keys are left zero,
+// e.g. nil for a slice of pointers.
+func (c *ClusterClient) MGetScan(ctx context.Context, keys []string, dst interface{}) error {
+	return mgetScan(ctx, c, c.opt.Codec, keys, dst)
+}
+
+// HGetAllScan gets all the fields of the hash and decodes their values using
+// ClusterOptions.Codec into dst, which must be a pointer to a map with string keys.
+func (c *ClusterClient) HGetAllScan(ctx context.Context, key string, dst interface{}) error {
+	return hgetAllScan(ctx, c, c.opt.Codec, key, dst)
+}
+
+// MGetScan gets the values of the keys and decodes them using RingOptions.Codec into dst,
+// which must be a pointer to a slice. The values of the missing keys are left zero,
+// e.g. nil for a slice of pointers.
+func (c *Ring) MGetScan(ctx context.Context, keys []string, dst interface{}) error {
+	return mgetScan(ctx, c, c.opt.Codec, keys, dst)
+}
+
+// HGetAllScan gets all the fields of the hash and decodes their values using
+// RingOptions.Codec into dst, which must be a pointer to a map with string keys.
+func (c *Ring) HGetAllScan(ctx context.Context, key string, dst interface{}) error {
+	return hgetAllScan(ctx, c, c.opt.Codec, key, dst)
+}
@@ -0,0 +1,135 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+type upperCodec struct{}
+
+func (upperCodec) Marshal(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, errors.New("not a string")
+	}
+	return bytes.ToUpper([]byte(s)), nil
+}
+
+func (upperCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*string) = string(bytes.ToLower(data))
+	return nil
+}
+
+func TestObject(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	client := NewClientStub([]byte("+OK\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	cmd := client.SetObject(ctx, "user:1", user{Name: "alice"}, 0)
+	if err := cmd.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := cmd.Args()[2].([]byte); string(b) != `{"name":"alice"}` {
+		t.Fatalf("got %q", cmd.Args()[2])
+	}
+
+	client = NewClientStub([]byte("$16\r\n{\"name\":\"alice\"}\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	var u user
+	if err := client.GetObject(ctx, "user:1", &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "alice" {
+		t.Fatalf("got %+v", u)
+	}
+}
+
+func TestObjectCodec(t *testing.T) {
+	stub := &ClientStub{resp: []byte("+OK\r\n")}
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return stub.stubConn(initHello), nil
+		},
+		Codec:            upperCodec{},
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	cmd := client.SetObject(ctx, "key", "hello", 0)
+	if b, _ := cmd.Args()[2].([]byte); string(b) != "HELLO" {
+		t.Fatalf("got %q", cmd.Args()[2])
+	}
+
+	if err := client.SetObject(ctx, "key", 42, 0).Err(); err == nil || err.Error() != "not a string" {
+		t.Fatalf("got %v, wanted the encoding error", err)
+	}
+}
+
+func TestMGetScan(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	resp := []byte("*3\r\n" +
+		"$16\r\n{\"name\":\"alice\"}\r\n" +
+		"_\r\n" +
+		"$14\r\n{\"name\":\"bob\"}\r\n")
+
+	client := NewClientStub(resp).Cmdable.(*Client)
+	defer client.Close()
+
+	var users []user
+	if err := client.MGetScan(ctx, []string{"a", "b", "c"}, &users); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(users, []user{{"alice"}, {}, {"bob"}}) {
+		t.Fatalf("got %+v", users)
+	}
+
+	var ptrs []*user
+	if err := client.MGetScan(ctx, []string{"a", "b", "c"}, &ptrs); err != nil {
+		t.Fatal(err)
+	}
+	if len(ptrs) != 3 || ptrs[0].Name != "alice" || ptrs[1] != nil || ptrs[2].Name != "bob" {
+		t.Fatalf("got %+v", ptrs)
+	}
+
+	if err := client.MGetScan(ctx, []string{"a"}, users); err == nil {
+		t.Fatal("expected an error for a non-pointer")
+	}
+}
+
+func TestHGetAllScan(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	client := NewClientStub([]byte("%2\r\n" +
+		"$1\r\na\r\n$16\r\n{\"name\":\"alice\"}\r\n" +
+		"$1\r\nb\r\n$14\r\n{\"name\":\"bob\"}\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	var users map[string]user
+	if err := client.HGetAllScan(ctx, "users", &users); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(users, map[string]user{"a": {"alice"}, "b": {"bob"}}) {
+		t.Fatalf("got %+v", users)
+	}
+
+	var ptrs map[string]*user
+	if err := client.HGetAllScan(ctx, "users", &ptrs); err != nil {
+		t.Fatal(err)
+	}
+	if len(ptrs) != 2 || ptrs["a"].Name != "alice" {
+		t.Fatalf("got %+v", ptrs)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"math/big"
 	"net"
 	"regexp"
 	"strconv"
@@ -43,6 +44,11 @@ type Cmder interface {
 	readRawReply(rd *proto.Reader) error
 	SetErr(error)
 	Err() error
+
+	// Stats returns the timings of the executed command,
+	// see Options.CmdStatsEnabled.
+	Stats() CmdStats
+	addStats(CmdStats)
 }
 
 func setCmdsErr(cmds []Cmder, e error) {
@@ -128,10 +134,19 @@ type baseCmd struct {
 	keyPos       int8
 	rawVal       interface{}
 	_readTimeout *time.Duration
+	stats        *CmdStats
 }
 
 var _ Cmder = (*Cmd)(nil)
 
+// reset prepares the command to be processed again with new arguments.
+func (cmd *baseCmd) reset(ctx context.Context, args []interface{}) {
+	*cmd = baseCmd{
+		ctx:  ctx,
+		args: args,
+	}
+}
+
 func (cmd *baseCmd) Name() string {
 	if len(cmd.args) == 0 {
 		return ""
@@ -191,6 +206,20 @@ func (cmd *baseCmd) Err() error {
 	return cmd.err
 }
 
+func (cmd *baseCmd) Stats() CmdStats {
+	if cmd.stats == nil {
+		return CmdStats{}
+	}
+	return *cmd.stats
+}
+
+func (cmd *baseCmd) addStats(stats CmdStats) {
+	if cmd.stats == nil {
+		cmd.stats = new(CmdStats)
+	}
+	cmd.stats.add(stats)
+}
+
 func (cmd *baseCmd) readTimeout() *time.Duration {
 	return cmd._readTimeout
 }
@@ -209,7 +238,8 @@ func (cmd *baseCmd) readRawReply(rd *proto.Reader) (err error) {
 type Cmd struct {
 	baseCmd
 
-	val interface{}
+	val    interface{}
+	parser ReplyParser
 }
 
 func NewCmd(ctx context.Context, args ...interface{}) *Cmd {
@@ -225,10 +255,25 @@ func (cmd *Cmd) String() string {
 	return cmdString(cmd, cmd.val)
 }
 
+// Reset prepares the command to be reused with new arguments, e.g. to avoid
+// allocating a new command for every iteration of a hot loop. The command
+// must not be used concurrently, e.g. it must not be queued in a pipeline
+// that hasn't been executed yet.
+func (cmd *Cmd) Reset(ctx context.Context, args ...interface{}) {
+	cmd.baseCmd.reset(ctx, args)
+	cmd.val = nil
+}
+
 func (cmd *Cmd) SetVal(val interface{}) {
 	cmd.val = val
 }
 
+// SetReplyParser sets the parser that is used to read the reply of this
+// command instead of the default one or the one registered with RegisterReplyParser.
+func (cmd *Cmd) SetReplyParser(parser ReplyParser) {
+	cmd.parser = parser
+}
+
 func (cmd *Cmd) Val() interface{} {
 	return cmd.val
 }
@@ -248,6 +293,9 @@ func toString(val interface{}) (string, error) {
 	switch val := val.(type) {
 	case string:
 		return val, nil
+	case float64:
+		// RESP3 double.
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
 	default:
 		err := fmt.Errorf("redis: unexpected type=%T for String", val)
 		return "", err
@@ -282,6 +330,12 @@ func toInt64(val interface{}) (int64, error) {
 		return val, nil
 	case string:
 		return strconv.ParseInt(val, 10, 64)
+	case *big.Int:
+		// RESP3 big number.
+		if !val.IsInt64() {
+			return 0, fmt.Errorf("redis: bigInt(%s) value out of range for Int64", val)
+		}
+		return val.Int64(), nil
 	default:
 		err := fmt.Errorf("redis: unexpected type=%T for Int64", val)
 		return 0, err
@@ -301,6 +355,12 @@ func toUint64(val interface{}) (uint64, error) {
 		return uint64(val), nil
 	case string:
 		return strconv.ParseUint(val, 10, 64)
+	case *big.Int:
+		// RESP3 big number.
+		if !val.IsUint64() {
+			return 0, fmt.Errorf("redis: bigInt(%s) value out of range for Uint64", val)
+		}
+		return val.Uint64(), nil
 	default:
 		err := fmt.Errorf("redis: unexpected type=%T for Uint64", val)
 		return 0, err
@@ -318,6 +378,9 @@ func toFloat32(val interface{}) (float32, error) {
 	switch val := val.(type) {
 	case int64:
 		return float32(val), nil
+	case float64:
+		// RESP3 double.
+		return float32(val), nil
 	case string:
 		f, err := strconv.ParseFloat(val, 32)
 		if err != nil {
@@ -341,14 +404,60 @@ func toFloat64(val interface{}) (float64, error) {
 	switch val := val.(type) {
 	case int64:
 		return float64(val), nil
+	case float64:
+		// RESP3 double.
+		return val, nil
 	case string:
 		return strconv.ParseFloat(val, 64)
+	case *big.Int:
+		// RESP3 big number.
+		f, _ := new(big.Float).SetInt(val).Float64()
+		return f, nil
 	default:
 		err := fmt.Errorf("redis: unexpected type=%T for Float64", val)
 		return 0, err
 	}
 }
 
+// BigInt returns the reply as a big.Int, e.g. a RESP3 big number
+// that does not fit into int64.
+func (cmd *Cmd) BigInt() (*big.Int, error) {
+	if cmd.err != nil {
+		return nil, cmd.err
+	}
+	switch val := cmd.val.(type) {
+	case *big.Int:
+		return val, nil
+	case int64:
+		return big.NewInt(val), nil
+	case string:
+		return parseBigInt(val)
+	default:
+		err := fmt.Errorf("redis: unexpected type=%T for BigInt", val)
+		return nil, err
+	}
+}
+
+func parseBigInt(s string) (*big.Int, error) {
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("redis: can't parse %q as BigInt", s)
+	}
+	return i, nil
+}
+
+// bigFloatPrec is the precision of the BigFloat values, which is more than enough
+// for the long double values formatted by Redis, e.g. by INCRBYFLOAT.
+const bigFloatPrec = 128
+
+func parseBigFloat(s string) (*big.Float, error) {
+	f, _, err := big.ParseFloat(s, 10, bigFloatPrec, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("redis: can't parse %q as BigFloat: %w", s, err)
+	}
+	return f, nil
+}
+
 func (cmd *Cmd) Bool() (bool, error) {
 	if cmd.err != nil {
 		return false, cmd.err
@@ -370,6 +479,36 @@ func toBool(val interface{}) (bool, error) {
 	}
 }
 
+// Time parses the reply as a time: a string reply is parsed using RFC3339Nano
+// and an integer reply is interpreted as a Unix timestamp in seconds.
+func (cmd *Cmd) Time() (time.Time, error) {
+	if cmd.err != nil {
+		return time.Time{}, cmd.err
+	}
+	switch val := cmd.val.(type) {
+	case int64:
+		return time.Unix(val, 0), nil
+	case string:
+		return time.Parse(time.RFC3339Nano, val)
+	default:
+		err := fmt.Errorf("redis: unexpected type=%T for Time", val)
+		return time.Time{}, err
+	}
+}
+
+// Duration interprets the integer reply as a number of units,
+// e.g. cmd.Duration(time.Millisecond) for a PTTL-like reply.
+func (cmd *Cmd) Duration(unit time.Duration) (time.Duration, error) {
+	if cmd.err != nil {
+		return 0, cmd.err
+	}
+	n, err := toInt64(cmd.val)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(n) * unit, nil
+}
+
 func (cmd *Cmd) Slice() ([]interface{}, error) {
 	if cmd.err != nil {
 		return nil, cmd.err
@@ -484,7 +623,58 @@ func (cmd *Cmd) BoolSlice() ([]bool, error) {
 	return bools, nil
 }
 
+// StringMap converts a RESP3 map reply or a RESP2 array of
+// field-value pairs, e.g. the reply to HGETALL, into a map.
+func (cmd *Cmd) StringMap() (map[string]string, error) {
+	if cmd.err != nil {
+		return nil, cmd.err
+	}
+	switch val := cmd.val.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]string, len(val))
+		for k, v := range val {
+			key, err := toString(k)
+			if err != nil {
+				return nil, err
+			}
+			value, err := toString(v)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		}
+		return m, nil
+	case []interface{}:
+		if len(val)%2 != 0 {
+			return nil, fmt.Errorf("redis: got %d elements for StringMap, wanted an even number", len(val))
+		}
+		m := make(map[string]string, len(val)/2)
+		for i := 0; i < len(val); i += 2 {
+			key, err := toString(val[i])
+			if err != nil {
+				return nil, err
+			}
+			value, err := toString(val[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected type=%T for StringMap", val)
+	}
+}
+
 func (cmd *Cmd) readReply(rd *proto.Reader) (err error) {
+	parser := cmd.parser
+	if parser == nil {
+		parser = replyParsers.get(cmd)
+	}
+	if parser != nil {
+		cmd.val, err = parser(&ReplyReader{rd: rd})
+		return err
+	}
 	cmd.val, err = rd.ReadReply()
 	return err
 }
@@ -568,6 +758,12 @@ func NewStatusCmd(ctx context.Context, args ...interface{}) *StatusCmd {
 	}
 }
 
+// Reset prepares the command to be reused with new arguments. See Cmd.Reset.
+func (cmd *StatusCmd) Reset(ctx context.Context, args ...interface{}) {
+	cmd.baseCmd.reset(ctx, args)
+	cmd.val = ""
+}
+
 func (cmd *StatusCmd) SetVal(val string) {
 	cmd.val = val
 }
@@ -580,7 +776,15 @@ func (cmd *StatusCmd) Result() (string, error) {
 	return cmd.val, cmd.err
 }
 
+// Bytes returns a copy of the reply that is safe to modify.
 func (cmd *StatusCmd) Bytes() ([]byte, error) {
+	return []byte(cmd.val), cmd.err
+}
+
+// BytesUnsafe is like Bytes, but returns the bytes of the reply without copying them.
+// The returned slice shares memory with the value returned by Val
+// and must not be modified.
+func (cmd *StatusCmd) BytesUnsafe() ([]byte, error) {
 	return util.StringToBytes(cmd.val), cmd.err
 }
 
@@ -612,6 +816,12 @@ func NewIntCmd(ctx context.Context, args ...interface{}) *IntCmd {
 	}
 }
 
+// Reset prepares the command to be reused with new arguments. See Cmd.Reset.
+func (cmd *IntCmd) Reset(ctx context.Context, args ...interface{}) {
+	cmd.baseCmd.reset(ctx, args)
+	cmd.val = 0
+}
+
 func (cmd *IntCmd) SetVal(val int64) {
 	cmd.val = val
 }
@@ -628,6 +838,20 @@ func (cmd *IntCmd) Uint64() (uint64, error) {
 	return uint64(cmd.val), cmd.err
 }
 
+// Duration interprets the reply as a number of units.
+func (cmd *IntCmd) Duration(unit time.Duration) (time.Duration, error) {
+	return time.Duration(cmd.val) * unit, cmd.err
+}
+
+// Time interprets the reply as a number of units since the Unix epoch,
+// e.g. time.Second for a Unix timestamp.
+func (cmd *IntCmd) Time(unit time.Duration) (time.Time, error) {
+	if cmd.err != nil {
+		return time.Time{}, cmd.err
+	}
+	return time.Unix(0, cmd.val*int64(unit)), nil
+}
+
 func (cmd *IntCmd) String() string {
 	return cmdString(cmd, cmd.val)
 }
@@ -719,6 +943,16 @@ func (cmd *DurationCmd) Result() (time.Duration, error) {
 	return cmd.val, cmd.err
 }
 
+// Time interprets the reply as the time since the Unix epoch, e.g. the reply
+// of ExpireTime. The zero time is returned if the key does not exist
+// or has no associated expire.
+func (cmd *DurationCmd) Time() (time.Time, error) {
+	if cmd.err != nil || cmd.val < 0 {
+		return time.Time{}, cmd.err
+	}
+	return time.Unix(0, int64(cmd.val)), nil
+}
+
 func (cmd *DurationCmd) String() string {
 	return cmdString(cmd, cmd.val)
 }
@@ -809,6 +1043,12 @@ func NewBoolCmd(ctx context.Context, args ...interface{}) *BoolCmd {
 	}
 }
 
+// Reset prepares the command to be reused with new arguments. See Cmd.Reset.
+func (cmd *BoolCmd) Reset(ctx context.Context, args ...interface{}) {
+	cmd.baseCmd.reset(ctx, args)
+	cmd.val = false
+}
+
 func (cmd *BoolCmd) SetVal(val bool) {
 	cmd.val = val
 }
@@ -856,6 +1096,12 @@ func NewStringCmd(ctx context.Context, args ...interface{}) *StringCmd {
 	}
 }
 
+// Reset prepares the command to be reused with new arguments. See Cmd.Reset.
+func (cmd *StringCmd) Reset(ctx context.Context, args ...interface{}) {
+	cmd.baseCmd.reset(ctx, args)
+	cmd.val = ""
+}
+
 func (cmd *StringCmd) SetVal(val string) {
 	cmd.val = val
 }
@@ -868,7 +1114,26 @@ func (cmd *StringCmd) Result() (string, error) {
 	return cmd.val, cmd.err
 }
 
+// ResultOK is like Result, but reports a missing key or value with ok=false
+// instead of the Nil error, so it is distinguished from an empty value.
+func (cmd *StringCmd) ResultOK() (val string, ok bool, err error) {
+	if IsNil(cmd.err) {
+		return "", false, nil
+	}
+	return cmd.val, cmd.err == nil, cmd.err
+}
+
+// Bytes returns a copy of the reply that is safe to modify.
 func (cmd *StringCmd) Bytes() ([]byte, error) {
+	return []byte(cmd.val), cmd.err
+}
+
+// BytesUnsafe is like Bytes, but avoids the allocation by returning the bytes
+// of the reply without copying them. It is meant for callers that immediately
+// deserialize the value. The returned slice shares memory with the value returned
+// by Val and, with Options.MaxReplyBufferSize < 0 or Options.MaxInternedReplies,
+// with the read buffer or the replies of other commands, so it must never be modified.
+func (cmd *StringCmd) BytesUnsafe() ([]byte, error) {
 	return util.StringToBytes(cmd.val), cmd.err
 }
 
@@ -918,6 +1183,23 @@ func (cmd *StringCmd) Float64() (float64, error) {
 	return strconv.ParseFloat(cmd.Val(), 64)
 }
 
+// BigInt parses the reply as an arbitrary-precision integer.
+func (cmd *StringCmd) BigInt() (*big.Int, error) {
+	if cmd.err != nil {
+		return nil, cmd.err
+	}
+	return parseBigInt(cmd.Val())
+}
+
+// BigFloat parses the reply as a 128-bit precision float, e.g. the reply
+// of IncrByDecimal, without the rounding of Float64.
+func (cmd *StringCmd) BigFloat() (*big.Float, error) {
+	if cmd.err != nil {
+		return nil, cmd.err
+	}
+	return parseBigFloat(cmd.Val())
+}
+
 func (cmd *StringCmd) Time() (time.Time, error) {
 	if cmd.err != nil {
 		return time.Time{}, cmd.err
@@ -925,6 +1207,18 @@ func (cmd *StringCmd) Time() (time.Time, error) {
 	return time.Parse(time.RFC3339Nano, cmd.Val())
 }
 
+// Duration parses the reply as an integer number of units.
+func (cmd *StringCmd) Duration(unit time.Duration) (time.Duration, error) {
+	if cmd.err != nil {
+		return 0, cmd.err
+	}
+	n, err := strconv.ParseInt(cmd.Val(), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(n) * unit, nil
+}
+
 func (cmd *StringCmd) Scan(val interface{}) error {
 	if cmd.err != nil {
 		return cmd.err
@@ -960,6 +1254,12 @@ func NewFloatCmd(ctx context.Context, args ...interface{}) *FloatCmd {
 	}
 }
 
+// Reset prepares the command to be reused with new arguments. See Cmd.Reset.
+func (cmd *FloatCmd) Reset(ctx context.Context, args ...interface{}) {
+	cmd.baseCmd.reset(ctx, args)
+	cmd.val = 0
+}
+
 func (cmd *FloatCmd) SetVal(val float64) {
 	cmd.val = val
 }
@@ -972,6 +1272,15 @@ func (cmd *FloatCmd) Result() (float64, error) {
 	return cmd.val, cmd.err
 }
 
+// ResultOK is like Result, but reports a missing value, e.g. the score
+// of a missing member, with ok=false instead of the Nil error.
+func (cmd *FloatCmd) ResultOK() (val float64, ok bool, err error) {
+	if IsNil(cmd.err) {
+		return 0, false, nil
+	}
+	return cmd.val, cmd.err == nil, cmd.err
+}
+
 func (cmd *FloatCmd) String() string {
 	return cmdString(cmd, cmd.val)
 }
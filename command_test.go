@@ -86,6 +86,21 @@ var _ = Describe("Cmd", func() {
 		Expect(tm2).To(BeTemporally("==", tm))
 	})
 
+	It("supports Bytes and BytesUnsafe", func() {
+		err := client.Set(ctx, "bytes_key", "hello", 0).Err()
+		Expect(err).NotTo(HaveOccurred())
+
+		cmd := client.Get(ctx, "bytes_key")
+		b, err := cmd.Bytes()
+		Expect(err).NotTo(HaveOccurred())
+		b[0] = 'j'
+		Expect(cmd.Val()).To(Equal("hello"))
+
+		b, err = cmd.BytesUnsafe()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b).To(Equal([]byte("hello")))
+	})
+
 	It("allows to set custom error", func() {
 		e := errors.New("custom error")
 		cmd := redis.Cmd{}
@@ -79,7 +79,7 @@ func appendArg(dst []interface{}, arg interface{}) []interface{} {
 			dst = append(dst, k, v)
 		}
 		return dst
-	case time.Time, time.Duration, encoding.BinaryMarshaler, net.IP:
+	case time.Time, time.Duration, encoding.BinaryMarshaler, net.IP, *ReaderArg:
 		return append(dst, arg)
 	default:
 		// scan struct field
@@ -157,6 +157,9 @@ func isEmptyValue(v reflect.Value) bool {
 	return false
 }
 
+// Cmdable is the set of the commands implemented by Client, ClusterClient,
+// Ring, Tx and Pipeline, so the code using it can work with any of them
+// or with a mock.
 type Cmdable interface {
 	Pipeline() Pipeliner
 	Pipelined(ctx context.Context, fn func(Pipeliner) error) ([]Cmder, error)
@@ -173,6 +176,8 @@ type Cmdable interface {
 	Ping(ctx context.Context) *StatusCmd
 	Quit(ctx context.Context) *StatusCmd
 	Unlink(ctx context.Context, keys ...string) *IntCmd
+	Wait(ctx context.Context, numSlaves int, timeout time.Duration) *IntCmd
+	WaitAOF(ctx context.Context, numLocal, numSlaves int, timeout time.Duration) *IntCmd
 
 	BgRewriteAOF(ctx context.Context) *StatusCmd
 	BgSave(ctx context.Context) *StatusCmd
@@ -195,6 +200,7 @@ type Cmdable interface {
 	FlushDB(ctx context.Context) *StatusCmd
 	FlushDBAsync(ctx context.Context) *StatusCmd
 	Info(ctx context.Context, section ...string) *StringCmd
+	InfoMap(ctx context.Context, sections ...string) *InfoCmd
 	LastSave(ctx context.Context) *IntCmd
 	Save(ctx context.Context) *StatusCmd
 	Shutdown(ctx context.Context) *StatusCmd
@@ -245,6 +251,7 @@ var (
 	_ Cmdable = (*Tx)(nil)
 	_ Cmdable = (*Ring)(nil)
 	_ Cmdable = (*ClusterClient)(nil)
+	_ Cmdable = (*Pipeline)(nil)
 )
 
 type cmdable func(ctx context.Context, cmd Cmder) error
@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// Compressor compresses the values encoded by a CompressionCodec,
+// e.g. using gzip, snappy or zstd.
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// GzipCompressor is a Compressor using compress/gzip.
+type GzipCompressor struct {
+	// Level is the gzip compression level. Default is gzip.DefaultCompression.
+	Level int
+}
+
+var _ Compressor = GzipCompressor{}
+
+func (c GzipCompressor) Compress(data []byte) ([]byte, error) {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// compressedPrefix marks the compressed values. Values encoded by the usual
+// codecs, e.g. JSON, do not start with a zero byte.
+var compressedPrefix = []byte("\x00rz\x01")
+
+// CompressionCodec is a Codec that compresses the values encoded by Codec
+// when they are at least Threshold bytes long, e.g. large JSON documents:
+//
+//	rdb := redis.NewClient(&redis.Options{
+//		Codec: &redis.CompressionCodec{
+//			Codec:      redis.JSONCodec{},
+//			Compressor: redis.GzipCompressor{},
+//			Threshold:  1024,
+//		},
+//	})
+//
+// The compressed values are prefixed with a magic header, so the values
+// stored without the compression are still decoded.
+type CompressionCodec struct {
+	// Codec encodes the values. Default is JSONCodec.
+	Codec Codec
+	// Compressor compresses the encoded values.
+	Compressor Compressor
+	// Threshold is the minimum size of the encoded values to compress.
+	// Default is 0, which compresses all values.
+	Threshold int
+}
+
+var _ Codec = (*CompressionCodec)(nil)
+
+func (c *CompressionCodec) Marshal(v interface{}) ([]byte, error) {
+	b, err := codecOrDefault(c.Codec).Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < c.Threshold {
+		return b, nil
+	}
+
+	compressed, err := c.Compressor.Compress(b)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(make([]byte, 0, len(compressedPrefix)+len(compressed)),
+		compressedPrefix...), compressed...), nil
+}
+
+func (c *CompressionCodec) Unmarshal(data []byte, v interface{}) error {
+	if bytes.HasPrefix(data, compressedPrefix) {
+		b, err := c.Compressor.Decompress(data[len(compressedPrefix):])
+		if err != nil {
+			return fmt.Errorf("redis: can't decompress value: %w", err)
+		}
+		data = b
+	}
+	return codecOrDefault(c.Codec).Unmarshal(data, v)
+}
@@ -0,0 +1,40 @@
+package redis
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompressionCodec(t *testing.T) {
+	codec := &CompressionCodec{
+		Compressor: GzipCompressor{},
+		Threshold:  100,
+	}
+
+	for _, value := range []string{"short", strings.Repeat("long", 100)} {
+		b, err := codec.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compressed := bytes.HasPrefix(b, compressedPrefix)
+		if compressed != (len(value) >= 100) {
+			t.Fatalf("got compressed=%t for %d bytes", compressed, len(value))
+		}
+		if compressed && len(b) >= len(value) {
+			t.Fatalf("got %d compressed bytes for %d bytes", len(b), len(value))
+		}
+
+		var got string
+		if err := codec.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != value {
+			t.Fatalf("got %q, wanted %q", got, value)
+		}
+	}
+
+	if err := codec.Unmarshal([]byte(string(compressedPrefix)+"junk"), new(string)); err == nil {
+		t.Fatal("expected a decompression error")
+	}
+}
@@ -0,0 +1,134 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal/pool"
+)
+
+// DialStats holds the durations of establishing connections, summed over
+// all the connections. DNS, Connect and TLS are only measured by the default
+// dialer, see NewDialer.
+type DialStats struct {
+	Conns   uint64        // number of established connections
+	Dial    time.Duration // time spent in the dialer
+	DNS     time.Duration // resolving the address
+	Connect time.Duration // establishing the network connection
+	TLS     time.Duration // TLS handshake
+	Init    time.Duration // AUTH, HELLO, SELECT and the other commands sent on connect, including OnConnect
+}
+
+func (s *DialStats) add(other DialStats) {
+	s.Conns += other.Conns
+	s.Dial += other.Dial
+	s.DNS += other.DNS
+	s.Connect += other.Connect
+	s.TLS += other.TLS
+	s.Init += other.Init
+}
+
+// dialStats sums the dial stats, shared by all clones of the options.
+type dialStats struct {
+	mu    sync.Mutex
+	stats DialStats
+}
+
+func (s *dialStats) add(stats DialStats) {
+	s.mu.Lock()
+	s.stats.add(stats)
+	s.mu.Unlock()
+}
+
+func (s *dialStats) get() *DialStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats
+	return &stats
+}
+
+// reportDial records the stats of the new connection initialized in init.
+func (c *baseClient) reportDial(ctx context.Context, cn *pool.Conn, init time.Duration) {
+	stats := DialStats{
+		Conns:   1,
+		Dial:    cn.DialTimings.Dial,
+		DNS:     cn.DialTimings.DNS,
+		Connect: cn.DialTimings.Connect,
+		TLS:     cn.DialTimings.TLS,
+		Init:    init,
+	}
+	c.opt.dialStats.add(stats)
+	if c.opt.OnDial != nil {
+		c.opt.OnDial(ctx, c.opt.Addr, stats)
+	}
+}
+
+// DialStats returns the durations of establishing the connections of the client.
+func (c *Client) DialStats() *DialStats {
+	return c.opt.dialStats.get()
+}
+
+// DialStats returns the durations of establishing the connections to all cluster nodes.
+func (c *ClusterClient) DialStats() *DialStats {
+	return c.opt.dialStats.get()
+}
+
+// DialStats returns the durations of establishing the connections to all ring shards.
+func (c *Ring) DialStats() *DialStats {
+	return c.opt.dialStats.get()
+}
+
+// dialWithTimings dials like NewDialer and records the durations of the phases.
+// Control is called after resolving the address, right before connecting.
+func dialWithTimings(
+	ctx context.Context, netDialer *net.Dialer, network, addr string, tlsConfig *tls.Config, timings *pool.DialTimings,
+) (net.Conn, error) {
+	if tlsConfig != nil && netDialer.Timeout > 0 {
+		// The timeout covers both dialing and the TLS handshake like in tls.DialWithDialer.
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, netDialer.Timeout)
+		defer cancel()
+	}
+
+	var connectStart int64 // atomic
+	netDialer.ControlContext = func(context.Context, string, string, syscall.RawConn) error {
+		atomic.CompareAndSwapInt64(&connectStart, 0, time.Now().UnixNano())
+		return nil
+	}
+
+	start := time.Now()
+	conn, err := netDialer.DialContext(ctx, network, addr)
+	connected := time.Now()
+	if started := atomic.LoadInt64(&connectStart); started != 0 {
+		timings.DNS = time.Unix(0, started).Sub(start)
+		timings.Connect = connected.Sub(time.Unix(0, started))
+	} else {
+		timings.Connect = connected.Sub(start)
+	}
+	if err != nil || tlsConfig == nil {
+		return conn, err
+	}
+
+	if tlsConfig.ServerName == "" {
+		// Verify the host name like tls.DialWithDialer.
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = host
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	err = tlsConn.HandshakeContext(ctx)
+	timings.TLS = time.Since(connected)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func dialStatsServer(ln net.Listener) {
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				// Reply to HELLO and PING.
+				buf := make([]byte, 1024)
+				if _, err := conn.Read(buf); err != nil {
+					return
+				}
+				_, _ = conn.Write(initHello)
+				if _, err := conn.Read(buf); err != nil {
+					return
+				}
+				_, _ = conn.Write([]byte("+PONG\r\n"))
+				_, _ = conn.Read(buf)
+			}()
+		}
+	}()
+}
+
+// testCertificate returns the self-signed certificate of the httptest package.
+func testCertificate() tls.Certificate {
+	srv := httptest.NewUnstartedServer(nil)
+	srv.StartTLS()
+	defer srv.Close()
+	return srv.TLS.Certificates[0]
+}
+
+func TestDialStats(t *testing.T) {
+	cert := testCertificate()
+
+	for _, withTLS := range []bool{false, true} {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		opt := &Options{
+			Addr:             ln.Addr().String(),
+			DisableIndentity: true,
+		}
+		if withTLS {
+			ln = tls.NewListener(ln, &tls.Config{Certificates: []tls.Certificate{cert}})
+			opt.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+		dialStatsServer(ln)
+
+		var dialed []DialStats
+		opt.OnDial = func(ctx context.Context, addr string, stats DialStats) {
+			if addr != opt.Addr {
+				t.Errorf("got %q, expected %q", addr, opt.Addr)
+			}
+			dialed = append(dialed, stats)
+		}
+		client := NewClient(opt)
+
+		if err := client.Ping(ctx).Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(dialed) != 1 {
+			t.Fatalf("got %d dials, expected 1", len(dialed))
+		}
+		stats := dialed[0]
+		if *client.DialStats() != stats {
+			t.Fatalf("got %+v, expected %+v", *client.DialStats(), stats)
+		}
+		if stats.Conns != 1 || stats.Connect <= 0 || stats.Init <= 0 {
+			t.Fatalf("got %+v, expected the connect and init durations", stats)
+		}
+		if stats.Dial < stats.DNS+stats.Connect+stats.TLS {
+			t.Fatalf("got %+v, expected the dial to include the phases", stats)
+		}
+		if withTLS != (stats.TLS > 0) {
+			t.Fatalf("got %+v, expected the TLS handshake duration: %v", stats, withTLS)
+		}
+
+		_ = client.Close()
+		_ = ln.Close()
+	}
+}
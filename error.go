@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"net"
+	"strconv"
 	"strings"
 
 	"github.com/redis/go-redis/v9/internal"
@@ -26,6 +27,81 @@ func HasErrorPrefix(err error, prefix string) bool {
 	return strings.HasPrefix(msg, prefix)
 }
 
+// IsNil reports whether err is Nil, i.e. the key or the value does not exist.
+// Unlike comparing err == Nil, it also matches the wrapped errors.
+func IsNil(err error) bool {
+	return errors.Is(err, Nil)
+}
+
+// IsLoading reports whether err is a LOADING error returned by a Redis server
+// that is still loading the dataset in memory.
+func IsLoading(err error) bool {
+	return HasErrorPrefix(err, "LOADING ")
+}
+
+// IsReadOnly reports whether err is a READONLY error returned when a write
+// command is sent to a read-only replica.
+func IsReadOnly(err error) bool {
+	return HasErrorPrefix(err, "READONLY ")
+}
+
+// IsClusterDown reports whether err is a CLUSTERDOWN error.
+func IsClusterDown(err error) bool {
+	return HasErrorPrefix(err, "CLUSTERDOWN ")
+}
+
+// IsTryAgain reports whether err is a TRYAGAIN error returned by a cluster
+// node during resharding.
+func IsTryAgain(err error) bool {
+	return HasErrorPrefix(err, "TRYAGAIN ")
+}
+
+// IsNoScript reports whether err is a NOSCRIPT error returned by EVALSHA
+// when the script is not loaded.
+func IsNoScript(err error) bool {
+	return HasErrorPrefix(err, "NOSCRIPT ")
+}
+
+// IsMaxClients reports whether err is returned by a Redis server
+// that reached the maximum number of clients.
+func IsMaxClients(err error) bool {
+	return HasErrorPrefix(err, "max number of clients reached")
+}
+
+// IsMoved reports whether err is a MOVED redirection and returns
+// the slot and the address of the node that serves it.
+func IsMoved(err error) (addr string, slot int, ok bool) {
+	return parseRedirect(err, "MOVED ")
+}
+
+// IsAsk reports whether err is an ASK redirection and returns
+// the slot and the address of the node to ask.
+func IsAsk(err error) (addr string, slot int, ok bool) {
+	return parseRedirect(err, "ASK ")
+}
+
+func parseRedirect(err error, prefix string) (addr string, slot int, ok bool) {
+	var rErr Error
+	if !errors.As(err, &rErr) {
+		return "", 0, false
+	}
+
+	// "MOVED 3999 127.0.0.1:6381"
+	s := rErr.Error()
+	if !strings.HasPrefix(s, prefix) {
+		return "", 0, false
+	}
+	slotStr, addr, found := strings.Cut(s[len(prefix):], " ")
+	if !found {
+		return "", 0, false
+	}
+	slot, convErr := strconv.Atoi(slotStr)
+	if convErr != nil {
+		return "", 0, false
+	}
+	return internal.GetAddr(addr), slot, true
+}
+
 type Error interface {
 	error
 
@@ -69,10 +145,22 @@ func shouldRetry(err error, retryTimeout bool) bool {
 	if strings.HasPrefix(s, "TRYAGAIN ") {
 		return true
 	}
-
 	return false
 }
 
+// retryAllowed reports whether the ShouldRetry hook allows to retry the commands.
+func retryAllowed(fn func(error, int, Cmder) bool, err error, attempt int, cmds ...Cmder) bool {
+	if fn == nil {
+		return true
+	}
+	for _, cmd := range cmds {
+		if !fn(err, attempt, cmd) {
+			return false
+		}
+	}
+	return true
+}
+
 func isRedisError(err error) bool {
 	_, ok := err.(proto.RedisError)
 	return ok
@@ -92,6 +180,10 @@ func isBadConn(err error, allowTimeout bool, addr string) bool {
 			// Close connections in read only state in case domain addr is used
 			// and domain resolves to a different Redis Server. See #790.
 			return true
+		case isNoAuthError(err):
+			// The authentication was revoked or expired, e.g. a token.
+			// A new connection authenticates with fresh credentials.
+			return true
 		case isMovedSameConnAddr(err, addr):
 			// Close connections when we are asked to move to the same addr
 			// of the connection. Force a DNS resolution when all connections
@@ -144,6 +236,37 @@ func isReadOnlyError(err error) bool {
 	return strings.HasPrefix(err.Error(), "READONLY ")
 }
 
+func isNoAuthError(err error) bool {
+	return strings.HasPrefix(err.Error(), "NOAUTH ")
+}
+
+// resp3RequiredError is returned when RESP3 is pinned by Options.Protocol,
+// but the server does not support it. errors.Is and errors.As match the error
+// of HELLO. It does not implement Unwrap, because the errors of the connection
+// initialization are unwrapped once when the connection is taken from the pool.
+type resp3RequiredError struct {
+	err error
+}
+
+func (e *resp3RequiredError) Error() string {
+	return "redis: RESP3 is required by Protocol option, but HELLO 3 failed: " + e.err.Error()
+}
+
+func (e *resp3RequiredError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+func (e *resp3RequiredError) As(target interface{}) bool {
+	return errors.As(e.err, target)
+}
+
+// isUnsupportedHelloError reports whether HELLO failed because the server
+// does not support the command or the requested protocol version.
+func isUnsupportedHelloError(err error) bool {
+	s := err.Error()
+	return strings.HasPrefix(s, "NOPROTO ") || strings.Contains(strings.ToLower(s), "unknown command")
+}
+
 func isMovedSameConnAddr(err error, addr string) bool {
 	redisError := err.Error()
 	if !strings.HasPrefix(redisError, "MOVED ") {
@@ -0,0 +1,80 @@
+package redis_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+func TestErrorPredicates(t *testing.T) {
+	cases := []struct {
+		err  error
+		pred func(error) bool
+		want bool
+	}{
+		{proto.RedisError("LOADING Redis is loading the dataset in memory"), redis.IsLoading, true},
+		{proto.RedisError("READONLY You can't write against a read only replica."), redis.IsReadOnly, true},
+		{proto.RedisError("CLUSTERDOWN The cluster is down"), redis.IsClusterDown, true},
+		{proto.RedisError("TRYAGAIN Multiple keys request during rehashing of slot"), redis.IsTryAgain, true},
+		{proto.RedisError("NOSCRIPT No matching script. Please use EVAL."), redis.IsNoScript, true},
+		{proto.RedisError("ERR max number of clients reached"), redis.IsMaxClients, true},
+		{fmt.Errorf("wrapped: %w", proto.RedisError("LOADING loading")), redis.IsLoading, true},
+		{errors.New("LOADING not a redis error"), redis.IsLoading, false},
+		{proto.RedisError("ERR unknown command"), redis.IsNoScript, false},
+		{nil, redis.IsReadOnly, false},
+		{redis.Nil, redis.IsNil, true},
+		{fmt.Errorf("wrapped: %w", redis.Nil), redis.IsNil, true},
+		{proto.RedisError("ERR unknown command"), redis.IsNil, false},
+		{nil, redis.IsNil, false},
+	}
+
+	for _, c := range cases {
+		if got := c.pred(c.err); got != c.want {
+			t.Errorf("%v: got %v, wanted %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestIsMoved(t *testing.T) {
+	addr, slot, ok := redis.IsMoved(proto.RedisError("MOVED 3999 127.0.0.1:6381"))
+	if !ok || addr != "127.0.0.1:6381" || slot != 3999 {
+		t.Errorf("got %q %d %v, wanted 127.0.0.1:6381 3999 true", addr, slot, ok)
+	}
+
+	if _, _, ok := redis.IsMoved(proto.RedisError("ASK 3999 127.0.0.1:6381")); ok {
+		t.Error("ASK must not be reported as MOVED")
+	}
+
+	addr, slot, ok = redis.IsAsk(proto.RedisError("ASK 12 127.0.0.1:6380"))
+	if !ok || addr != "127.0.0.1:6380" || slot != 12 {
+		t.Errorf("got %q %d %v, wanted 127.0.0.1:6380 12 true", addr, slot, ok)
+	}
+}
+
+func TestResultOK(t *testing.T) {
+	cases := []struct {
+		cmd    *redis.StringCmd
+		val    string
+		ok     bool
+		hasErr bool
+	}{
+		{redis.NewStringResult("value", nil), "value", true, false},
+		{redis.NewStringResult("", nil), "", true, false},
+		{redis.NewStringResult("", redis.Nil), "", false, false},
+		{redis.NewStringResult("", errors.New("failed")), "", false, true},
+	}
+
+	for _, c := range cases {
+		val, ok, err := c.cmd.ResultOK()
+		if val != c.val || ok != c.ok || (err != nil) != c.hasErr {
+			t.Errorf("%v: got %q, %v, %v", c.cmd, val, ok, err)
+		}
+	}
+
+	if _, ok, err := redis.NewFloatResult(0, redis.Nil).ResultOK(); ok || err != nil {
+		t.Errorf("got %v, %v, wanted a missing value", ok, err)
+	}
+}
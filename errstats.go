@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9/internal/pool"
+)
+
+// ErrStats contains the numbers of errors the client got by category.
+// Every attempt is counted, so a retried command may be counted several times.
+type ErrStats struct {
+	Dial        uint64 // number of times a connection could not be established
+	Timeout     uint64 // number of times reading or writing timed out
+	PoolTimeout uint64 // number of times a connection could not be taken from the pool in time
+	Moved       uint64 // number of MOVED and ASK redirections
+	ReadOnly    uint64 // number of READONLY errors
+	OOM         uint64 // number of OOM errors
+	Redis       uint64 // number of other errors returned by Redis
+}
+
+// errStats counts the errors, shared by all clones of the options.
+type errStats struct {
+	dial        uint64 // atomic
+	timeout     uint64 // atomic
+	poolTimeout uint64 // atomic
+	moved       uint64 // atomic
+	readOnly    uint64 // atomic
+	oom         uint64 // atomic
+	redis       uint64 // atomic
+}
+
+// add counts the err of an attempt. Errors of attempts that did not get
+// a connection are dial errors unless the pool timed out.
+func (s *errStats) add(err error, connected bool) {
+	if s == nil || err == nil {
+		return
+	}
+
+	if errors.Is(err, context.Canceled) || err == pool.ErrClosed {
+		return
+	}
+	if err == pool.ErrPoolTimeout {
+		atomic.AddUint64(&s.poolTimeout, 1)
+		return
+	}
+	if !connected {
+		atomic.AddUint64(&s.dial, 1)
+		return
+	}
+
+	if isRedisError(err) {
+		s.addRedisError(err)
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		atomic.AddUint64(&s.timeout, 1)
+		return
+	}
+	if err, ok := err.(timeoutError); ok && err.Timeout() {
+		atomic.AddUint64(&s.timeout, 1)
+	}
+}
+
+// addCmds counts the errors returned by Redis for the cmds.
+func (s *errStats) addCmds(cmds []Cmder) {
+	if s == nil {
+		return
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Err(); isRedisError(err) {
+			s.addRedisError(err)
+		}
+	}
+}
+
+func (s *errStats) addRedisError(err error) {
+	if err == Nil || err == TxFailedErr {
+		return
+	}
+	switch msg := err.Error(); {
+	case strings.HasPrefix(msg, "MOVED "), strings.HasPrefix(msg, "ASK "):
+		atomic.AddUint64(&s.moved, 1)
+	case strings.HasPrefix(msg, "READONLY "):
+		atomic.AddUint64(&s.readOnly, 1)
+	case strings.HasPrefix(msg, "OOM "):
+		atomic.AddUint64(&s.oom, 1)
+	default:
+		atomic.AddUint64(&s.redis, 1)
+	}
+}
+
+func (s *errStats) stats() *ErrStats {
+	return &ErrStats{
+		Dial:        atomic.LoadUint64(&s.dial),
+		Timeout:     atomic.LoadUint64(&s.timeout),
+		PoolTimeout: atomic.LoadUint64(&s.poolTimeout),
+		Moved:       atomic.LoadUint64(&s.moved),
+		ReadOnly:    atomic.LoadUint64(&s.readOnly),
+		OOM:         atomic.LoadUint64(&s.oom),
+		Redis:       atomic.LoadUint64(&s.redis),
+	}
+}
+
+// ErrStats returns the numbers of errors the client got by category.
+func (c *Client) ErrStats() *ErrStats {
+	return c.opt.errStats.stats()
+}
+
+// ErrStats returns the numbers of errors got from all cluster nodes by category.
+func (c *ClusterClient) ErrStats() *ErrStats {
+	return c.opt.errStats.stats()
+}
+
+// ErrStats returns the numbers of errors got from all ring shards by category.
+func (c *Ring) ErrStats() *ErrStats {
+	return c.opt.errStats.stats()
+}
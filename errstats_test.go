@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/redis/go-redis/v9/internal/pool"
+)
+
+func TestErrStats(t *testing.T) {
+	tests := []struct {
+		reply string
+		want  ErrStats
+	}{
+		{"", ErrStats{Dial: 1}},
+		{"-MOVED 3999 127.0.0.1:6381\r\n", ErrStats{Moved: 1}},
+		{"-ASK 3999 127.0.0.1:6381\r\n", ErrStats{Moved: 1}},
+		{"-READONLY You can't write against a read only replica.\r\n", ErrStats{ReadOnly: 1}},
+		{"-OOM command not allowed when used memory > 'maxmemory'.\r\n", ErrStats{OOM: 1}},
+		{"-ERR unknown command\r\n", ErrStats{Redis: 1}},
+		{"$-1\r\n", ErrStats{}},
+	}
+	for _, tt := range tests {
+		client := NewClient(&Options{
+			Addr: "stub:6379",
+			Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				if tt.reply == "" {
+					return nil, errors.New("connection refused")
+				}
+				return &ConnStub{init: initHello, resp: []byte(tt.reply)}, nil
+			},
+			MaxRetries:       -1,
+			DisableIndentity: true,
+		})
+
+		_ = client.Get(ctx, "key").Err()
+		if got := *client.ErrStats(); got != tt.want {
+			t.Errorf("%q: got %+v, expected %+v", tt.reply, got, tt.want)
+		}
+
+		_ = client.Close()
+	}
+}
+
+func TestErrStatsPipeline(t *testing.T) {
+	client := NewClient(&Options{
+		Addr: "stub:6379",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &ConnStub{init: initHello, resp: []byte("-OOM command not allowed\r\n")}, nil
+		},
+		MaxRetries:       -1,
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	_, _ = client.Pipelined(ctx, func(pipe Pipeliner) error {
+		pipe.Set(ctx, "key1", "value", 0)
+		pipe.Set(ctx, "key2", "value", 0)
+		return nil
+	})
+
+	// Each command of the pipeline is counted.
+	got := *client.ErrStats()
+	want := ErrStats{OOM: 2}
+	if got != want {
+		t.Fatalf("got %+v, expected %+v", got, want)
+	}
+}
+
+func TestErrStatsAdd(t *testing.T) {
+	s := new(errStats)
+	s.add(pool.ErrPoolTimeout, false)
+	s.add(os.ErrDeadlineExceeded, true)
+	s.add(context.DeadlineExceeded, true)
+	s.add(context.Canceled, true)
+	s.add(ErrClosed, false)
+	s.add(errors.New("connection reset"), true)
+
+	got := *s.stats()
+	want := ErrStats{PoolTimeout: 1, Timeout: 2}
+	if got != want {
+		t.Fatalf("got %+v, expected %+v", got, want)
+	}
+
+	// The nil stats are ignored.
+	var nilStats *errStats
+	nilStats.add(pool.ErrPoolTimeout, false)
+}
@@ -0,0 +1,24 @@
+package redis
+
+import (
+	"expvar"
+)
+
+// StatsReporter is implemented by Client, ClusterClient and Ring.
+type StatsReporter interface {
+	PoolStats() *PoolStats
+	ErrStats() *ErrStats
+}
+
+// PublishExpvar publishes the pool and error stats of the client in expvar
+// under the name, e.g. to serve them with the /debug/vars handler.
+// The stats are read each time the variable is formatted. Like expvar.Publish,
+// it panics if the name is already used.
+func PublishExpvar(name string, client StatsReporter) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return map[string]interface{}{
+			"pool":   client.PoolStats(),
+			"errors": client.ErrStats(),
+		}
+	}))
+}
@@ -0,0 +1,35 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net"
+	"testing"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	client := NewClient(&Options{
+		Addr: "stub:6379",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		},
+		MaxRetries: -1,
+	})
+	defer client.Close()
+
+	PublishExpvar("redis_test_client", client)
+	_ = client.Ping(ctx).Err()
+
+	var stats struct {
+		Pool   PoolStats
+		Errors ErrStats
+	}
+	if err := json.Unmarshal([]byte(expvar.Get("redis_test_client").String()), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Pool.Misses != 1 || stats.Errors.Dial != 1 {
+		t.Fatalf("got %+v, expected a pool miss and a dial error", stats)
+	}
+}
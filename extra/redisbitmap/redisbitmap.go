@@ -0,0 +1,330 @@
+// Package redisbitmap implements large bitmaps split into chunks stored in
+// Redis, and the tracking of the active users per day using the bitmaps.
+package redisbitmap
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Options configures New.
+type Options struct {
+	// ChunkBits is the number of the bits stored in a key, so that setting
+	// a large offset does not allocate a huge string. The bitmaps combined
+	// by BitOp must have the same ChunkBits. Default is 2^23 bits (1MB).
+	ChunkBits int64
+
+	// TTL is set on the keys of the bitmap by SetBit. Default is 0,
+	// which keeps the bitmap until it is deleted.
+	TTL time.Duration
+}
+
+func (opt *Options) init() *Options {
+	o := Options{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.ChunkBits <= 0 {
+		o.ChunkBits = 1 << 23
+	}
+	return &o
+}
+
+// Bitmap is a bitmap split into chunks stored in the keys "<key>:<chunk>",
+// and the set "<key>:chunks" of its chunks. With redis.ClusterClient,
+// the bitmaps combined by BitOp must share a hash tag,
+// e.g. "active:{app}:2024-01-01".
+type Bitmap struct {
+	c   redis.Cmdable
+	key string
+	opt *Options
+}
+
+// New returns the bitmap stored in the keys prefixed with the key.
+func New(c redis.Cmdable, key string, opt *Options) *Bitmap {
+	return &Bitmap{
+		c:   c,
+		key: key,
+		opt: opt.init(),
+	}
+}
+
+// Key returns the key of the bitmap.
+func (b *Bitmap) Key() string {
+	return b.key
+}
+
+func (b *Bitmap) chunkKey(chunk int64) string {
+	return b.key + ":" + strconv.FormatInt(chunk, 10)
+}
+
+func (b *Bitmap) chunksKey() string {
+	return b.key + ":chunks"
+}
+
+// chunks returns the sorted chunks of the bitmap.
+func (b *Bitmap) chunks(ctx context.Context) ([]int64, error) {
+	members, err := b.c.SMembers(ctx, b.chunksKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+	return parseChunks(members)
+}
+
+func parseChunks(members []string) ([]int64, error) {
+	chunks := make([]int64, len(members))
+	for i, member := range members {
+		chunk, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		chunks[i] = chunk
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i] < chunks[j] })
+	return chunks, nil
+}
+
+// SetBit sets the bit at the offset and returns its previous value.
+func (b *Bitmap) SetBit(ctx context.Context, offset int64, value bool) (bool, error) {
+	chunk := offset / b.opt.ChunkBits
+	var bit int
+	if value {
+		bit = 1
+	}
+
+	var cmd *redis.IntCmd
+	_, err := b.c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		cmd = pipe.SetBit(ctx, b.chunkKey(chunk), offset%b.opt.ChunkBits, bit)
+		pipe.SAdd(ctx, b.chunksKey(), chunk)
+		if b.opt.TTL > 0 {
+			pipe.Expire(ctx, b.chunkKey(chunk), b.opt.TTL)
+			pipe.Expire(ctx, b.chunksKey(), b.opt.TTL)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return cmd.Val() == 1, nil
+}
+
+// GetBit returns the bit at the offset.
+func (b *Bitmap) GetBit(ctx context.Context, offset int64) (bool, error) {
+	chunk := offset / b.opt.ChunkBits
+	n, err := b.c.GetBit(ctx, b.chunkKey(chunk), offset%b.opt.ChunkBits).Result()
+	return n == 1, err
+}
+
+// Count returns the number of the set bits.
+func (b *Bitmap) Count(ctx context.Context) (int64, error) {
+	chunks, err := b.chunks(ctx)
+	if err != nil || len(chunks) == 0 {
+		return 0, err
+	}
+
+	cmds, err := b.c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, chunk := range chunks {
+			pipe.BitCount(ctx, b.chunkKey(chunk), nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	for _, cmd := range cmds {
+		n += cmd.(*redis.IntCmd).Val()
+	}
+	return n, nil
+}
+
+// Pos returns the offset of the first bit set to the value, or -1 if there
+// is no set bit.
+func (b *Bitmap) Pos(ctx context.Context, value bool) (int64, error) {
+	chunks, err := b.chunks(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var bit int64
+	if value {
+		bit = 1
+	}
+	var next int64
+	for _, chunk := range chunks {
+		if !value && chunk > next {
+			// The missing chunk is unset.
+			return next * b.opt.ChunkBits, nil
+		}
+		next = chunk + 1
+
+		pos, err := b.c.BitPos(ctx, b.chunkKey(chunk), bit).Result()
+		if err != nil {
+			return 0, err
+		}
+		if pos >= 0 && pos < b.opt.ChunkBits {
+			return chunk*b.opt.ChunkBits + pos, nil
+		}
+	}
+	if value {
+		return -1, nil
+	}
+	return next * b.opt.ChunkBits, nil
+}
+
+// BitOp stores the result of the operation, i.e. "and", "or", "xor" or "not",
+// over the source bitmaps in the bitmap.
+func (b *Bitmap) BitOp(ctx context.Context, op string, srcs ...*Bitmap) error {
+	keys := make([]string, len(srcs))
+	for i, src := range srcs {
+		keys[i] = src.chunksKey()
+	}
+	members, err := b.c.SUnion(ctx, keys...).Result()
+	if err != nil {
+		return err
+	}
+	chunks, err := parseChunks(members)
+	if err != nil {
+		return err
+	}
+	oldChunks, err := b.chunks(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, chunk := range oldChunks {
+			pipe.Del(ctx, b.chunkKey(chunk))
+		}
+		pipe.Del(ctx, b.chunksKey())
+		for _, chunk := range chunks {
+			args := make([]interface{}, 0, 3+len(srcs))
+			args = append(args, "bitop", op, b.chunkKey(chunk))
+			for _, src := range srcs {
+				args = append(args, src.chunkKey(chunk))
+			}
+			pipe.Do(ctx, args...)
+			pipe.SAdd(ctx, b.chunksKey(), chunk)
+		}
+		return nil
+	})
+	return err
+}
+
+// Expire sets the TTL of the bitmap.
+func (b *Bitmap) Expire(ctx context.Context, ttl time.Duration) error {
+	chunks, err := b.chunks(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = b.c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, chunk := range chunks {
+			pipe.Expire(ctx, b.chunkKey(chunk), ttl)
+		}
+		pipe.Expire(ctx, b.chunksKey(), ttl)
+		return nil
+	})
+	return err
+}
+
+// Delete deletes the bitmap.
+func (b *Bitmap) Delete(ctx context.Context) error {
+	chunks, err := b.chunks(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = b.c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, chunk := range chunks {
+			pipe.Del(ctx, b.chunkKey(chunk))
+		}
+		pipe.Del(ctx, b.chunksKey())
+		return nil
+	})
+	return err
+}
+
+//------------------------------------------------------------------------------
+
+// Activity tracks the active users per day using a Bitmap per day indexed
+// by the user ID, e.g. to compute the retention of the user cohorts.
+// The bitmaps are stored in the keys "activity:{name}:<date>".
+type Activity struct {
+	c      redis.Cmdable
+	prefix string
+	opt    *Options
+}
+
+// NewActivity returns the activity of the name. The bitmaps of the days
+// are kept for the ttl after they were last updated.
+func NewActivity(c redis.Cmdable, name string, ttl time.Duration) *Activity {
+	return &Activity{
+		c:      c,
+		prefix: "activity:{" + name + "}:",
+		opt:    &Options{TTL: ttl},
+	}
+}
+
+// Day returns the bitmap of the users active on the day.
+func (a *Activity) Day(day time.Time) *Bitmap {
+	return New(a.c, a.prefix+day.UTC().Format("2006-01-02"), a.opt)
+}
+
+// Mark marks the user active at the time.
+func (a *Activity) Mark(ctx context.Context, userID int64, t time.Time) error {
+	_, err := a.Day(t).SetBit(ctx, userID, true)
+	return err
+}
+
+// IsActive reports whether the user was active on the day.
+func (a *Activity) IsActive(ctx context.Context, userID int64, day time.Time) (bool, error) {
+	return a.Day(day).GetBit(ctx, userID)
+}
+
+// Active returns the number of the users active on the day.
+func (a *Activity) Active(ctx context.Context, day time.Time) (int64, error) {
+	return a.Day(day).Count(ctx)
+}
+
+// ActiveBetween returns the number of the users active on any day
+// between start and end.
+func (a *Activity) ActiveBetween(ctx context.Context, start, end time.Time) (int64, error) {
+	var days []*Bitmap
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, a.Day(day))
+	}
+	return a.count(ctx, "or", days...)
+}
+
+// Retained returns the number of the users active on the cohort day
+// who were active again on the day.
+func (a *Activity) Retained(ctx context.Context, cohort, day time.Time) (int64, error) {
+	return a.count(ctx, "and", a.Day(cohort), a.Day(day))
+}
+
+// count counts the bits of the operation over the bitmaps using
+// a temporary bitmap.
+func (a *Activity) count(ctx context.Context, op string, srcs ...*Bitmap) (int64, error) {
+	if len(srcs) == 0 {
+		return 0, nil
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return 0, err
+	}
+	tmp := New(a.c, a.prefix+"tmp:"+hex.EncodeToString(b), a.opt)
+	defer func() {
+		_ = tmp.Delete(context.Background())
+	}()
+
+	if err := tmp.BitOp(ctx, op, srcs...); err != nil {
+		return 0, err
+	}
+	return tmp.Count(ctx)
+}
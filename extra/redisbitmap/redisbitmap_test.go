@@ -0,0 +1,121 @@
+package redisbitmap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/extra/redistest/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+var rdb *redis.Client
+
+func TestGinkgo(t *testing.T) {
+	rdb = redistest.NewClient(t, nil)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "redisbitmap")
+}
+
+// Each spec starts with an empty server.
+var _ = BeforeEach(func() {
+	Expect(rdb.FlushAll(context.TODO()).Err()).NotTo(HaveOccurred())
+})
+
+var _ = Describe("Bitmap", func() {
+	ctx := context.TODO()
+
+	It("splits the bitmap into chunks", func() {
+		bm := New(rdb, "bm", &Options{ChunkBits: 16})
+		for _, offset := range []int64{3, 17, 40} {
+			_, err := bm.SetBit(ctx, offset, true)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(rdb.Keys(ctx, "bm:*").Val()).To(ConsistOf("bm:0", "bm:1", "bm:2", "bm:chunks"))
+		Expect(rdb.StrLen(ctx, "bm:2").Val()).To(Equal(int64(2)))
+
+		old, err := bm.SetBit(ctx, 17, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(old).To(BeTrue())
+		Expect(bm.GetBit(ctx, 40)).To(BeTrue())
+		Expect(bm.GetBit(ctx, 41)).To(BeFalse())
+		Expect(bm.GetBit(ctx, 1000)).To(BeFalse())
+
+		Expect(bm.Count(ctx)).To(Equal(int64(3)))
+		Expect(bm.Pos(ctx, true)).To(Equal(int64(3)))
+		Expect(bm.Pos(ctx, false)).To(Equal(int64(0)))
+
+		Expect(bm.Delete(ctx)).NotTo(HaveOccurred())
+		Expect(rdb.DBSize(ctx).Val()).To(BeZero())
+		Expect(bm.Count(ctx)).To(BeZero())
+		Expect(bm.Pos(ctx, true)).To(Equal(int64(-1)))
+	})
+
+	It("finds the first unset bit in a missing chunk", func() {
+		bm := New(rdb, "bm", &Options{ChunkBits: 8})
+		for offset := int64(0); offset < 8; offset++ {
+			_, err := bm.SetBit(ctx, offset, true)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		_, err := bm.SetBit(ctx, 20, true)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(bm.Pos(ctx, false)).To(Equal(int64(8)))
+	})
+
+	It("combines the bitmaps", func() {
+		a := New(rdb, "{bm}:a", &Options{ChunkBits: 16})
+		b := New(rdb, "{bm}:b", &Options{ChunkBits: 16})
+		for _, offset := range []int64{1, 20} {
+			_, err := a.SetBit(ctx, offset, true)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		for _, offset := range []int64{20, 40} {
+			_, err := b.SetBit(ctx, offset, true)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		dst := New(rdb, "{bm}:dst", &Options{ChunkBits: 16})
+		Expect(dst.BitOp(ctx, "or", a, b)).NotTo(HaveOccurred())
+		Expect(dst.Count(ctx)).To(Equal(int64(3)))
+
+		Expect(dst.BitOp(ctx, "and", a, b)).NotTo(HaveOccurred())
+		Expect(dst.Count(ctx)).To(Equal(int64(1)))
+		Expect(dst.Pos(ctx, true)).To(Equal(int64(20)))
+
+		Expect(dst.Expire(ctx, time.Minute)).NotTo(HaveOccurred())
+		Expect(rdb.TTL(ctx, "{bm}:dst:chunks").Val()).To(BeNumerically("~", time.Minute, time.Second))
+	})
+})
+
+var _ = Describe("Activity", func() {
+	ctx := context.TODO()
+
+	It("counts the retained users", func() {
+		activity := NewActivity(rdb, "app", 90*24*time.Hour)
+		day1 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		day2 := day1.AddDate(0, 0, 1)
+		for _, mark := range []struct {
+			user int64
+			day  time.Time
+		}{
+			{1, day1}, {2, day1}, {3, day1},
+			{2, day2}, {3, day2}, {4, day2},
+		} {
+			Expect(activity.Mark(ctx, mark.user, mark.day)).NotTo(HaveOccurred())
+		}
+		Expect(rdb.TTL(ctx, "activity:{app}:2024-01-01:0").Val()).To(BeNumerically("~", 90*24*time.Hour, time.Minute))
+
+		Expect(activity.IsActive(ctx, 1, day1)).To(BeTrue())
+		Expect(activity.IsActive(ctx, 1, day2)).To(BeFalse())
+		Expect(activity.Active(ctx, day1)).To(Equal(int64(3)))
+		Expect(activity.Retained(ctx, day1, day2)).To(Equal(int64(2)))
+		Expect(activity.ActiveBetween(ctx, day1, day2)).To(Equal(int64(4)))
+
+		// The temporary bitmaps are deleted.
+		Expect(rdb.Keys(ctx, "*").Val()).To(HaveLen(4))
+	})
+})
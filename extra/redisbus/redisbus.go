@@ -0,0 +1,199 @@
+// Package redisbus implements a message bus broadcasting the typed messages
+// of the topics to their subscribers using Redis Pub/Sub.
+package redisbus
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Options configures New.
+type Options struct {
+	// Codec encoding the messages. Default is redis.JSONCodec.
+	Codec redis.Codec
+
+	// Prefix of the channels of the topics. Default is "bus:".
+	Prefix string
+}
+
+func (opt *Options) init() *Options {
+	o := Options{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Codec == nil {
+		o.Codec = redis.JSONCodec{}
+	}
+	if o.Prefix == "" {
+		o.Prefix = "bus:"
+	}
+	return &o
+}
+
+// Bus broadcasts the messages of the topics to their subscribers using
+// Pub/Sub, so the messages published while a subscriber is disconnected
+// are lost. Bus is safe for concurrent use by multiple goroutines.
+type Bus struct {
+	c   redis.UniversalClient
+	opt *Options
+}
+
+// New returns a Bus using the client, e.g. redis.Client, redis.Ring
+// or redis.ClusterClient.
+func New(c redis.UniversalClient, opt *Options) *Bus {
+	return &Bus{
+		c:   c,
+		opt: opt.init(),
+	}
+}
+
+// SubscribeOptions configures the subscriptions of a Topic.
+type SubscribeOptions struct {
+	// Workers is the number of the goroutines handling the messages.
+	// Default is 1, which handles the messages in order.
+	Workers int
+
+	// QueueSize is the number of the received messages waiting for a worker.
+	// Default is 100.
+	QueueSize int
+	// Policy defines what to do when the queue is full.
+	// Default is redis.ChannelDropAfterTimeout.
+	Policy redis.ChannelPolicy
+
+	// OnError is called with the messages that can't be decoded and
+	// the errors of the handler. Default logs the errors.
+	OnError func(topic string, payload string, err error)
+}
+
+func (opt *SubscribeOptions) init() *SubscribeOptions {
+	o := SubscribeOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Workers <= 0 {
+		o.Workers = 1
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 100
+	}
+	if o.OnError == nil {
+		o.OnError = func(topic string, payload string, err error) {
+			log.Printf("redisbus: topic %q failed: %s", topic, err)
+		}
+	}
+	return &o
+}
+
+// Topic is a topic of a Bus with the messages of type T.
+type Topic[T any] struct {
+	bus     *Bus
+	name    string
+	channel string
+}
+
+// NewTopic returns the topic of the bus stored in the channel "bus:<name>".
+func NewTopic[T any](bus *Bus, name string) *Topic[T] {
+	return &Topic[T]{
+		bus:     bus,
+		name:    name,
+		channel: bus.opt.Prefix + name,
+	}
+}
+
+// Publish encodes the message and publishes it to the subscribers.
+func (t *Topic[T]) Publish(ctx context.Context, msg T) error {
+	b, err := t.bus.opt.Codec.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return t.bus.c.Publish(ctx, t.channel, b).Err()
+}
+
+// Subscribe calls the handler with the messages of the topic until the
+// subscription is drained. The subscription reconnects and resubscribes
+// automatically after a network error.
+//
+//	sub, err := topic.Subscribe(ctx, func(ctx context.Context, event OrderPlaced) error {
+//		return notify(ctx, event)
+//	}, &redisbus.SubscribeOptions{Workers: 8})
+//	...
+//	sub.Drain(ctx)
+func (t *Topic[T]) Subscribe(
+	ctx context.Context, handler func(ctx context.Context, msg T) error, opt *SubscribeOptions,
+) (*Subscription, error) {
+	opt = opt.init()
+
+	pubsub := t.bus.c.Subscribe(ctx, t.channel)
+	// Wait for the confirmation, so the messages published after Subscribe
+	// returns are received.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	sub := &Subscription{
+		pubsub: pubsub,
+		done:   make(chan struct{}),
+	}
+	// The channel is closed when the subscription is drained.
+	ch := pubsub.Channel(redis.WithChannelSize(opt.QueueSize), redis.WithChannelPolicy(opt.Policy))
+
+	var wg sync.WaitGroup
+	for i := 0; i < opt.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range ch {
+				var v T
+				if err := t.bus.opt.Codec.Unmarshal([]byte(msg.Payload), &v); err != nil {
+					opt.OnError(t.name, msg.Payload, err)
+					continue
+				}
+				if err := handler(ctx, v); err != nil {
+					opt.OnError(t.name, msg.Payload, err)
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(sub.done)
+	}()
+
+	return sub, nil
+}
+
+// Subscription is a subscription to a Topic.
+type Subscription struct {
+	pubsub *redis.PubSub
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// Drain unsubscribes and waits until the received messages are handled
+// or the context is done.
+func (s *Subscription) Drain(ctx context.Context) error {
+	var err error
+	s.closeOnce.Do(func() {
+		err = s.pubsub.Close()
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close unsubscribes and waits until the received messages are handled.
+func (s *Subscription) Close() error {
+	return s.Drain(context.Background())
+}
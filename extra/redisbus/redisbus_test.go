@@ -0,0 +1,100 @@
+package redisbus
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/extra/redistest/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+var rdb *redis.Client
+
+func TestGinkgo(t *testing.T) {
+	rdb = redistest.NewClient(t, nil)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "redisbus")
+}
+
+// Each spec starts with an empty server.
+var _ = BeforeEach(func() {
+	Expect(rdb.FlushAll(context.TODO()).Err()).NotTo(HaveOccurred())
+})
+
+type event struct {
+	ID int `json:"id"`
+}
+
+var _ = Describe("Topic", func() {
+	ctx := context.TODO()
+
+	It("delivers the messages to the subscribers", func() {
+		topic := NewTopic[event](New(rdb, nil), "order")
+
+		var mu sync.Mutex
+		var ids []int
+		var errs []string
+		received := make(chan struct{}, 3)
+		sub, err := topic.Subscribe(ctx, func(ctx context.Context, e event) error {
+			mu.Lock()
+			ids = append(ids, e.ID)
+			mu.Unlock()
+			received <- struct{}{}
+			return nil
+		}, &SubscribeOptions{
+			Workers: 2,
+			OnError: func(topic string, payload string, err error) {
+				mu.Lock()
+				errs = append(errs, topic+": "+payload)
+				mu.Unlock()
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(topic.Publish(ctx, event{ID: 1})).NotTo(HaveOccurred())
+		Expect(rdb.Publish(ctx, "bus:order", "not json").Err()).NotTo(HaveOccurred())
+		Expect(topic.Publish(ctx, event{ID: 2})).NotTo(HaveOccurred())
+		Expect(topic.Publish(ctx, event{ID: 3})).NotTo(HaveOccurred())
+		for i := 0; i < 3; i++ {
+			Eventually(received).Should(Receive())
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		Expect(sub.Drain(ctx)).NotTo(HaveOccurred())
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(ids).To(ConsistOf(1, 2, 3))
+		Expect(errs).To(Equal([]string{"order: not json"}))
+	})
+
+	It("waits for the received messages on Drain", func() {
+		bus := New(rdb, &Options{Prefix: "events:"})
+		topic := NewTopic[event](bus, "order")
+
+		handled := make(chan int, 10)
+		sub, err := topic.Subscribe(ctx, func(ctx context.Context, e event) error {
+			time.Sleep(10 * time.Millisecond)
+			handled <- e.ID
+			return nil
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		for i := 1; i <= 3; i++ {
+			Expect(topic.Publish(ctx, event{ID: i})).NotTo(HaveOccurred())
+		}
+		// The first message is handled, so the others are received.
+		Eventually(handled).Should(Receive(Equal(1)))
+
+		Expect(sub.Close()).NotTo(HaveOccurred())
+		// The messages of a single worker are handled in order.
+		Expect(handled).To(Receive(Equal(2)))
+		Expect(handled).To(Receive(Equal(3)))
+	})
+})
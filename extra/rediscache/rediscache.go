@@ -0,0 +1,316 @@
+// Package rediscache implements a read-through cache of the values loaded
+// by the callers, stored in Redis and optionally in process.
+package rediscache
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Options configures New.
+type Options struct {
+	// Codec encoding the cached values. Default is redis.JSONCodec.
+	Codec redis.Codec
+
+	// Beta of the probabilistic early refresh: the greater the beta,
+	// the earlier the values are refreshed before they expire, based on
+	// the time their loader took. Default is 1; -1 disables the early refresh.
+	Beta float64
+
+	// LocalSize is the number of the values cached in process, evicting
+	// the least recently used. Default is 0, which disables the local cache.
+	LocalSize int
+	// LocalTTL is the maximum time the values are cached in process.
+	// Default is 1 minute.
+	LocalTTL time.Duration
+}
+
+func (opt *Options) init() *Options {
+	o := Options{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Codec == nil {
+		o.Codec = redis.JSONCodec{}
+	}
+	switch o.Beta {
+	case -1:
+		o.Beta = 0
+	case 0:
+		o.Beta = 1
+	}
+	if o.LocalTTL <= 0 {
+		o.LocalTTL = time.Minute
+	}
+	return &o
+}
+
+// Cache is a read-through cache of the values loaded by the callers.
+// The concurrent loads of a key are deduplicated within the process,
+// and the values are refreshed probabilistically before they expire,
+// so that the expiration of a hot key does not stampede the loader.
+// Cache is safe for concurrent use by multiple goroutines.
+type Cache struct {
+	c   redis.Cmdable
+	opt *Options
+
+	mu    sync.Mutex
+	calls map[string]*cacheCall
+
+	local *lruCache
+}
+
+type cacheCall struct {
+	wg   sync.WaitGroup
+	data []byte
+	err  error
+}
+
+// New returns a Cache storing the values in Redis using the client,
+// e.g. redis.Client, redis.Ring or redis.ClusterClient.
+func New(c redis.Cmdable, opt *Options) *Cache {
+	opt = opt.init()
+	cache := &Cache{
+		c:     c,
+		opt:   opt,
+		calls: make(map[string]*cacheCall),
+	}
+	if opt.LocalSize > 0 {
+		cache.local = newLRUCache(opt.LocalSize)
+	}
+	return cache
+}
+
+// Get decodes the value of the key into dst. If the key is missing,
+// it calls the loader and caches the returned value for ttl.
+//
+//	var user User
+//	err := cache.Get(ctx, "user:1", time.Hour, func(ctx context.Context) (interface{}, error) {
+//		return db.LoadUser(ctx, 1)
+//	}, &user)
+func (c *Cache) Get(
+	ctx context.Context,
+	key string,
+	ttl time.Duration,
+	loader func(ctx context.Context) (interface{}, error),
+	dst interface{},
+) error {
+	if c.local != nil {
+		if data, ok := c.local.get(key); ok {
+			return c.opt.Codec.Unmarshal(data, dst)
+		}
+	}
+
+	data, err := c.do(key, func() ([]byte, error) {
+		return c.load(ctx, key, ttl, loader)
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.local != nil {
+		localTTL := c.opt.LocalTTL
+		if ttl > 0 && ttl < localTTL {
+			localTTL = ttl
+		}
+		c.local.set(key, data, localTTL)
+	}
+	return c.opt.Codec.Unmarshal(data, dst)
+}
+
+// Set encodes the value and caches it for ttl.
+func (c *Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := c.opt.Codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if c.local != nil {
+		c.local.del(key)
+	}
+	return c.c.Set(ctx, key, encodeCacheItem(data, 0), ttl).Err()
+}
+
+// Delete removes the key from the cache.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	if c.local != nil {
+		c.local.del(key)
+	}
+	return c.c.Del(ctx, key).Err()
+}
+
+// do calls fn once for the concurrent calls with the same key.
+func (c *Cache) do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	if call, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		call.wg.Wait()
+		return call.data, call.err
+	}
+	call := new(cacheCall)
+	call.wg.Add(1)
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	call.data, call.err = fn()
+	call.wg.Done()
+
+	c.mu.Lock()
+	delete(c.calls, key)
+	c.mu.Unlock()
+
+	return call.data, call.err
+}
+
+func (c *Cache) load(
+	ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (interface{}, error),
+) ([]byte, error) {
+	var get *redis.StringCmd
+	var pttl *redis.DurationCmd
+	if _, err := c.c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pttl = pipe.PTTL(ctx, key)
+		return nil
+	}); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	var cached []byte
+	if b, err := get.Bytes(); err == nil {
+		data, delta, ok := decodeCacheItem(b)
+		if !ok {
+			return nil, errors.New("rediscache: invalid cache item")
+		}
+		if !c.refreshEarly(delta, pttl.Val()) {
+			return data, nil
+		}
+		cached = data
+	}
+
+	start := time.Now()
+	value, err := loader(ctx)
+	if err != nil {
+		if cached != nil {
+			// The early refresh failed, but the cached value is still valid.
+			return cached, nil
+		}
+		return nil, err
+	}
+	delta := time.Since(start)
+
+	data, err := c.opt.Codec.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.c.Set(ctx, key, encodeCacheItem(data, delta), ttl).Err(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// refreshEarly implements the XFetch algorithm: the value is refreshed with
+// a probability growing as it gets closer to the expiration and with the time
+// its loader took.
+func (c *Cache) refreshEarly(delta, ttl time.Duration) bool {
+	if c.opt.Beta == 0 || delta <= 0 || ttl <= 0 {
+		return false
+	}
+	early := float64(delta) * c.opt.Beta * -math.Log(1-rand.Float64())
+	return early >= float64(ttl)
+}
+
+// The cached values are prefixed with the time their loader took in milliseconds.
+func encodeCacheItem(data []byte, delta time.Duration) []byte {
+	b := make([]byte, 8+len(data))
+	binary.BigEndian.PutUint64(b, uint64(delta.Milliseconds()))
+	copy(b[8:], data)
+	return b
+}
+
+func decodeCacheItem(b []byte) ([]byte, time.Duration, bool) {
+	if len(b) < 8 {
+		return nil, 0, false
+	}
+	delta := time.Duration(binary.BigEndian.Uint64(b)) * time.Millisecond
+	return b[8:], delta, true
+}
+
+//------------------------------------------------------------------------------
+
+// lruCache is the in-process tier of Cache.
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruItem struct {
+	key      string
+	data     []byte
+	expireAt time.Time
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+func (c *lruCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := el.Value.(*lruItem)
+	if time.Now().After(item.expireAt) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return item.data, true
+}
+
+func (c *lruCache) set(key string, data []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expireAt := time.Now().Add(ttl)
+	if el, ok := c.items[key]; ok {
+		item := el.Value.(*lruItem)
+		item.data = data
+		item.expireAt = expireAt
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&lruItem{key: key, data: data, expireAt: expireAt})
+	if c.ll.Len() > c.size {
+		el := c.ll.Back()
+		c.ll.Remove(el)
+		delete(c.items, el.Value.(*lruItem).key)
+	}
+}
+
+func (c *lruCache) del(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.Remove(el)
+		delete(c.items, key)
+	}
+}
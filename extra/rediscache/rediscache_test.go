@@ -0,0 +1,216 @@
+package rediscache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/extra/redistest/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+var rdb *redis.Client
+
+func TestGinkgo(t *testing.T) {
+	rdb = redistest.NewClient(t, nil)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "rediscache")
+}
+
+// Each spec starts with an empty server.
+var _ = BeforeEach(func() {
+	Expect(rdb.FlushAll(context.TODO()).Err()).NotTo(HaveOccurred())
+})
+
+type user struct {
+	Name string `json:"name"`
+}
+
+var _ = Describe("Cache", func() {
+	ctx := context.TODO()
+
+	It("loads the missing keys once", func() {
+		cache := New(rdb, nil)
+
+		var loads int32
+		loader := func(ctx context.Context) (interface{}, error) {
+			atomic.AddInt32(&loads, 1)
+			time.Sleep(10 * time.Millisecond)
+			return user{Name: "alice"}, nil
+		}
+
+		// The concurrent loads of the key are deduplicated.
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				var u user
+				Expect(cache.Get(ctx, "user:1", time.Hour, loader, &u)).NotTo(HaveOccurred())
+				Expect(u.Name).To(Equal("alice"))
+			}()
+		}
+		wg.Wait()
+
+		var u user
+		Expect(cache.Get(ctx, "user:1", time.Hour, loader, &u)).NotTo(HaveOccurred())
+		Expect(u.Name).To(Equal("alice"))
+		Expect(atomic.LoadInt32(&loads)).To(Equal(int32(1)))
+		Expect(rdb.TTL(ctx, "user:1").Val()).To(BeNumerically("~", time.Hour, time.Minute))
+
+		Expect(cache.Delete(ctx, "user:1")).NotTo(HaveOccurred())
+		Expect(rdb.Exists(ctx, "user:1").Val()).To(BeZero())
+		Expect(cache.Get(ctx, "user:1", time.Hour, loader, &u)).NotTo(HaveOccurred())
+		Expect(atomic.LoadInt32(&loads)).To(Equal(int32(2)))
+	})
+
+	It("returns the error of the loader", func() {
+		loaderErr := errors.New("loader failed")
+		var u user
+		err := New(rdb, nil).Get(ctx, "user:1", time.Hour, func(ctx context.Context) (interface{}, error) {
+			return nil, loaderErr
+		}, &u)
+		Expect(err).To(Equal(loaderErr))
+		Expect(rdb.Exists(ctx, "user:1").Val()).To(BeZero())
+	})
+
+	It("refreshes the values before they expire", func() {
+		// The value expiring in 5 seconds took an hour to load, so it is refreshed.
+		setVolatile := func() {
+			item := encodeCacheItem([]byte(`{"name":"old"}`), time.Hour)
+			Expect(rdb.Set(ctx, "volatile", item, 5*time.Second).Err()).NotTo(HaveOccurred())
+		}
+		setVolatile()
+
+		var u user
+		err := New(rdb, &Options{Beta: 1000}).Get(ctx, "volatile", time.Hour,
+			func(ctx context.Context) (interface{}, error) {
+				return nil, errors.New("loader failed")
+			}, &u)
+		Expect(err).NotTo(HaveOccurred())
+		// The cached value is returned when the refresh fails.
+		Expect(u.Name).To(Equal("old"))
+
+		err = New(rdb, &Options{Beta: 1000}).Get(ctx, "volatile", time.Hour,
+			func(ctx context.Context) (interface{}, error) {
+				return user{Name: "new"}, nil
+			}, &u)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.Name).To(Equal("new"))
+		Expect(rdb.TTL(ctx, "volatile").Val()).To(BeNumerically("~", time.Hour, time.Minute))
+
+		// The early refresh is disabled.
+		setVolatile()
+		err = New(rdb, &Options{Beta: -1}).Get(ctx, "volatile", time.Hour,
+			func(ctx context.Context) (interface{}, error) {
+				return user{Name: "new"}, nil
+			}, &u)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.Name).To(Equal("old"))
+	})
+
+	It("serves the values from the local cache", func() {
+		cache := New(rdb, &Options{LocalSize: 10})
+		Expect(cache.Set(ctx, "user:1", user{Name: "alice"}, time.Hour)).NotTo(HaveOccurred())
+
+		loader := func(ctx context.Context) (interface{}, error) {
+			return nil, errors.New("unexpected load")
+		}
+		var u user
+		Expect(cache.Get(ctx, "user:1", time.Hour, loader, &u)).NotTo(HaveOccurred())
+
+		Expect(rdb.Del(ctx, "user:1").Err()).NotTo(HaveOccurred())
+		u = user{}
+		Expect(cache.Get(ctx, "user:1", time.Hour, loader, &u)).NotTo(HaveOccurred())
+		Expect(u.Name).To(Equal("alice"))
+
+		// Delete removes the value from the local cache.
+		Expect(cache.Delete(ctx, "user:1")).NotTo(HaveOccurred())
+		Expect(cache.Get(ctx, "user:1", time.Hour, loader, &u)).To(MatchError("unexpected load"))
+	})
+})
+
+var _ = Describe("Tag", func() {
+	ctx := context.TODO()
+
+	It("invalidates the tagged keys", func() {
+		cache := New(rdb, &Options{LocalSize: 10})
+		for _, key := range []string{"{user:1}:profile", "{user:1}:orders"} {
+			Expect(cache.Set(ctx, key, user{Name: "alice"}, time.Hour)).NotTo(HaveOccurred())
+			Expect(cache.Tag(ctx, key, time.Hour, "{user:1}")).NotTo(HaveOccurred())
+		}
+		Expect(cache.Set(ctx, "{user:2}:profile", user{Name: "bob"}, time.Hour)).NotTo(HaveOccurred())
+		Expect(rdb.SMembers(ctx, "tag:{user:1}").Val()).To(ConsistOf("{user:1}:profile", "{user:1}:orders"))
+		Expect(rdb.TTL(ctx, "tag:{user:1}").Val()).To(BeNumerically("~", time.Hour, time.Minute))
+
+		// Cache the value locally.
+		var u user
+		Expect(cache.Get(ctx, "{user:1}:profile", time.Hour, nil, &u)).NotTo(HaveOccurred())
+
+		n, err := cache.InvalidateTag(ctx, "{user:1}")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(2))
+		Expect(rdb.Keys(ctx, "*").Val()).To(ConsistOf("{user:2}:profile"))
+
+		// The invalidated key is not served from the local cache.
+		var loads int
+		err = cache.Get(ctx, "{user:1}:profile", time.Hour, func(ctx context.Context) (interface{}, error) {
+			loads++
+			return user{Name: "alice"}, nil
+		}, &u)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loads).To(Equal(1))
+	})
+
+	It("removes the expired keys from the tag set", func() {
+		cache := New(rdb, nil)
+		Expect(cache.Set(ctx, "a", user{Name: "alice"}, 50*time.Millisecond)).NotTo(HaveOccurred())
+		Expect(cache.Tag(ctx, "a", 50*time.Millisecond, "users")).NotTo(HaveOccurred())
+
+		time.Sleep(100 * time.Millisecond)
+		// The tag set expired with its last key.
+		Expect(rdb.Exists(ctx, "tag:users").Val()).To(BeZero())
+
+		Expect(cache.Set(ctx, "a", user{Name: "alice"}, 50*time.Millisecond)).NotTo(HaveOccurred())
+		Expect(cache.Tag(ctx, "a", 50*time.Millisecond, "users")).NotTo(HaveOccurred())
+		Expect(cache.Set(ctx, "b", user{Name: "bob"}, time.Hour)).NotTo(HaveOccurred())
+		time.Sleep(100 * time.Millisecond)
+		Expect(cache.Tag(ctx, "b", time.Hour, "users")).NotTo(HaveOccurred())
+		Expect(rdb.SMembers(ctx, "tag:users").Val()).To(ConsistOf("b"))
+		Expect(rdb.TTL(ctx, "tag:users").Val()).To(BeNumerically("~", time.Hour, time.Minute))
+
+		n, err := cache.InvalidateTag(ctx, "users")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+	})
+})
+
+var _ = Describe("lruCache", func() {
+	It("evicts the least recently used keys", func() {
+		c := newLRUCache(2)
+		c.set("a", []byte("1"), time.Hour)
+		c.set("b", []byte("2"), time.Hour)
+		c.get("a")
+		c.set("c", []byte("3"), time.Hour)
+
+		_, ok := c.get("b")
+		Expect(ok).To(BeFalse())
+		_, ok = c.get("a")
+		Expect(ok).To(BeTrue())
+	})
+
+	It("does not return the expired keys", func() {
+		c := newLRUCache(2)
+		c.set("a", []byte("1"), -time.Second)
+		_, ok := c.get("a")
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,85 @@
+package rediscache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const cacheTagPrefix = "tag:"
+
+var (
+	// Adds the keys to the tag set, removes a few members whose keys are gone
+	// and keeps the set until the last of its keys expires.
+	tagCacheKeysScript = redis.NewScript(`
+local ttl = tonumber(ARGV[1])
+local set_ttl = redis.call("pttl", KEYS[1])
+
+for i = 2, #ARGV do
+	redis.call("sadd", KEYS[1], ARGV[i])
+end
+
+for _, key in ipairs(redis.call("srandmember", KEYS[1], 10)) do
+	if redis.call("exists", key) == 0 then
+		redis.call("srem", KEYS[1], key)
+	end
+end
+
+if ttl <= 0 then
+	redis.call("persist", KEYS[1])
+elseif set_ttl == -2 or (set_ttl >= 0 and set_ttl < ttl) then
+	redis.call("pexpire", KEYS[1], ttl)
+end
+return 1
+`)
+
+	// Deletes the tag set and its keys, returning the deleted keys.
+	invalidateCacheTagScript = redis.NewScript(`
+local keys = redis.call("smembers", KEYS[1])
+for _, key in ipairs(keys) do
+	redis.call("del", key)
+end
+redis.call("del", KEYS[1])
+return keys
+`)
+)
+
+// Tag associates the key cached for ttl with the tags, so that it is deleted
+// by InvalidateTag. The tags are stored in the sets "tag:<tag>", whose members
+// are removed lazily after their keys expire.
+//
+// The tag set and its keys are updated atomically, so with redis.Ring and
+// redis.ClusterClient they must be on the same node, e.g. the keys
+// "{user:1}:profile" and "{user:1}:orders" tagged with "{user:1}".
+//
+//	err := cache.Get(ctx, key, time.Hour, func(ctx context.Context) (interface{}, error) {
+//		if err := cache.Tag(ctx, key, time.Hour, "{user:1}"); err != nil {
+//			return nil, err
+//		}
+//		return db.LoadProfile(ctx, 1)
+//	}, &profile)
+func (c *Cache) Tag(ctx context.Context, key string, ttl time.Duration, tags ...string) error {
+	for _, tag := range tags {
+		err := tagCacheKeysScript.Run(ctx, c.c, []string{cacheTagPrefix + tag}, ttl.Milliseconds(), key).Err()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InvalidateTag atomically deletes the keys associated with the tag by Tag
+// and returns their number.
+func (c *Cache) InvalidateTag(ctx context.Context, tag string) (int, error) {
+	keys, err := invalidateCacheTagScript.Run(ctx, c.c, []string{cacheTagPrefix + tag}).StringSlice()
+	if err != nil {
+		return 0, err
+	}
+	if c.local != nil {
+		for _, key := range keys {
+			c.local.del(key)
+		}
+	}
+	return len(keys), nil
+}
@@ -17,6 +17,24 @@ func CmdString(cmd redis.Cmder) string {
 }
 
 func CmdsString(cmds []redis.Cmder) (string, string) {
+	return cmdsString(cmds, AppendCmd)
+}
+
+// SanitizedCmdString is like CmdString, but replaces the arguments
+// with "?" so the values stored in Redis are not exposed.
+func SanitizedCmdString(cmd redis.Cmder) string {
+	b := make([]byte, 0, 32)
+	b = AppendSanitizedCmd(b, cmd)
+	return String(b)
+}
+
+// SanitizedCmdsString is like CmdsString, but sanitizes the commands
+// like SanitizedCmdString.
+func SanitizedCmdsString(cmds []redis.Cmder) (string, string) {
+	return cmdsString(cmds, AppendSanitizedCmd)
+}
+
+func cmdsString(cmds []redis.Cmder, appendCmd func([]byte, redis.Cmder) []byte) (string, string) {
 	const numCmdLimit = 100
 	const numNameLimit = 10
 
@@ -33,7 +51,7 @@ func CmdsString(cmds []redis.Cmder) (string, string) {
 		if i > 0 {
 			b = append(b, '\n')
 		}
-		b = AppendCmd(b, cmd)
+		b = appendCmd(b, cmd)
 
 		if len(unqNames) >= numNameLimit {
 			continue
@@ -71,6 +89,30 @@ func AppendCmd(b []byte, cmd redis.Cmder) []byte {
 	return b
 }
 
+// AppendSanitizedCmd appends the command name, including the subcommand
+// of container commands like CLUSTER INFO, and "?" for every argument.
+func AppendSanitizedCmd(b []byte, cmd redis.Cmder) []byte {
+	const numArgLimit = 32
+
+	name := cmd.FullName()
+	b = append(b, name...)
+
+	args := cmd.Args()
+	for i := strings.Count(name, " ") + 1; i < len(args); i++ {
+		if i > numArgLimit {
+			break
+		}
+		b = append(b, " ?"...)
+	}
+
+	if err := cmd.Err(); err != nil {
+		b = append(b, ": "...)
+		b = append(b, err.Error()...)
+	}
+
+	return b
+}
+
 func appendArg(b []byte, v interface{}) []byte {
 	const argLenLimit = 64
 
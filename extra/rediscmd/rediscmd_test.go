@@ -1,10 +1,13 @@
 package rediscmd
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/bsm/ginkgo/v2"
 	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9"
 )
 
 func TestGinkgo(t *testing.T) {
@@ -29,3 +32,17 @@ var _ = Describe("AppendArg", func() {
 		Entry("", "\000", "00"),
 	)
 })
+
+var _ = Describe("SanitizedCmdString", func() {
+	DescribeTable("...",
+		func(args []interface{}, wanted string) {
+			cmd := redis.NewCmd(context.TODO(), args...)
+			Expect(SanitizedCmdString(cmd)).To(Equal(wanted))
+		},
+
+		Entry("", []interface{}{"ping"}, "ping"),
+		Entry("", []interface{}{"set", "key", "secret"}, "set ? ?"),
+		Entry("", []interface{}{"cluster", "info"}, "cluster info"),
+		Entry("", []interface{}{"command", "info", "get"}, "command info ?"),
+	)
+})
@@ -0,0 +1,228 @@
+// Package rediscounters implements named counters of events split into
+// time buckets stored in Redis.
+package rediscounters
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Buckets is the size and the retention of the buckets of Counters.
+type Buckets struct {
+	Size time.Duration
+	// TTL is the time the bucket is kept after it ends.
+	TTL time.Duration
+}
+
+var (
+	// MinuteBuckets are kept for a day.
+	MinuteBuckets = Buckets{Size: time.Minute, TTL: 24 * time.Hour}
+	// HourBuckets are kept for 30 days.
+	HourBuckets = Buckets{Size: time.Hour, TTL: 30 * 24 * time.Hour}
+)
+
+// Options configures New.
+type Options struct {
+	// Prefix of the keys. Default is "counter:".
+	Prefix string
+
+	// Buckets incremented by Counters.Incr. Default is MinuteBuckets and HourBuckets.
+	Buckets []Buckets
+
+	// FlushInterval buffers the increments in process and flushes them
+	// with a pipeline every interval, so the hot counters cost a command
+	// per interval. Default is 0, which sends every increment.
+	FlushInterval time.Duration
+}
+
+func (opt *Options) init() *Options {
+	o := Options{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Prefix == "" {
+		o.Prefix = "counter:"
+	}
+	if len(o.Buckets) == 0 {
+		o.Buckets = []Buckets{MinuteBuckets, HourBuckets}
+	}
+	return &o
+}
+
+// Point is the value of a counter bucket.
+type Point struct {
+	Time  time.Time
+	Value int64
+}
+
+// Counters are named counters of events split into time buckets, e.g.
+// page views per minute and per hour. The buckets of a counter are stored
+// in the keys "counter:{name}:<size in seconds>:<unix time>", which share
+// the hash tag of the counter. Counters are safe for concurrent use
+// by multiple goroutines.
+type Counters struct {
+	c   redis.Cmdable
+	opt *Options
+
+	mu      sync.Mutex
+	pending map[string]*pendingCounter
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+type pendingCounter struct {
+	n        int64
+	expireAt time.Time
+}
+
+// New returns Counters stored using the client, e.g. redis.Client, redis.Ring
+// or redis.ClusterClient. With Options.FlushInterval, Close must be called
+// to flush the buffered increments.
+func New(c redis.Cmdable, opt *Options) *Counters {
+	cs := &Counters{
+		c:       c,
+		opt:     opt.init(),
+		pending: make(map[string]*pendingCounter),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if cs.opt.FlushInterval > 0 {
+		go cs.flusher()
+	} else {
+		close(cs.stopped)
+	}
+	return cs
+}
+
+func (cs *Counters) key(name string, size time.Duration, t time.Time) string {
+	return cs.opt.Prefix + "{" + name + "}:" +
+		strconv.FormatInt(int64(size/time.Second), 10) + ":" +
+		strconv.FormatInt(t.Unix(), 10)
+}
+
+// Incr increments the counter by n in the current buckets.
+func (cs *Counters) Incr(ctx context.Context, name string, n int64) error {
+	return cs.IncrAt(ctx, name, time.Now(), n)
+}
+
+// IncrAt increments the counter by n in the buckets of the time.
+func (cs *Counters) IncrAt(ctx context.Context, name string, t time.Time, n int64) error {
+	cs.mu.Lock()
+	for _, b := range cs.opt.Buckets {
+		start := t.Truncate(b.Size)
+		key := cs.key(name, b.Size, start)
+		p, ok := cs.pending[key]
+		if !ok {
+			p = &pendingCounter{expireAt: start.Add(b.Size + b.TTL)}
+			cs.pending[key] = p
+		}
+		p.n += n
+	}
+	cs.mu.Unlock()
+
+	if cs.opt.FlushInterval > 0 {
+		return nil
+	}
+	return cs.Flush(ctx)
+}
+
+// Flush sends the buffered increments.
+func (cs *Counters) Flush(ctx context.Context) error {
+	cs.mu.Lock()
+	pending := cs.pending
+	cs.pending = make(map[string]*pendingCounter, len(pending))
+	cs.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	_, err := cs.c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, p := range pending {
+			pipe.IncrBy(ctx, key, p.n)
+			pipe.ExpireAt(ctx, key, p.expireAt)
+		}
+		return nil
+	})
+	return err
+}
+
+func (cs *Counters) flusher() {
+	defer close(cs.stopped)
+
+	ticker := time.NewTicker(cs.opt.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cs.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), cs.opt.FlushInterval)
+		// The failed increments are lost rather than counted twice.
+		_ = cs.Flush(ctx)
+		cancel()
+	}
+}
+
+// Close stops the background flushing and flushes the buffered increments.
+func (cs *Counters) Close() error {
+	cs.closeOnce.Do(func() {
+		close(cs.stop)
+	})
+	<-cs.stopped
+	return cs.Flush(context.Background())
+}
+
+// Range returns the values of the buckets of the size between start and end,
+// including the buckets of the times with no events.
+func (cs *Counters) Range(
+	ctx context.Context, name string, size time.Duration, start, end time.Time,
+) ([]Point, error) {
+	var points []Point
+	var keys []string
+	for t := start.Truncate(size); !t.After(end); t = t.Add(size) {
+		points = append(points, Point{Time: t})
+		keys = append(keys, cs.key(name, size, t))
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	vals, err := cs.c.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, val := range vals {
+		s, ok := val.(string)
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		points[i].Value = n
+	}
+	return points, nil
+}
+
+// Sum returns the sum of the buckets of the size between start and end.
+func (cs *Counters) Sum(ctx context.Context, name string, size time.Duration, start, end time.Time) (int64, error) {
+	points, err := cs.Range(ctx, name, size, start, end)
+	if err != nil {
+		return 0, err
+	}
+	var sum int64
+	for _, p := range points {
+		sum += p.Value
+	}
+	return sum, nil
+}
@@ -0,0 +1,82 @@
+package rediscounters
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/extra/redistest/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+var rdb *redis.Client
+
+func TestGinkgo(t *testing.T) {
+	rdb = redistest.NewClient(t, nil)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "rediscounters")
+}
+
+// Each spec starts with an empty server.
+var _ = BeforeEach(func() {
+	Expect(rdb.FlushAll(context.TODO()).Err()).NotTo(HaveOccurred())
+})
+
+var _ = Describe("Counters", func() {
+	ctx := context.TODO()
+
+	It("counts the events in the buckets", func() {
+		cs := New(rdb, nil)
+		defer cs.Close()
+
+		start := time.Now().Truncate(time.Hour)
+		for _, incr := range []struct {
+			offset time.Duration
+			n      int64
+		}{
+			{0, 1},
+			{30 * time.Second, 2},
+			{2 * time.Minute, 3},
+		} {
+			Expect(cs.IncrAt(ctx, "views", start.Add(incr.offset), incr.n)).NotTo(HaveOccurred())
+		}
+
+		minuteKey := "counter:{views}:60:" + strconv.FormatInt(start.Unix(), 10)
+		Expect(rdb.Get(ctx, minuteKey).Val()).To(Equal("3"))
+		Expect(rdb.ExpireTime(ctx, minuteKey).Val()).To(Equal(
+			time.Duration(start.Add(time.Minute+24*time.Hour).Unix()) * time.Second))
+		hourKey := "counter:{views}:3600:" + strconv.FormatInt(start.Unix(), 10)
+		Expect(rdb.Get(ctx, hourKey).Val()).To(Equal("6"))
+
+		points, err := cs.Range(ctx, "views", time.Minute, start, start.Add(2*time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points).To(Equal([]Point{
+			{Time: start, Value: 3},
+			{Time: start.Add(time.Minute)},
+			{Time: start.Add(2 * time.Minute), Value: 3},
+		}))
+
+		sum, err := cs.Sum(ctx, "views", time.Hour, start, start)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sum).To(Equal(int64(6)))
+	})
+
+	It("buffers the increments until they are flushed", func() {
+		cs := New(rdb, &Options{
+			Buckets:       []Buckets{HourBuckets},
+			FlushInterval: time.Hour,
+		})
+		for i := 0; i < 10; i++ {
+			Expect(cs.Incr(ctx, "views", 1)).NotTo(HaveOccurred())
+		}
+		Expect(rdb.DBSize(ctx).Val()).To(BeZero())
+
+		Expect(cs.Close()).NotTo(HaveOccurred())
+		key := "counter:{views}:3600:" + strconv.FormatInt(time.Now().Truncate(time.Hour).Unix(), 10)
+		Expect(rdb.Get(ctx, key).Val()).To(Equal("10"))
+	})
+})
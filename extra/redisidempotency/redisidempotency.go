@@ -0,0 +1,178 @@
+// Package redisidempotency deduplicates the retried requests identified by
+// the idempotency keys, storing their results in Redis.
+package redisidempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInProgress is returned by Idempotency.Do when the request
+// with the same key is being handled, e.g. by another process.
+var ErrInProgress = errors.New("redisidempotency: request in progress")
+
+// ErrTakenOver is returned by Idempotency.Do when fn succeeded, but its
+// result was not stored because the in-progress marker expired and
+// the request was taken over, e.g. by another process.
+var ErrTakenOver = errors.New("redisidempotency: request taken over")
+
+const (
+	pendingPrefix = "p:"
+	resultPrefix  = "r:"
+)
+
+// Deletes the marker if the request is still handled by its owner.
+var releaseMarkerScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// Stores the result if the request is still handled by the owner of the marker.
+var storeResultScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call("set", KEYS[1], ARGV[2], "px", ARGV[3])
+else
+	redis.call("set", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// Options configures New.
+type Options struct {
+	// Codec encoding the results. Default is redis.JSONCodec.
+	Codec redis.Codec
+
+	// Prefix of the keys. Default is "idempotency:".
+	Prefix string
+
+	// PendingTTL is the TTL of the in-progress marker, after which another
+	// request takes over if the handler did not finish, e.g. because
+	// the process crashed. Default is 30 seconds.
+	PendingTTL time.Duration
+}
+
+func (opt *Options) init() *Options {
+	o := Options{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Codec == nil {
+		o.Codec = redis.JSONCodec{}
+	}
+	if o.Prefix == "" {
+		o.Prefix = "idempotency:"
+	}
+	if o.PendingTTL <= 0 {
+		o.PendingTTL = 30 * time.Second
+	}
+	return &o
+}
+
+// Idempotency deduplicates the retried requests identified by
+// the idempotency keys, e.g. the Idempotency-Key HTTP header.
+// Idempotency is safe for concurrent use by multiple goroutines.
+type Idempotency struct {
+	c   redis.Cmdable
+	opt *Options
+}
+
+// New returns an Idempotency storing the results using the client,
+// e.g. redis.Client, redis.Ring or redis.ClusterClient.
+func New(c redis.Cmdable, opt *Options) *Idempotency {
+	return &Idempotency{
+		c:   c,
+		opt: opt.init(),
+	}
+}
+
+// Do calls fn once for the key and stores its result for ttl. The result of
+// the first successful call is decoded into dst, so the retried requests get
+// the same result. If the request is being handled, Do returns ErrInProgress.
+// If fn fails, its error is returned and the request can be retried.
+// If fn takes longer than Options.PendingTTL and the request is taken over,
+// the result is not stored and Do returns ErrTakenOver.
+//
+//	var payment Payment
+//	err := idem.Do(ctx, r.Header.Get("Idempotency-Key"), 24*time.Hour,
+//		func(ctx context.Context) (interface{}, error) {
+//			return charge(ctx, order)
+//		}, &payment)
+func (i *Idempotency) Do(
+	ctx context.Context,
+	key string,
+	ttl time.Duration,
+	fn func(ctx context.Context) (interface{}, error),
+	dst interface{},
+) error {
+	key = i.opt.Prefix + key
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	marker := pendingPrefix + hex.EncodeToString(b)
+
+	for {
+		ok, err := i.c.SetNX(ctx, key, marker, i.opt.PendingTTL).Result()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return i.do(ctx, key, marker, ttl, fn, dst)
+		}
+
+		val, err := i.c.Get(ctx, key).Result()
+		if err == redis.Nil {
+			// The marker expired in the meantime.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(val, resultPrefix) {
+			return ErrInProgress
+		}
+		return i.opt.Codec.Unmarshal([]byte(val[len(resultPrefix):]), dst)
+	}
+}
+
+func (i *Idempotency) do(
+	ctx context.Context,
+	key, marker string,
+	ttl time.Duration,
+	fn func(ctx context.Context) (interface{}, error),
+	dst interface{},
+) error {
+	value, err := fn(ctx)
+	if err != nil {
+		// Allow the retries.
+		_ = releaseMarkerScript.Run(ctx, i.c, []string{key}, marker).Err()
+		return err
+	}
+
+	data, err := i.opt.Codec.Marshal(value)
+	if err != nil {
+		_ = releaseMarkerScript.Run(ctx, i.c, []string{key}, marker).Err()
+		return err
+	}
+	result := resultPrefix + string(data)
+	stored, err := storeResultScript.Run(ctx, i.c, []string{key}, marker, result, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if stored == 0 {
+		return ErrTakenOver
+	}
+	return i.opt.Codec.Unmarshal(data, dst)
+}
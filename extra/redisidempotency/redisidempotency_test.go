@@ -0,0 +1,114 @@
+package redisidempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/extra/redistest/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+var rdb *redis.Client
+
+func TestGinkgo(t *testing.T) {
+	rdb = redistest.NewClient(t, nil)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "redisidempotency")
+}
+
+// Each spec starts with an empty server.
+var _ = BeforeEach(func() {
+	Expect(rdb.FlushAll(context.TODO()).Err()).NotTo(HaveOccurred())
+})
+
+type user struct {
+	Name string `json:"name"`
+}
+
+var _ = Describe("Idempotency", func() {
+	ctx := context.TODO()
+
+	It("calls fn once and stores its result", func() {
+		idem := New(rdb, nil)
+
+		var calls int
+		fn := func(ctx context.Context) (interface{}, error) {
+			calls++
+			return user{Name: "alice"}, nil
+		}
+		for i := 0; i < 2; i++ {
+			var u user
+			Expect(idem.Do(ctx, "req-1", time.Hour, fn, &u)).NotTo(HaveOccurred())
+			Expect(u.Name).To(Equal("alice"))
+		}
+		Expect(calls).To(Equal(1))
+		Expect(rdb.Get(ctx, "idempotency:req-1").Val()).To(Equal(`r:{"name":"alice"}`))
+		Expect(rdb.TTL(ctx, "idempotency:req-1").Val()).To(BeNumerically("~", time.Hour, time.Minute))
+	})
+
+	It("allows the retries of the failed requests", func() {
+		idem := New(rdb, nil)
+		var u user
+
+		fnErr := errors.New("failed")
+		err := idem.Do(ctx, "req-1", time.Hour, func(ctx context.Context) (interface{}, error) {
+			return nil, fnErr
+		}, &u)
+		Expect(err).To(Equal(fnErr))
+		Expect(rdb.Exists(ctx, "idempotency:req-1").Val()).To(BeZero())
+
+		err = idem.Do(ctx, "req-1", time.Hour, func(ctx context.Context) (interface{}, error) {
+			return user{Name: "alice"}, nil
+		}, &u)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.Name).To(Equal("alice"))
+	})
+
+	It("rejects the requests in progress", func() {
+		idem := New(rdb, &Options{PendingTTL: time.Minute})
+		var u user
+
+		started, release := make(chan struct{}), make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			var u user
+			done <- idem.Do(ctx, "req-1", time.Hour, func(ctx context.Context) (interface{}, error) {
+				close(started)
+				<-release
+				return user{Name: "alice"}, nil
+			}, &u)
+		}()
+		<-started
+		Expect(rdb.PTTL(ctx, "idempotency:req-1").Val()).To(BeNumerically("~", time.Minute, time.Second))
+
+		err := idem.Do(ctx, "req-1", time.Hour, func(ctx context.Context) (interface{}, error) {
+			Fail("fn is called for the request in progress")
+			return nil, nil
+		}, &u)
+		Expect(err).To(Equal(ErrInProgress))
+
+		close(release)
+		Expect(<-done).NotTo(HaveOccurred())
+		Expect(idem.Do(ctx, "req-1", time.Hour, nil, &u)).NotTo(HaveOccurred())
+		Expect(u.Name).To(Equal("alice"))
+	})
+
+	It("does not store the result after the marker expired", func() {
+		idem := New(rdb, &Options{PendingTTL: 50 * time.Millisecond})
+		var u user
+
+		err := idem.Do(ctx, "req-1", time.Hour, func(ctx context.Context) (interface{}, error) {
+			time.Sleep(100 * time.Millisecond)
+			// Another request takes over.
+			Expect(rdb.Set(ctx, "idempotency:req-1", "p:other", 0).Err()).NotTo(HaveOccurred())
+			return user{Name: "alice"}, nil
+		}, &u)
+		Expect(err).To(Equal(ErrTakenOver))
+		Expect(rdb.Get(ctx, "idempotency:req-1").Val()).To(Equal("p:other"))
+	})
+})
@@ -0,0 +1,368 @@
+package rediskeys
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AnalyzeKeysOptions configures AnalyzeKeys.
+type AnalyzeKeysOptions struct {
+	// Pattern of the analyzed keys. Default is "*".
+	Pattern string
+
+	// Number of keys requested by every SCAN and analyzed at once.
+	// Default is 1000.
+	BatchSize int
+
+	// Maximum number of keys analyzed per second by every server.
+	// Default is 0, which does not limit the rate.
+	MaxKeysPerSecond int
+
+	// GroupBy returns the group of the key in the report.
+	// Default replaces the runs of digits with "*", e.g. "user:*:profile".
+	GroupBy func(key string) string
+
+	// Number of the largest and the hottest keys in the report. Default is 10.
+	TopN int
+
+	// Hot queries the access frequency of the keys using OBJECT FREQ, which
+	// requires an LFU maxmemory-policy.
+	Hot bool
+}
+
+func (opt *AnalyzeKeysOptions) init() *AnalyzeKeysOptions {
+	o := AnalyzeKeysOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Pattern == "" {
+		o.Pattern = "*"
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 1000
+	}
+	if o.GroupBy == nil {
+		o.GroupBy = keyPattern
+	}
+	if o.TopN <= 0 {
+		o.TopN = 10
+	}
+	return &o
+}
+
+// KeyStats describes a key analyzed by AnalyzeKeys.
+type KeyStats struct {
+	Key  string
+	Type string
+	// Memory is the number of bytes reported by MEMORY USAGE.
+	Memory int64
+	// Freq is the access frequency reported by OBJECT FREQ,
+	// if AnalyzeKeysOptions.Hot is enabled.
+	Freq int64
+}
+
+// KeyGroupStats sums the stats of the keys of a group.
+type KeyGroupStats struct {
+	Group  string
+	Keys   int64
+	Memory int64
+	Freq   int64
+}
+
+// KeysReport is returned by AnalyzeKeys.
+type KeysReport struct {
+	Keys   int64
+	Memory int64
+
+	// Largest keys by memory, from the largest.
+	Largest []KeyStats
+	// Hottest keys by access frequency, from the hottest,
+	// if AnalyzeKeysOptions.Hot is enabled.
+	Hottest []KeyStats
+	// Groups of the keys by memory, from the largest.
+	Groups []KeyGroupStats
+}
+
+// AnalyzeKeys walks the keyspace using SCAN and reports the largest and
+// the hottest keys and the memory used by the groups of keys, e.g. to find
+// big keys and hot keys. The keys are scanned on every cluster master or
+// ring shard of the client.
+func AnalyzeKeys(ctx context.Context, c redis.UniversalClient, opt *AnalyzeKeysOptions) (*KeysReport, error) {
+	opt = opt.init()
+	a := &keysAnalyzer{
+		opt:    opt,
+		groups: make(map[string]*KeyGroupStats),
+	}
+
+	err := forEachNode(ctx, c, func(ctx context.Context, client *redis.Client) error {
+		limiter := newKeyRateLimiter(opt.MaxKeysPerSecond)
+		return scanNodeKeys(ctx, client, opt.Pattern, opt.BatchSize, func(keys []string) error {
+			stats, err := analyzeKeys(ctx, client, keys, opt.Hot)
+			if err != nil {
+				return err
+			}
+			a.add(stats)
+			return limiter.wait(ctx, len(keys))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.report(), nil
+}
+
+// analyzeKeys returns the stats of the keys, skipping the keys that no longer exist.
+func analyzeKeys(ctx context.Context, c *redis.Client, keys []string, hot bool) ([]KeyStats, error) {
+	types := make([]*redis.StatusCmd, len(keys))
+	memory := make([]*redis.IntCmd, len(keys))
+	freqs := make([]*redis.IntCmd, len(keys))
+	_, _ = c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			types[i] = pipe.Type(ctx, key)
+			memory[i] = pipe.MemoryUsage(ctx, key)
+			if hot {
+				freqs[i] = pipe.ObjectFreq(ctx, key)
+			}
+		}
+		return nil
+	})
+
+	stats := make([]KeyStats, 0, len(keys))
+	for i, key := range keys {
+		typ, err := types[i].Result()
+		if err != nil {
+			return nil, err
+		}
+		if typ == "none" {
+			continue
+		}
+		st := KeyStats{
+			Key:  key,
+			Type: typ,
+		}
+		if st.Memory, err = memory[i].Result(); err != nil && err != redis.Nil {
+			return nil, err
+		}
+		if hot {
+			if st.Freq, err = freqs[i].Result(); err != nil && err != redis.Nil {
+				return nil, err
+			}
+		}
+		stats = append(stats, st)
+	}
+	return stats, nil
+}
+
+// keysAnalyzer aggregates the stats of the keys scanned on all servers.
+type keysAnalyzer struct {
+	opt *AnalyzeKeysOptions
+
+	mu      sync.Mutex
+	keys    int64
+	memory  int64
+	largest []KeyStats
+	hottest []KeyStats
+	groups  map[string]*KeyGroupStats
+}
+
+func (a *keysAnalyzer) add(stats []KeyStats) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, st := range stats {
+		a.keys++
+		a.memory += st.Memory
+
+		name := a.opt.GroupBy(st.Key)
+		group := a.groups[name]
+		if group == nil {
+			group = &KeyGroupStats{Group: name}
+			a.groups[name] = group
+		}
+		group.Keys++
+		group.Memory += st.Memory
+		group.Freq += st.Freq
+	}
+
+	a.largest = topKeys(append(a.largest, stats...), a.opt.TopN, func(st KeyStats) int64 {
+		return st.Memory
+	})
+	if a.opt.Hot {
+		a.hottest = topKeys(append(a.hottest, stats...), a.opt.TopN, func(st KeyStats) int64 {
+			return st.Freq
+		})
+	}
+}
+
+// topKeys returns the n keys with the largest value.
+func topKeys(stats []KeyStats, n int, value func(KeyStats) int64) []KeyStats {
+	sort.SliceStable(stats, func(i, j int) bool {
+		return value(stats[i]) > value(stats[j])
+	})
+	if len(stats) > n {
+		stats = stats[:n]
+	}
+	// Do not keep the rest of the batch in memory.
+	return append([]KeyStats(nil), stats...)
+}
+
+func (a *keysAnalyzer) report() *KeysReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	groups := make([]KeyGroupStats, 0, len(a.groups))
+	for _, group := range a.groups {
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Memory != groups[j].Memory {
+			return groups[i].Memory > groups[j].Memory
+		}
+		return groups[i].Group < groups[j].Group
+	})
+
+	return &KeysReport{
+		Keys:    a.keys,
+		Memory:  a.memory,
+		Largest: a.largest,
+		Hottest: a.hottest,
+		Groups:  groups,
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// KeyspaceSample estimates the keyspace from a sample of random keys,
+// see SampleKeyspace.
+type KeyspaceSample struct {
+	// Keys is the number of keys reported by DBSIZE.
+	Keys int64
+	// Sampled is the number of sampled keys, which may repeat.
+	Sampled int
+
+	// Types is the number of the sampled keys by type.
+	Types map[string]int
+	// WithTTL is the number of the sampled keys with a TTL.
+	WithTTL int
+	// AvgTTL is the average remaining TTL of the sampled keys with a TTL.
+	AvgTTL time.Duration
+	// AvgMemory is the average number of bytes reported by MEMORY USAGE.
+	AvgMemory int64
+}
+
+// TTLRatio returns the fraction of the keys with a TTL.
+func (s *KeyspaceSample) TTLRatio() float64 {
+	if s.Sampled == 0 {
+		return 0
+	}
+	return float64(s.WithTTL) / float64(s.Sampled)
+}
+
+// EstimatedMemory returns the estimated number of bytes used by all keys.
+func (s *KeyspaceSample) EstimatedMemory() int64 {
+	return s.AvgMemory * s.Keys
+}
+
+// SampleKeyspace estimates the type distribution, the TTL coverage and
+// the average size of the keys from n keys returned by RANDOMKEY, e.g. for
+// capacity planning without scanning the whole keyspace. The keys are
+// sampled on every cluster master or ring shard of the client.
+func SampleKeyspace(ctx context.Context, c redis.UniversalClient, n int) (*KeyspaceSample, error) {
+	var mu sync.Mutex
+	sample := &KeyspaceSample{
+		Types: make(map[string]int),
+	}
+	var ttl time.Duration
+	var memory int64
+
+	err := forEachNode(ctx, c, func(ctx context.Context, client *redis.Client) error {
+		size, err := client.DBSize(ctx).Result()
+		if err != nil || size == 0 {
+			return err
+		}
+
+		randomKeys := make([]*redis.StringCmd, n)
+		_, _ = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i := range randomKeys {
+				randomKeys[i] = pipe.RandomKey(ctx)
+			}
+			return nil
+		})
+		keys := make([]string, 0, n)
+		for _, cmd := range randomKeys {
+			key, err := cmd.Result()
+			if err == redis.Nil {
+				// The keys were deleted.
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			keys = append(keys, key)
+		}
+
+		stats, err := analyzeKeys(ctx, client, keys, false)
+		if err != nil {
+			return err
+		}
+		ttls := make([]*redis.DurationCmd, len(stats))
+		_, _ = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, st := range stats {
+				ttls[i] = pipe.PTTL(ctx, st.Key)
+			}
+			return nil
+		})
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		sample.Keys += size
+		for i, st := range stats {
+			d, err := ttls[i].Result()
+			if err != nil {
+				return err
+			}
+			sample.Sampled++
+			sample.Types[st.Type]++
+			memory += st.Memory
+			if d >= 0 {
+				sample.WithTTL++
+				ttl += d
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if sample.Sampled > 0 {
+		sample.AvgMemory = memory / int64(sample.Sampled)
+	}
+	if sample.WithTTL > 0 {
+		sample.AvgTTL = ttl / time.Duration(sample.WithTTL)
+	}
+	return sample, nil
+}
+
+// keyPattern replaces the runs of digits in the key with "*", e.g. "user:*:profile".
+func keyPattern(key string) string {
+	b := make([]byte, 0, len(key))
+	var digits bool
+	for i := 0; i < len(key); i++ {
+		if key[i] >= '0' && key[i] <= '9' {
+			if !digits {
+				b = append(b, '*')
+			}
+			digits = true
+			continue
+		}
+		digits = false
+		b = append(b, key[i])
+	}
+	return string(b)
+}
@@ -0,0 +1,73 @@
+package rediskeys
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DeleteByPatternOptions configures DeleteByPattern.
+type DeleteByPatternOptions struct {
+	// Number of keys requested by every SCAN and unlinked at once.
+	// Default is 1000.
+	BatchSize int
+
+	// Maximum number of keys unlinked per second by every server.
+	// Default is 0, which does not limit the rate.
+	MaxKeysPerSecond int
+
+	// OnProgress is called after every batch with the total number of keys
+	// deleted so far. It is called concurrently for the cluster masters
+	// and the ring shards.
+	OnProgress func(deleted int64)
+}
+
+func (opt *DeleteByPatternOptions) init() *DeleteByPatternOptions {
+	o := DeleteByPatternOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 1000
+	}
+	return &o
+}
+
+// DeleteByPattern unlinks the keys matching the pattern using SCAN and batches
+// of pipelined UNLINK commands on every cluster master or ring shard of the
+// client. It returns the number of deleted keys, which is returned together
+// with the error if the deletion is interrupted.
+func DeleteByPattern(
+	ctx context.Context, c redis.UniversalClient, pattern string, opt *DeleteByPatternOptions,
+) (int64, error) {
+	opt = opt.init()
+	var deleted int64
+	err := forEachNode(ctx, c, func(ctx context.Context, client *redis.Client) error {
+		limiter := newKeyRateLimiter(opt.MaxKeysPerSecond)
+		return scanNodeKeys(ctx, client, pattern, opt.BatchSize, func(keys []string) error {
+			// The keys are unlinked one by one, since the keys of a single
+			// UNLINK must belong to the same cluster slot.
+			cmds, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+				for _, key := range keys {
+					pipe.Unlink(ctx, key)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			var n int64
+			for _, cmd := range cmds {
+				n += cmd.(*redis.IntCmd).Val()
+			}
+			total := atomic.AddInt64(&deleted, n)
+			if opt.OnProgress != nil {
+				opt.OnProgress(total)
+			}
+			return limiter.wait(ctx, len(keys))
+		})
+	})
+	return atomic.LoadInt64(&deleted), err
+}
@@ -0,0 +1,161 @@
+package rediskeys
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MigrateKeysOptions configures MigrateKeys.
+type MigrateKeysOptions struct {
+	// Keys to migrate. If empty, the keys matching Pattern are scanned
+	// on every server of the source client.
+	Keys []string
+	// Pattern of the keys to migrate when Keys is empty. Default is "*".
+	Pattern string
+
+	// Replace overwrites the existing keys of the destination.
+	// Otherwise restoring an existing key fails with a BUSYKEY error.
+	Replace bool
+
+	// Number of keys dumped and restored at once. Default is 100.
+	BatchSize int
+
+	// Maximum number of keys migrated per second from every source server.
+	// Default is 0, which does not limit the rate.
+	MaxKeysPerSecond int
+
+	// OnProgress is called after every batch with the total number of keys
+	// migrated so far. It is called concurrently for the cluster masters
+	// and the ring shards.
+	OnProgress func(migrated int64)
+}
+
+func (opt *MigrateKeysOptions) init() *MigrateKeysOptions {
+	o := MigrateKeysOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Pattern == "" {
+		o.Pattern = "*"
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	return &o
+}
+
+// MigrateKeys copies the keys from src to dst using DUMP and RESTORE, which
+// unlike MIGRATE does not require the servers to connect to each other.
+// The keys keep their TTL. The keys that do not exist in src are skipped.
+// It returns the number of migrated keys, which is returned together with
+// the error if the migration is interrupted.
+func MigrateKeys(ctx context.Context, src, dst redis.UniversalClient, opt *MigrateKeysOptions) (int64, error) {
+	opt = opt.init()
+
+	var migrated int64
+	batch := func(src redis.Cmdable, keys []string) error {
+		n, err := migrateKeys(ctx, src, dst, keys, opt.Replace)
+		total := atomic.AddInt64(&migrated, n)
+		if err != nil {
+			return err
+		}
+		if opt.OnProgress != nil {
+			opt.OnProgress(total)
+		}
+		return nil
+	}
+
+	if len(opt.Keys) > 0 {
+		limiter := newKeyRateLimiter(opt.MaxKeysPerSecond)
+		for keys := opt.Keys; len(keys) > 0; {
+			n := opt.BatchSize
+			if n > len(keys) {
+				n = len(keys)
+			}
+			if err := batch(src, keys[:n]); err != nil {
+				return migrated, err
+			}
+			if err := limiter.wait(ctx, n); err != nil {
+				return migrated, err
+			}
+			keys = keys[n:]
+		}
+		return migrated, nil
+	}
+
+	err := forEachNode(ctx, src, func(ctx context.Context, client *redis.Client) error {
+		limiter := newKeyRateLimiter(opt.MaxKeysPerSecond)
+		return scanNodeKeys(ctx, client, opt.Pattern, opt.BatchSize, func(keys []string) error {
+			if err := batch(client, keys); err != nil {
+				return err
+			}
+			return limiter.wait(ctx, len(keys))
+		})
+	})
+	return atomic.LoadInt64(&migrated), err
+}
+
+// migrateKeys dumps the keys from src and restores them in dst
+// using a pipeline for each of them.
+func migrateKeys(ctx context.Context, src, dst redis.Cmdable, keys []string, replace bool) (int64, error) {
+	dumps := make([]*redis.StringCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	_, _ = src.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			dumps[i] = pipe.Dump(ctx, key)
+			ttls[i] = pipe.PTTL(ctx, key)
+		}
+		return nil
+	})
+
+	for i := range keys {
+		if err := dumps[i].Err(); err != nil && err != redis.Nil {
+			return 0, err
+		}
+		if err := ttls[i].Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	var restores []*redis.StatusCmd
+	_, _ = dst.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			dump, err := dumps[i].Result()
+			if err != nil {
+				// The key does not exist.
+				continue
+			}
+			ttl := ttls[i].Val()
+			if ttl == -2 {
+				// The key expired after DUMP.
+				continue
+			}
+			if ttl < 0 {
+				// The key has no TTL.
+				ttl = 0
+			}
+
+			if replace {
+				restores = append(restores, pipe.RestoreReplace(ctx, key, ttl, dump))
+			} else {
+				restores = append(restores, pipe.Restore(ctx, key, ttl, dump))
+			}
+		}
+		return nil
+	})
+
+	var n int64
+	var firstErr error
+	for _, cmd := range restores {
+		if err := cmd.Err(); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		n++
+	}
+	return n, firstErr
+}
@@ -0,0 +1,88 @@
+// Package rediskeys implements tools walking the keyspace of a server,
+// a cluster or a ring: analyzing, sampling, migrating and deleting keys.
+// The keys are scanned with SCAN on every cluster master or ring shard.
+package rediskeys
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// forEachNode calls fn for the client, or concurrently for every cluster master
+// or ring shard.
+func forEachNode(
+	ctx context.Context, c redis.UniversalClient, fn func(ctx context.Context, client *redis.Client) error,
+) error {
+	switch c := c.(type) {
+	case *redis.Client:
+		return fn(ctx, c)
+	case *redis.ClusterClient:
+		return c.ForEachMaster(ctx, fn)
+	case *redis.Ring:
+		return c.ForEachShard(ctx, fn)
+	default:
+		return fmt.Errorf("rediskeys: can't scan the keys of %T", c)
+	}
+}
+
+// scanNodeKeys calls fn for every non-empty page of the keys matching
+// the pattern on a single server.
+func scanNodeKeys(
+	ctx context.Context, c *redis.Client, pattern string, count int, fn func(keys []string) error,
+) error {
+	var cursor uint64
+	for {
+		keys, next, err := c.Scan(ctx, cursor, pattern, int64(count)).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// keyRateLimiter limits the number of keys processed per second.
+type keyRateLimiter struct {
+	rate  int
+	start time.Time
+	n     int64
+}
+
+func newKeyRateLimiter(rate int) *keyRateLimiter {
+	return &keyRateLimiter{
+		rate:  rate,
+		start: time.Now(),
+	}
+}
+
+// wait accounts n processed keys and waits until the rate allows more keys.
+func (l *keyRateLimiter) wait(ctx context.Context, n int) error {
+	if l.rate <= 0 {
+		return nil
+	}
+	l.n += int64(n)
+	wait := time.Duration(l.n)*time.Second/time.Duration(l.rate) - time.Since(l.start)
+	if wait <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
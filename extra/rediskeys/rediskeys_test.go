@@ -0,0 +1,198 @@
+package rediskeys
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/extra/redistest/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+var rdb *redis.Client
+
+func TestGinkgo(t *testing.T) {
+	rdb = redistest.NewClient(t, nil)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "rediskeys")
+}
+
+// Each spec starts with an empty server.
+var _ = BeforeEach(func() {
+	Expect(rdb.FlushAll(context.TODO()).Err()).NotTo(HaveOccurred())
+})
+
+var _ = Describe("AnalyzeKeys", func() {
+	ctx := context.TODO()
+	var policy string
+
+	BeforeEach(func() {
+		// OBJECT FREQ requires an LFU maxmemory-policy.
+		cfg, err := rdb.ConfigGet(ctx, "maxmemory-policy").Result()
+		Expect(err).NotTo(HaveOccurred())
+		policy = cfg["maxmemory-policy"]
+		Expect(rdb.ConfigSet(ctx, "maxmemory-policy", "allkeys-lfu").Err()).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(rdb.ConfigSet(ctx, "maxmemory-policy", policy).Err()).NotTo(HaveOccurred())
+	})
+
+	It("reports the largest and the hottest keys and the groups", func() {
+		Expect(rdb.Set(ctx, "user:1", "1", 0).Err()).NotTo(HaveOccurred())
+		Expect(rdb.Set(ctx, "user:2", strings.Repeat("2", 1000), 0).Err()).NotTo(HaveOccurred())
+		Expect(rdb.Set(ctx, "user:3", "3", 0).Err()).NotTo(HaveOccurred())
+		Expect(rdb.Set(ctx, "order:1", strings.Repeat("9", 10000), 0).Err()).NotTo(HaveOccurred())
+		for i := 0; i < 1000; i++ {
+			Expect(rdb.Get(ctx, "user:3").Err()).NotTo(HaveOccurred())
+		}
+
+		report, err := AnalyzeKeys(ctx, rdb, &AnalyzeKeysOptions{
+			BatchSize: 2,
+			TopN:      2,
+			Hot:       true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Keys).To(Equal(int64(4)))
+		Expect(report.Memory).To(BeNumerically(">", 11000))
+
+		Expect(report.Largest).To(HaveLen(2))
+		Expect(report.Largest[0].Key).To(Equal("order:1"))
+		Expect(report.Largest[0].Type).To(Equal("string"))
+		Expect(report.Largest[1].Key).To(Equal("user:2"))
+
+		Expect(report.Hottest).To(HaveLen(2))
+		Expect(report.Hottest[0].Key).To(Equal("user:3"))
+		Expect(report.Hottest[0].Freq).To(BeNumerically(">", report.Hottest[1].Freq))
+
+		Expect(report.Groups).To(HaveLen(2))
+		Expect(report.Groups[0].Group).To(Equal("order:*"))
+		Expect(report.Groups[0].Keys).To(Equal(int64(1)))
+		Expect(report.Groups[1].Group).To(Equal("user:*"))
+		Expect(report.Groups[1].Keys).To(Equal(int64(3)))
+	})
+
+	It("analyzes the keys matching the pattern", func() {
+		Expect(rdb.Set(ctx, "user:1", "1", 0).Err()).NotTo(HaveOccurred())
+		Expect(rdb.Set(ctx, "order:1", "1", 0).Err()).NotTo(HaveOccurred())
+
+		report, err := AnalyzeKeys(ctx, rdb, &AnalyzeKeysOptions{Pattern: "user:*"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Keys).To(Equal(int64(1)))
+		Expect(report.Hottest).To(BeEmpty())
+		Expect(report.Groups).To(HaveLen(1))
+		Expect(report.Groups[0].Group).To(Equal("user:*"))
+	})
+})
+
+var _ = Describe("SampleKeyspace", func() {
+	ctx := context.TODO()
+
+	It("estimates the keyspace from random keys", func() {
+		Expect(rdb.Set(ctx, "a", "1", time.Minute).Err()).NotTo(HaveOccurred())
+		Expect(rdb.Set(ctx, "b", strings.Repeat("2", 100), time.Minute).Err()).NotTo(HaveOccurred())
+
+		sample, err := SampleKeyspace(ctx, rdb, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sample.Keys).To(Equal(int64(2)))
+		Expect(sample.Sampled).To(Equal(10))
+		Expect(sample.Types).To(Equal(map[string]int{"string": 10}))
+		Expect(sample.WithTTL).To(Equal(10))
+		Expect(sample.TTLRatio()).To(Equal(1.0))
+		Expect(sample.AvgTTL).To(BeNumerically("~", time.Minute, time.Second))
+		Expect(sample.AvgMemory).To(BeNumerically(">", 0))
+		Expect(sample.EstimatedMemory()).To(Equal(2 * sample.AvgMemory))
+	})
+
+	It("samples an empty keyspace", func() {
+		sample, err := SampleKeyspace(ctx, rdb, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sample.Keys).To(BeZero())
+		Expect(sample.Sampled).To(BeZero())
+		Expect(sample.TTLRatio()).To(BeZero())
+	})
+})
+
+var _ = Describe("MigrateKeys", func() {
+	ctx := context.TODO()
+	var src, dst *redis.Client
+
+	BeforeEach(func() {
+		src = rdb
+		dst = redis.NewClient(&redis.Options{Addr: rdb.Options().Addr, DB: 1})
+
+		Expect(src.Set(ctx, "a", "1", 0).Err()).NotTo(HaveOccurred())
+		Expect(src.Set(ctx, "volatile", "2", time.Minute).Err()).NotTo(HaveOccurred())
+		Expect(src.RPush(ctx, "b", "3", "4").Err()).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(dst.Close()).NotTo(HaveOccurred())
+	})
+
+	It("migrates the keys with their TTL", func() {
+		var progress []int64
+		n, err := MigrateKeys(ctx, src, dst, &MigrateKeysOptions{
+			Keys:      []string{"a", "volatile", "missing"},
+			BatchSize: 2,
+			OnProgress: func(migrated int64) {
+				progress = append(progress, migrated)
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(int64(2)))
+		Expect(progress).To(Equal([]int64{2, 2}))
+
+		Expect(dst.Get(ctx, "a").Val()).To(Equal("1"))
+		Expect(dst.TTL(ctx, "a").Val()).To(Equal(time.Duration(-1)))
+		Expect(dst.Get(ctx, "volatile").Val()).To(Equal("2"))
+		Expect(dst.TTL(ctx, "volatile").Val()).To(BeNumerically("~", time.Minute, time.Second))
+		Expect(dst.Exists(ctx, "b", "missing").Val()).To(BeZero())
+	})
+
+	It("does not replace the existing keys by default", func() {
+		Expect(dst.Set(ctx, "a", "old", 0).Err()).NotTo(HaveOccurred())
+
+		n, err := MigrateKeys(ctx, src, dst, nil)
+		Expect(err).To(MatchError(ContainSubstring("BUSYKEY")))
+		Expect(n).To(Equal(int64(2)))
+		Expect(dst.Get(ctx, "a").Val()).To(Equal("old"))
+
+		n, err = MigrateKeys(ctx, src, dst, &MigrateKeysOptions{Pattern: "*", Replace: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(int64(3)))
+		Expect(dst.Get(ctx, "a").Val()).To(Equal("1"))
+		Expect(dst.LRange(ctx, "b", 0, -1).Val()).To(Equal([]string{"3", "4"}))
+	})
+})
+
+var _ = Describe("DeleteByPattern", func() {
+	ctx := context.TODO()
+
+	It("unlinks the keys matching the pattern at the limited rate", func() {
+		Expect(rdb.MSet(ctx, "k:1", "1", "k:2", "2", "k:3", "3", "other", "4").Err()).NotTo(HaveOccurred())
+
+		var progress []int64
+		start := time.Now()
+		n, err := DeleteByPattern(ctx, rdb, "k:*", &DeleteByPatternOptions{
+			BatchSize:        2,
+			MaxKeysPerSecond: 20,
+			OnProgress: func(deleted int64) {
+				progress = append(progress, deleted)
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(int64(3)))
+		Expect(progress).NotTo(BeEmpty())
+		Expect(progress[len(progress)-1]).To(Equal(int64(3)))
+		// 3 keys at 20 keys per second take at least 150ms.
+		Expect(time.Since(start)).To(BeNumerically(">=", 150*time.Millisecond))
+
+		Expect(rdb.Keys(ctx, "*").Val()).To(Equal([]string{"other"}))
+	})
+})
@@ -0,0 +1,329 @@
+// Package redisleaderboard implements leaderboards ranking the members
+// by their scores using Redis sorted sets.
+package redisleaderboard
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TieBreak orders the members of a Leaderboard with equal scores.
+type TieBreak int
+
+const (
+	// TieBreakLex orders the members with equal scores lexicographically,
+	// which is the order of the sorted set.
+	TieBreakLex TieBreak = iota
+	// TieBreakShared gives the members with equal scores the same rank,
+	// e.g. 1, 2, 2, 4.
+	TieBreakShared
+	// TieBreakEarliest ranks higher the member that reached the score first.
+	TieBreakEarliest
+	// TieBreakLatest ranks higher the member that reached the score last.
+	TieBreakLatest
+)
+
+// The members ordered by time are stored in the sorted set prefixed with
+// the 16 hex digits of the time, and the hash maps them to the stored members.
+const leaderboardTimePrefixLen = 17
+
+var (
+	// Sets or increments the score of the member stored with the time prefix,
+	// keeping the prefix if the score does not change.
+	leaderboardSubmitScript = redis.NewScript(`
+local score = tonumber(ARGV[3])
+local old = redis.call("hget", KEYS[2], ARGV[1])
+if old then
+	local old_score = tonumber(redis.call("zscore", KEYS[1], old))
+	if ARGV[4] == "1" then
+		score = old_score + score
+	end
+	if old_score == score then
+		return string.format("%.17g", score)
+	end
+	redis.call("zrem", KEYS[1], old)
+end
+redis.call("zadd", KEYS[1], score, ARGV[2])
+redis.call("hset", KEYS[2], ARGV[1], ARGV[2])
+return string.format("%.17g", score)
+`)
+
+	leaderboardRemoveScript = redis.NewScript(`
+local stored = redis.call("hget", KEYS[2], ARGV[1])
+if not stored then
+	return 0
+end
+redis.call("hdel", KEYS[2], ARGV[1])
+return redis.call("zrem", KEYS[1], stored)
+`)
+
+	// Returns the position, the score and the number of the members
+	// with better scores.
+	leaderboardRankScript = redis.NewScript(`
+local member = ARGV[1]
+if ARGV[2] == "1" then
+	member = redis.call("hget", KEYS[2], member)
+	if not member then
+		return nil
+	end
+end
+
+local pos, better
+local score = redis.call("zscore", KEYS[1], member)
+if not score then
+	return nil
+end
+if ARGV[3] == "1" then
+	pos = redis.call("zrank", KEYS[1], member)
+	better = redis.call("zcount", KEYS[1], "-inf", "(" .. score)
+else
+	pos = redis.call("zrevrank", KEYS[1], member)
+	better = redis.call("zcount", KEYS[1], "(" .. score, "+inf")
+end
+return {pos, score, better}
+`)
+)
+
+// Options configures New.
+type Options struct {
+	// Ascending ranks the lower scores higher, e.g. for the completion times.
+	// Default ranks the higher scores higher.
+	Ascending bool
+	// TieBreak orders the members with equal scores. Default is TieBreakLex.
+	TieBreak TieBreak
+}
+
+// Entry is a ranked member of a Leaderboard.
+type Entry struct {
+	Member string
+	Score  float64
+	// Rank starts with 1.
+	Rank int64
+}
+
+// Leaderboard ranks the members by their scores using a sorted set.
+// The keys of the leaderboard share the hash tag of its name,
+// so it can be used with redis.ClusterClient.
+type Leaderboard struct {
+	c   redis.Cmdable
+	opt Options
+
+	key        string
+	membersKey string
+}
+
+// New returns the leaderboard stored in the keys "leaderboard:{name}"
+// and, with TieBreakEarliest or TieBreakLatest, "leaderboard:{name}:members".
+func New(c redis.Cmdable, name string, opt *Options) *Leaderboard {
+	lb := &Leaderboard{
+		c:          c,
+		key:        "leaderboard:{" + name + "}",
+		membersKey: "leaderboard:{" + name + "}:members",
+	}
+	if opt != nil {
+		lb.opt = *opt
+	}
+	return lb
+}
+
+func (lb *Leaderboard) byTime() bool {
+	return lb.opt.TieBreak == TieBreakEarliest || lb.opt.TieBreak == TieBreakLatest
+}
+
+// storedMember prefixes the member with the current time, so that the sorted
+// set orders the members with equal scores by time.
+func (lb *Leaderboard) storedMember(member string) string {
+	ts := uint64(time.Now().UnixNano())
+	if (lb.opt.TieBreak == TieBreakEarliest) != lb.opt.Ascending {
+		ts = ^ts
+	}
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, ts)
+	return hex.EncodeToString(b) + ":" + member
+}
+
+func (lb *Leaderboard) member(stored string) string {
+	if lb.byTime() && len(stored) >= leaderboardTimePrefixLen {
+		return stored[leaderboardTimePrefixLen:]
+	}
+	return stored
+}
+
+// Submit sets the score of the member.
+func (lb *Leaderboard) Submit(ctx context.Context, member string, score float64) error {
+	if !lb.byTime() {
+		return lb.c.ZAdd(ctx, lb.key, redis.Z{Score: score, Member: member}).Err()
+	}
+	return lb.submit(ctx, member, score, false).Err()
+}
+
+// Incr increments the score of the member and returns the new score.
+func (lb *Leaderboard) Incr(ctx context.Context, member string, delta float64) (float64, error) {
+	if !lb.byTime() {
+		return lb.c.ZIncrBy(ctx, lb.key, delta, member).Result()
+	}
+	return lb.submit(ctx, member, delta, true).Float64()
+}
+
+func (lb *Leaderboard) submit(ctx context.Context, member string, score float64, incr bool) *redis.Cmd {
+	flag := "0"
+	if incr {
+		flag = "1"
+	}
+	keys := []string{lb.key, lb.membersKey}
+	return leaderboardSubmitScript.Run(ctx, lb.c, keys, member, lb.storedMember(member), score, flag)
+}
+
+// Remove removes the member from the leaderboard.
+func (lb *Leaderboard) Remove(ctx context.Context, member string) error {
+	if !lb.byTime() {
+		return lb.c.ZRem(ctx, lb.key, member).Err()
+	}
+	return leaderboardRemoveScript.Run(ctx, lb.c, []string{lb.key, lb.membersKey}, member).Err()
+}
+
+// Count returns the number of the members.
+func (lb *Leaderboard) Count(ctx context.Context) (int64, error) {
+	return lb.c.ZCard(ctx, lb.key).Result()
+}
+
+// Rank returns the entry of the member. It returns redis.Nil if the member
+// is not on the leaderboard.
+func (lb *Leaderboard) Rank(ctx context.Context, member string) (*Entry, error) {
+	entry, _, err := lb.rank(ctx, member)
+	return entry, err
+}
+
+// rank returns the entry and the position of the member.
+func (lb *Leaderboard) rank(ctx context.Context, member string) (*Entry, int64, error) {
+	byTime, asc := "0", "0"
+	if lb.byTime() {
+		byTime = "1"
+	}
+	if lb.opt.Ascending {
+		asc = "1"
+	}
+	vals, err := leaderboardRankScript.Run(
+		ctx, lb.c, []string{lb.key, lb.membersKey}, member, byTime, asc).Slice()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pos, _ := vals[0].(int64)
+	// The script returns the score as a string.
+	s, _ := vals[1].(string)
+	score, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, 0, err
+	}
+	entry := &Entry{Member: member, Score: score, Rank: pos + 1}
+	if lb.opt.TieBreak == TieBreakShared {
+		better, _ := vals[2].(int64)
+		entry.Rank = better + 1
+	}
+	return entry, pos, nil
+}
+
+// Percentile returns the percentage of the members ranked below the member,
+// e.g. 90 for the best member of 10. It returns redis.Nil if the member
+// is not on the leaderboard.
+func (lb *Leaderboard) Percentile(ctx context.Context, member string) (float64, error) {
+	entry, err := lb.Rank(ctx, member)
+	if err != nil {
+		return 0, err
+	}
+	n, err := lb.Count(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 || entry.Rank > n {
+		return 0, nil
+	}
+	return float64(n-entry.Rank) / float64(n) * 100, nil
+}
+
+// TopN returns the n best entries.
+func (lb *Leaderboard) TopN(ctx context.Context, n int64) ([]Entry, error) {
+	return lb.Range(ctx, 0, n)
+}
+
+// Range returns at most limit entries starting with the offset,
+// e.g. Range(ctx, (page-1)*size, size) returns a page.
+func (lb *Leaderboard) Range(ctx context.Context, offset, limit int64) ([]Entry, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	var zs []redis.Z
+	var err error
+	if lb.opt.Ascending {
+		zs, err = lb.c.ZRangeWithScores(ctx, lb.key, offset, offset+limit-1).Result()
+	} else {
+		zs, err = lb.c.ZRevRangeWithScores(ctx, lb.key, offset, offset+limit-1).Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(zs) == 0 {
+		return nil, nil
+	}
+
+	entries := make([]Entry, len(zs))
+	for i, z := range zs {
+		member, _ := z.Member.(string)
+		entries[i] = Entry{
+			Member: lb.member(member),
+			Score:  z.Score,
+			Rank:   offset + int64(i) + 1,
+		}
+	}
+
+	if lb.opt.TieBreak == TieBreakShared {
+		if err := lb.shareRanks(ctx, entries); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// shareRanks gives the entries with equal scores the rank of the first of them.
+func (lb *Leaderboard) shareRanks(ctx context.Context, entries []Entry) error {
+	score := strconv.FormatFloat(entries[0].Score, 'g', -1, 64)
+	var better int64
+	var err error
+	if lb.opt.Ascending {
+		better, err = lb.c.ZCount(ctx, lb.key, "-inf", "("+score).Result()
+	} else {
+		better, err = lb.c.ZCount(ctx, lb.key, "("+score, "+inf").Result()
+	}
+	if err != nil {
+		return err
+	}
+
+	entries[0].Rank = better + 1
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Score == entries[i-1].Score {
+			entries[i].Rank = entries[i-1].Rank
+		}
+	}
+	return nil
+}
+
+// Around returns the entry of the member with at most n entries
+// ranked above and below it. It returns redis.Nil if the member is not
+// on the leaderboard.
+func (lb *Leaderboard) Around(ctx context.Context, member string, n int64) ([]Entry, error) {
+	_, pos, err := lb.rank(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	offset := pos - n
+	if offset < 0 {
+		offset = 0
+	}
+	return lb.Range(ctx, offset, pos+n-offset+1)
+}
@@ -0,0 +1,163 @@
+package redisleaderboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/extra/redistest/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+var rdb *redis.Client
+
+func TestGinkgo(t *testing.T) {
+	rdb = redistest.NewClient(t, nil)
+	RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "redisleaderboard")
+}
+
+// Each spec starts with an empty server.
+var _ = ginkgo.BeforeEach(func() {
+	Expect(rdb.FlushAll(context.TODO()).Err()).NotTo(HaveOccurred())
+})
+
+var _ = ginkgo.Describe("Leaderboard", func() {
+	ctx := context.TODO()
+
+	submit := func(lb *Leaderboard, zs ...redis.Z) {
+		for _, z := range zs {
+			Expect(lb.Submit(ctx, z.Member.(string), z.Score)).NotTo(HaveOccurred())
+		}
+	}
+
+	ginkgo.It("ranks the members", func() {
+		lb := New(rdb, "game", nil)
+		submit(lb, redis.Z{Score: 50, Member: "a"}, redis.Z{Score: 40, Member: "b"}, redis.Z{Score: 30, Member: "c"})
+		Expect(rdb.ZCard(ctx, "leaderboard:{game}").Val()).To(Equal(int64(3)))
+
+		score, err := lb.Incr(ctx, "c", 15)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(score).To(Equal(float64(45)))
+
+		top, err := lb.TopN(ctx, 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(top).To(Equal([]Entry{{"a", 50, 1}, {"c", 45, 2}}))
+
+		Expect(lb.Remove(ctx, "a")).NotTo(HaveOccurred())
+		entry, err := lb.Rank(ctx, "b")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*entry).To(Equal(Entry{"b", 40, 2}))
+
+		_, err = lb.Rank(ctx, "a")
+		Expect(err).To(Equal(redis.Nil))
+		_, err = lb.Percentile(ctx, "a")
+		Expect(err).To(Equal(redis.Nil))
+	})
+
+	ginkgo.It("ranks the lower scores higher", func() {
+		lb := New(rdb, "race", &Options{Ascending: true})
+		submit(lb, redis.Z{Score: 12.5, Member: "a"}, redis.Z{Score: 10.25, Member: "b"})
+
+		top, err := lb.TopN(ctx, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(top).To(Equal([]Entry{{"b", 10.25, 1}, {"a", 12.5, 2}}))
+	})
+
+	ginkgo.It("shares the ranks of the equal scores", func() {
+		lb := New(rdb, "game", &Options{TieBreak: TieBreakShared})
+		submit(lb,
+			redis.Z{Score: 50, Member: "a"},
+			redis.Z{Score: 40, Member: "b"},
+			redis.Z{Score: 40, Member: "c"},
+			redis.Z{Score: 30, Member: "d"},
+			redis.Z{Score: 10, Member: "e"},
+		)
+
+		top, err := lb.TopN(ctx, 3)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(top).To(Equal([]Entry{{"a", 50, 1}, {"c", 40, 2}, {"b", 40, 2}}))
+
+		// The page starts with a shared rank.
+		page, err := lb.Range(ctx, 2, 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page).To(Equal([]Entry{{"b", 40, 2}, {"d", 30, 4}}))
+
+		around, err := lb.Around(ctx, "a", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(around).To(Equal([]Entry{{"a", 50, 1}, {"c", 40, 2}}))
+
+		entry, err := lb.Rank(ctx, "b")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*entry).To(Equal(Entry{"b", 40, 2}))
+
+		p, err := lb.Percentile(ctx, "a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p).To(Equal(float64(80)))
+	})
+
+	ginkgo.It("ranks the member that reached the score first higher", func() {
+		lb := New(rdb, "game", &Options{TieBreak: TieBreakEarliest})
+		submit(lb, redis.Z{Score: 40, Member: "b"}, redis.Z{Score: 40, Member: "a"}, redis.Z{Score: 10, Member: "c"})
+
+		top, err := lb.TopN(ctx, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(top).To(Equal([]Entry{{"b", 40, 1}, {"a", 40, 2}, {"c", 10, 3}}))
+
+		// The score of c reaches 40 after a and b.
+		score, err := lb.Incr(ctx, "c", 30)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(score).To(Equal(float64(40)))
+		entry, err := lb.Rank(ctx, "c")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*entry).To(Equal(Entry{"c", 40, 3}))
+
+		// Submitting the same score keeps the time it was reached.
+		submit(lb, redis.Z{Score: 40, Member: "b"})
+		around, err := lb.Around(ctx, "a", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(around).To(Equal([]Entry{{"b", 40, 1}, {"a", 40, 2}, {"c", 40, 3}}))
+
+		Expect(lb.Remove(ctx, "b")).NotTo(HaveOccurred())
+		Expect(lb.Count(ctx)).To(Equal(int64(2)))
+		Expect(rdb.HLen(ctx, "leaderboard:{game}:members").Val()).To(Equal(int64(2)))
+	})
+
+	ginkgo.It("ranks the member that reached the score last higher", func() {
+		lb := New(rdb, "game", &Options{TieBreak: TieBreakLatest})
+		submit(lb, redis.Z{Score: 40, Member: "b"}, redis.Z{Score: 40, Member: "a"})
+
+		top, err := lb.TopN(ctx, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(top).To(Equal([]Entry{{"a", 40, 1}, {"b", 40, 2}}))
+	})
+})
+
+var _ = ginkgo.Describe("storedMember", func() {
+	ginkgo.It("orders the members by time", func() {
+		for _, test := range []struct {
+			opt          Options
+			earlierFirst bool
+		}{
+			// The descending order lists the greater members first.
+			{Options{TieBreak: TieBreakEarliest}, true},
+			{Options{TieBreak: TieBreakLatest}, false},
+			{Options{TieBreak: TieBreakEarliest, Ascending: true}, true},
+			{Options{TieBreak: TieBreakLatest, Ascending: true}, false},
+		} {
+			lb := New(nil, "game", &test.opt)
+			earlier := lb.storedMember("a")
+			later := lb.storedMember("b")
+			Expect(earlier).NotTo(Equal(later))
+
+			listedFirst := earlier > later
+			if test.opt.Ascending {
+				listedFirst = earlier < later
+			}
+			Expect(listedFirst).To(Equal(test.earlierFirst), "%+v", test.opt)
+			Expect(lb.member(earlier)).To(Equal("a"))
+		}
+	})
+})
@@ -0,0 +1,402 @@
+// Package redislock implements distributed locks on a single Redis server
+// or, with the Redlock algorithm, on the majority of independent servers.
+// The locks are single keys, so they work with redis.Client, redis.Ring
+// and redis.ClusterClient.
+package redislock
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrNotObtained is returned by Obtain when the lock is held by
+	// another owner until the context is done.
+	ErrNotObtained = errors.New("redislock: lock not obtained")
+
+	// ErrNotHeld is returned when releasing or extending a lock
+	// that expired or was obtained by another owner.
+	ErrNotHeld = errors.New("redislock: lock not held")
+)
+
+var (
+	// Deletes the lock if it is still held with the token.
+	releaseLockScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+	// Sets the TTL of the lock if it is still held with the token.
+	extendLockScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0
+`)
+)
+
+// Options configures Obtain and ObtainRedlock.
+type Options struct {
+	// TTL of the lock, after which it is released if the owner disappears.
+	// Default is 10 seconds.
+	TTL time.Duration
+
+	// Minimum backoff between the attempts to obtain the lock held by another owner.
+	// Default is 8 milliseconds; -1 disables the retries, so Obtain returns
+	// ErrNotObtained after the first attempt.
+	MinRetryBackoff time.Duration
+	// Maximum backoff between the attempts to obtain the lock.
+	// Default is 512 milliseconds.
+	MaxRetryBackoff time.Duration
+
+	// AutoExtend extends the TTL of the lock every third of the TTL until
+	// the lock is released. If the lock is lost, Lock.Lost is closed.
+	AutoExtend bool
+
+	// Token identifying the owner of the lock. Default is a random token.
+	Token string
+}
+
+func (opt *Options) init() (*Options, error) {
+	o := Options{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.TTL <= 0 {
+		o.TTL = 10 * time.Second
+	}
+	switch o.MinRetryBackoff {
+	case -1:
+		o.MinRetryBackoff = 0
+	case 0:
+		o.MinRetryBackoff = 8 * time.Millisecond
+	}
+	if o.MaxRetryBackoff <= 0 {
+		o.MaxRetryBackoff = 512 * time.Millisecond
+	}
+	if o.Token == "" {
+		b := make([]byte, 16)
+		if _, err := crand.Read(b); err != nil {
+			return nil, err
+		}
+		o.Token = hex.EncodeToString(b)
+	}
+	return &o, nil
+}
+
+// Lock is a distributed lock obtained by Obtain or ObtainRedlock.
+// Lock is safe for concurrent use by multiple goroutines.
+type Lock struct {
+	clients []redis.Cmdable
+	key     string
+	token   string
+	ttl     time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	stopped  chan struct{}
+	lost     chan struct{}
+}
+
+// Obtain obtains the lock on the key using SET NX PX. If the lock is held
+// by another owner, it retries with a backoff until the context is done
+// and then returns ErrNotObtained.
+//
+//	lock, err := redislock.Obtain(ctx, rdb, "lock:report", &redislock.Options{TTL: time.Minute})
+//	if err != nil {
+//		return err
+//	}
+//	defer lock.Release(ctx)
+func Obtain(ctx context.Context, c redis.Cmdable, key string, opt *Options) (*Lock, error) {
+	return ObtainRedlock(ctx, []redis.Cmdable{c}, key, opt)
+}
+
+// ObtainRedlock obtains the lock on the key using the Redlock algorithm,
+// i.e. on the majority of the independent servers. The remaining validity of
+// the lock, its TTL minus the time spent obtaining it and a clock drift, must
+// be positive. The lock is set on the servers concurrently and the servers
+// that don't answer within a twentieth of the TTL are counted as failed.
+// See Obtain.
+func ObtainRedlock(ctx context.Context, clients []redis.Cmdable, key string, opt *Options) (*Lock, error) {
+	opt, err := opt.init()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Lock{
+		clients: clients,
+		key:     key,
+		token:   opt.Token,
+		ttl:     opt.TTL,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		lost:    make(chan struct{}),
+	}
+
+	var start time.Time
+	for attempt := 0; ; attempt++ {
+		start = time.Now()
+		ok, err := l.obtain(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			break
+		}
+
+		if opt.MinRetryBackoff == 0 {
+			return nil, ErrNotObtained
+		}
+		if err := sleep(ctx, retryBackoff(attempt, opt.MinRetryBackoff, opt.MaxRetryBackoff)); err != nil {
+			return nil, ErrNotObtained
+		}
+	}
+
+	if opt.AutoExtend {
+		go l.autoExtend(start)
+	} else {
+		close(l.stopped)
+	}
+	return l, nil
+}
+
+// obtain makes a single attempt to obtain the lock on the majority of the servers.
+func (l *Lock) obtain(ctx context.Context) (bool, error) {
+	start := time.Now()
+	obtained, failed, lastErr := l.each(ctx, func(ctx context.Context, c redis.Cmdable) (bool, error) {
+		return c.SetNX(ctx, l.key, l.token, l.ttl).Result()
+	})
+
+	// The clock drift of the Redlock algorithm.
+	drift := l.ttl/100 + 2*time.Millisecond
+	if obtained >= l.quorum() && time.Since(start)+drift < l.ttl {
+		return true, nil
+	}
+
+	if obtained > 0 {
+		// Do not leave the partially obtained lock until it expires.
+		ctx, cancel := context.WithTimeout(context.Background(), l.ttl)
+		_, _ = l.release(ctx)
+		cancel()
+	}
+	if failed == len(l.clients) {
+		return false, lastErr
+	}
+	return false, nil
+}
+
+// each runs fn on the servers concurrently and returns the number of servers
+// where fn succeeded and failed. In Redlock mode each server must answer within
+// a short timeout derived from the TTL, so an unavailable server does not
+// consume the validity of the lock.
+func (l *Lock) each(
+	ctx context.Context, fn func(ctx context.Context, c redis.Cmdable) (bool, error),
+) (ok, failed int, lastErr error) {
+	type result struct {
+		ok  bool
+		err error
+	}
+	results := make(chan result, len(l.clients))
+	for _, c := range l.clients {
+		go func(c redis.Cmdable) {
+			ctx, cancel := l.nodeContext(ctx)
+			defer cancel()
+			ok, err := fn(ctx, c)
+			results <- result{ok: ok, err: err}
+		}(c)
+	}
+
+	var timeout <-chan time.Time
+	if len(l.clients) > 1 {
+		// The timeout is not enforced by the clients without
+		// ContextTimeoutEnabled, so the slow servers are not waited for.
+		timer := time.NewTimer(l.nodeTimeout())
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for i := 0; i < len(l.clients); i++ {
+		select {
+		case res := <-results:
+			if res.err != nil {
+				failed++
+				lastErr = res.err
+			} else if res.ok {
+				ok++
+			}
+		case <-timeout:
+			failed += len(l.clients) - i
+			lastErr = context.DeadlineExceeded
+			return ok, failed, lastErr
+		}
+	}
+	return ok, failed, lastErr
+}
+
+// nodeTimeout returns the timeout of a server in Redlock mode.
+func (l *Lock) nodeTimeout() time.Duration {
+	return l.ttl / 20
+}
+
+// nodeContext returns the context of a command sent to a server.
+func (l *Lock) nodeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if len(l.clients) == 1 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, l.nodeTimeout())
+}
+
+func (l *Lock) quorum() int {
+	return len(l.clients)/2 + 1
+}
+
+// Key returns the locked key.
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Token returns the token identifying the owner of the lock.
+func (l *Lock) Token() string {
+	return l.token
+}
+
+// Lost returns a channel that is closed when the lock automatically extended
+// with Options.AutoExtend is lost, i.e. when it is not held anymore or
+// could not be extended before its TTL passed.
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// TTL returns the remaining TTL of the lock, or 0 if the lock is not held
+// by the owner anymore. For a Redlock, the TTL of the first server is returned.
+func (l *Lock) TTL(ctx context.Context) (time.Duration, error) {
+	for _, c := range l.clients {
+		token, err := c.Get(ctx, l.key).Result()
+		if err != nil && err != redis.Nil {
+			return 0, err
+		}
+		if token != l.token {
+			continue
+		}
+		ttl, err := c.PTTL(ctx, l.key).Result()
+		if err != nil || ttl < 0 {
+			return 0, err
+		}
+		return ttl, nil
+	}
+	return 0, nil
+}
+
+// Extend sets the TTL of the lock. It returns ErrNotHeld if the lock
+// is not held by the owner on the majority of the servers. Like Obtain,
+// it sets the TTL on the servers concurrently.
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	ms := ttl.Milliseconds()
+	extended, _, lastErr := l.each(ctx, func(ctx context.Context, c redis.Cmdable) (bool, error) {
+		n, err := extendLockScript.Run(ctx, c, []string{l.key}, l.token, ms).Int64()
+		return n == 1, err
+	})
+	if extended >= l.quorum() {
+		return nil
+	}
+	if lastErr != nil {
+		return lastErr
+	}
+	return ErrNotHeld
+}
+
+// Release stops the automatic extension and releases the lock. It returns
+// ErrNotHeld if the lock is not held by the owner on the majority
+// of the servers, e.g. because it expired.
+func (l *Lock) Release(ctx context.Context) error {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+	<-l.stopped
+
+	released, err := l.release(ctx)
+	if released >= l.quorum() {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return ErrNotHeld
+}
+
+// release deletes the lock on all servers and returns the number of servers
+// where it was held.
+func (l *Lock) release(ctx context.Context) (int, error) {
+	released, _, err := l.each(ctx, func(ctx context.Context, c redis.Cmdable) (bool, error) {
+		n, err := releaseLockScript.Run(ctx, c, []string{l.key}, l.token).Int64()
+		return n == 1, err
+	})
+	return released, err
+}
+
+// autoExtend extends the lock every third of its TTL until it is released or lost.
+// The lock is lost when it is not held anymore or when it could not be extended
+// for its TTL since it was obtained or last extended.
+func (l *Lock) autoExtend(validFrom time.Time) {
+	defer close(l.stopped)
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+		err := l.Extend(ctx, l.ttl)
+		cancel()
+		if err == nil {
+			validFrom = start
+			continue
+		}
+		// The errors other than ErrNotHeld are retried on the next tick
+		// while the lock is still valid.
+		if err == ErrNotHeld || time.Since(validFrom) >= l.ttl {
+			close(l.lost)
+			return
+		}
+	}
+}
+
+// retryBackoff returns a random exponential backoff between minBackoff and maxBackoff.
+func retryBackoff(attempt int, minBackoff, maxBackoff time.Duration) time.Duration {
+	d := minBackoff << uint(attempt)
+	if d < minBackoff {
+		return maxBackoff
+	}
+	d = minBackoff + time.Duration(rand.Int63n(int64(d)))
+	if d > maxBackoff || d < minBackoff {
+		d = maxBackoff
+	}
+	return d
+}
+
+func sleep(ctx context.Context, dur time.Duration) error {
+	t := time.NewTimer(dur)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,183 @@
+package redislock
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/extra/redistest/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+var rdb *redis.Client
+
+func TestGinkgo(t *testing.T) {
+	rdb = redistest.NewClient(t, nil)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "redislock")
+}
+
+// Each spec starts with an empty server.
+var _ = BeforeEach(func() {
+	Expect(rdb.FlushAll(context.TODO()).Err()).NotTo(HaveOccurred())
+})
+
+var _ = Describe("Lock", func() {
+	ctx := context.TODO()
+
+	It("obtains, extends and releases the lock", func() {
+		lock, err := Obtain(ctx, rdb, "lock", &Options{TTL: time.Second})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rdb.Get(ctx, "lock").Val()).To(Equal(lock.Token()))
+
+		// The lock is held.
+		_, err = Obtain(ctx, rdb, "lock", &Options{MinRetryBackoff: -1})
+		Expect(err).To(Equal(ErrNotObtained))
+		shortCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err = Obtain(shortCtx, rdb, "lock", nil)
+		Expect(err).To(Equal(ErrNotObtained))
+
+		Expect(lock.Extend(ctx, time.Minute)).NotTo(HaveOccurred())
+		ttl, err := lock.TTL(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ttl).To(BeNumerically("~", time.Minute, time.Second))
+
+		Expect(lock.Release(ctx)).NotTo(HaveOccurred())
+		Expect(lock.Release(ctx)).To(Equal(ErrNotHeld))
+		Expect(lock.Extend(ctx, time.Minute)).To(Equal(ErrNotHeld))
+		Expect(rdb.Exists(ctx, "lock").Val()).To(BeZero())
+
+		// The lock is released.
+		lock, err = Obtain(ctx, rdb, "lock", &Options{MinRetryBackoff: -1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lock.Release(ctx)).NotTo(HaveOccurred())
+	})
+
+	It("does not release the lock of another owner", func() {
+		lock, err := Obtain(ctx, rdb, "lock", &Options{TTL: 50 * time.Millisecond})
+		Expect(err).NotTo(HaveOccurred())
+
+		time.Sleep(100 * time.Millisecond)
+		other, err := Obtain(ctx, rdb, "lock", &Options{MinRetryBackoff: -1})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(lock.Release(ctx)).To(Equal(ErrNotHeld))
+		Expect(rdb.Get(ctx, "lock").Val()).To(Equal(other.Token()))
+		Expect(other.Release(ctx)).NotTo(HaveOccurred())
+	})
+
+	It("extends the lock automatically until it is lost", func() {
+		lock, err := Obtain(ctx, rdb, "lock", &Options{
+			TTL:        150 * time.Millisecond,
+			AutoExtend: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		time.Sleep(300 * time.Millisecond)
+		ttl, err := lock.TTL(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ttl).To(BeNumerically(">", 0))
+
+		// Another owner takes the lock.
+		Expect(rdb.Set(ctx, "lock", "other", 0).Err()).NotTo(HaveOccurred())
+		Eventually(lock.Lost(), time.Second).Should(BeClosed())
+		Expect(lock.Release(ctx)).To(Equal(ErrNotHeld))
+	})
+
+	It("loses the lock that could not be extended before it expired", func() {
+		client := redis.NewClient(&redis.Options{Addr: rdb.Options().Addr, ContextTimeoutEnabled: true})
+		defer client.Close()
+		lock, err := Obtain(ctx, client, "lock", &Options{
+			TTL:        150 * time.Millisecond,
+			AutoExtend: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// The server does not answer the extensions until the lock expired.
+		Expect(rdb.ClientPause(ctx, 500*time.Millisecond).Err()).NotTo(HaveOccurred())
+		Eventually(lock.Lost(), 400*time.Millisecond).Should(BeClosed())
+	})
+})
+
+var _ = Describe("Redlock", func() {
+	ctx := context.TODO()
+	var up []*redis.Client
+	var down []*redis.Client
+
+	BeforeEach(func() {
+		// The databases of the server stand for independent servers.
+		up = nil
+		for db := 0; db < 2; db++ {
+			up = append(up, redis.NewClient(&redis.Options{Addr: rdb.Options().Addr, DB: db}))
+		}
+		// Nothing listens on the port.
+		down = nil
+		for i := 0; i < 2; i++ {
+			down = append(down, redis.NewClient(&redis.Options{Addr: ":1", MaxRetries: -1}))
+		}
+	})
+
+	AfterEach(func() {
+		for _, client := range append(up, down...) {
+			Expect(client.Close()).NotTo(HaveOccurred())
+		}
+	})
+
+	It("obtains the lock on the majority of the servers", func() {
+		lock, err := ObtainRedlock(ctx, []redis.Cmdable{up[0], up[1], down[0]}, "lock", nil)
+		Expect(err).NotTo(HaveOccurred())
+		for _, rdb := range up {
+			Expect(rdb.Get(ctx, "lock").Val()).To(Equal(lock.Token()))
+		}
+
+		Expect(lock.Release(ctx)).NotTo(HaveOccurred())
+		for _, rdb := range up {
+			Expect(rdb.Exists(ctx, "lock").Val()).To(BeZero())
+		}
+	})
+
+	It("releases the lock obtained on the minority of the servers", func() {
+		_, err := ObtainRedlock(ctx, []redis.Cmdable{up[0], down[0], down[1]}, "lock", &Options{
+			MinRetryBackoff: -1,
+		})
+		Expect(err).To(Equal(ErrNotObtained))
+		Expect(up[0].Exists(ctx, "lock").Val()).To(BeZero())
+	})
+
+	It("does not wait for the servers that don't answer", func() {
+		// The server accepts the connections and never answers.
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer ln.Close()
+		go func() {
+			for {
+				cn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer cn.Close()
+			}
+		}()
+		hung := redis.NewClient(&redis.Options{Addr: ln.Addr().String(), MaxRetries: -1})
+		defer hung.Close()
+
+		start := time.Now()
+		lock, err := ObtainRedlock(ctx, []redis.Cmdable{up[0], hung, up[1]}, "lock", &Options{
+			TTL: time.Second,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		// The timeout of a server is 50ms.
+		Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+		Expect(up[0].Get(ctx, "lock").Val()).To(Equal(lock.Token()))
+
+		start = time.Now()
+		Expect(lock.Extend(ctx, time.Minute)).NotTo(HaveOccurred())
+		Expect(lock.Release(ctx)).NotTo(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+	})
+})
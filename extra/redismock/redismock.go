@@ -0,0 +1,389 @@
+// Package redismock mocks redis.Client for unit tests. The mocked client
+// implements redis.Cmdable, including pipelines and transactions, and never
+// connects to a server: the commands return the values of the expectations
+// set on the Mock.
+//
+//	db, mock := redismock.NewClientMock()
+//	mock.ExpectGet("key").SetVal("value")
+//
+//	val, err := db.Get(ctx, "key").Result()
+//	...
+//	if err := mock.ExpectationsWereMet(); err != nil {
+//		t.Fatal(err)
+//	}
+package redismock
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ExpectedCmd is an expected command and its result.
+type ExpectedCmd struct {
+	args []interface{}
+
+	val    interface{}
+	hasVal bool
+	err    error
+
+	triggered bool
+}
+
+// Args returns the arguments of the expected command.
+func (e *ExpectedCmd) Args() []interface{} {
+	return e.args
+}
+
+// SetVal sets the value returned by the command. The value must be
+// convertible to the type of the command value, e.g. string for Get.
+func (e *ExpectedCmd) SetVal(val interface{}) {
+	e.val = val
+	e.hasVal = true
+}
+
+// SetErr sets the error returned by the command.
+func (e *ExpectedCmd) SetErr(err error) {
+	e.err = err
+}
+
+// RedisNil makes the command return redis.Nil.
+func (e *ExpectedCmd) RedisNil() {
+	e.err = redis.Nil
+}
+
+func (e *ExpectedCmd) String() string {
+	return formatArgs(e.args)
+}
+
+func formatArgs(args []interface{}) string {
+	ss := make([]string, len(args))
+	for i, arg := range args {
+		ss[i] = fmt.Sprint(arg)
+	}
+	return strings.Join(ss, " ")
+}
+
+// Mock holds the expectations of a mocked client and records its calls.
+// Mock is safe for concurrent use by multiple goroutines.
+type Mock struct {
+	mu       sync.Mutex
+	expected []*ExpectedCmd
+	calls    [][]interface{}
+	ordered  bool
+
+	// recorder builds the arguments of the typed expectations.
+	recorder *redis.Client
+}
+
+// NewClientMock returns a client using the mock.
+func NewClientMock() (*redis.Client, *Mock) {
+	m := &Mock{
+		ordered:  true,
+		recorder: newClient(recordHook{}),
+	}
+	return newClient(m), m
+}
+
+func newClient(hook redis.Hook) *redis.Client {
+	c := redis.NewClient(&redis.Options{
+		Addr:             "redismock:6379",
+		DisableIndentity: true,
+		MaxRetries:       -1,
+	})
+	c.AddHook(hook)
+	return c
+}
+
+// MatchExpectationsInOrder sets whether the commands must be called
+// in the order of the expectations. Default is true.
+func (m *Mock) MatchExpectationsInOrder(ordered bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ordered = ordered
+}
+
+// Expect expects the command with the arguments, e.g.
+// Expect("set", "key", "value", "ex", 10).
+func (m *Mock) Expect(args ...interface{}) *ExpectedCmd {
+	e := &ExpectedCmd{args: args}
+	m.mu.Lock()
+	m.expected = append(m.expected, e)
+	m.mu.Unlock()
+	return e
+}
+
+// ExpectCmd expects the command called by fn on the client, which allows
+// the expectations of any command:
+//
+//	mock.ExpectCmd(func(c redis.Cmdable) {
+//		c.ZRangeByScore(ctx, "key", &redis.ZRangeBy{Min: "0", Max: "10"})
+//	}).SetVal([]string{"a", "b"})
+func (m *Mock) ExpectCmd(fn func(c redis.Cmdable)) *ExpectedCmd {
+	cmds, _ := m.recorder.Pipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		fn(pipe)
+		return nil
+	})
+	if len(cmds) == 0 {
+		panic("redismock: ExpectCmd called without a command")
+	}
+	return m.Expect(cmds[len(cmds)-1].Args()...)
+}
+
+// Calls returns the arguments of the commands called on the client.
+func (m *Mock) Calls() [][]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([][]interface{}(nil), m.calls...)
+}
+
+// ExpectationsWereMet returns an error if some expected commands
+// were not called.
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.expected {
+		if !e.triggered {
+			return fmt.Errorf("redismock: expected command was not called: %s", e)
+		}
+	}
+	return nil
+}
+
+// ClearExpect removes the expectations and the recorded calls.
+func (m *Mock) ClearExpect() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expected = nil
+	m.calls = nil
+}
+
+func (m *Mock) process(cmd redis.Cmder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, cmd.Args())
+
+	actual := formatArgs(cmd.Args())
+	for _, e := range m.expected {
+		if e.triggered {
+			continue
+		}
+		if e.String() != actual {
+			if m.ordered {
+				cmd.SetErr(fmt.Errorf("redismock: got command %q, expected %q", actual, e))
+				return
+			}
+			continue
+		}
+
+		e.triggered = true
+		if e.err != nil {
+			cmd.SetErr(e.err)
+			return
+		}
+		if e.hasVal {
+			if err := setVal(cmd, e.val); err != nil {
+				cmd.SetErr(err)
+			}
+		}
+		return
+	}
+	cmd.SetErr(fmt.Errorf("redismock: unexpected command %q", actual))
+}
+
+// setVal calls the SetVal method of the command.
+func setVal(cmd redis.Cmder, val interface{}) error {
+	method := reflect.ValueOf(cmd).MethodByName("SetVal")
+	if !method.IsValid() || method.Type().NumIn() != 1 {
+		return fmt.Errorf("redismock: %T does not support SetVal", cmd)
+	}
+	typ := method.Type().In(0)
+
+	v := reflect.ValueOf(val)
+	switch {
+	case val == nil:
+		v = reflect.Zero(typ)
+	case v.Type().AssignableTo(typ):
+	case v.Type().ConvertibleTo(typ):
+		v = v.Convert(typ)
+	default:
+		return fmt.Errorf("redismock: can't use %T as the value of %T", val, cmd)
+	}
+	method.Call([]reflect.Value{v})
+	return nil
+}
+
+var _ redis.Hook = (*Mock)(nil)
+
+func (m *Mock) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, fmt.Errorf("redismock: the mocked client does not connect")
+	}
+}
+
+func (m *Mock) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		m.process(cmd)
+		return cmd.Err()
+	}
+}
+
+func (m *Mock) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		var firstErr error
+		for _, cmd := range cmds {
+			if isMultiExec(cmd) {
+				continue
+			}
+			m.process(cmd)
+			if err := cmd.Err(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+}
+
+// isMultiExec reports whether the command wraps a transaction.
+func isMultiExec(cmd redis.Cmder) bool {
+	name := cmd.Name()
+	return name == "multi" || name == "exec"
+}
+
+// recordHook completes the commands without a server.
+type recordHook struct{}
+
+func (recordHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (recordHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		return nil
+	}
+}
+
+func (recordHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		return nil
+	}
+}
+
+//------------------------------------------------------------------------------
+
+var ctx = context.Background()
+
+func (m *Mock) ExpectGet(key string) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.Get(ctx, key) })
+}
+
+func (m *Mock) ExpectSet(key string, value interface{}, expiration time.Duration) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.Set(ctx, key, value, expiration) })
+}
+
+func (m *Mock) ExpectSetNX(key string, value interface{}, expiration time.Duration) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.SetNX(ctx, key, value, expiration) })
+}
+
+func (m *Mock) ExpectDel(keys ...string) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.Del(ctx, keys...) })
+}
+
+func (m *Mock) ExpectExists(keys ...string) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.Exists(ctx, keys...) })
+}
+
+func (m *Mock) ExpectExpire(key string, expiration time.Duration) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.Expire(ctx, key, expiration) })
+}
+
+func (m *Mock) ExpectTTL(key string) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.TTL(ctx, key) })
+}
+
+func (m *Mock) ExpectIncr(key string) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.Incr(ctx, key) })
+}
+
+func (m *Mock) ExpectIncrBy(key string, value int64) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.IncrBy(ctx, key, value) })
+}
+
+func (m *Mock) ExpectMGet(keys ...string) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.MGet(ctx, keys...) })
+}
+
+func (m *Mock) ExpectHGet(key, field string) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.HGet(ctx, key, field) })
+}
+
+func (m *Mock) ExpectHSet(key string, values ...interface{}) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.HSet(ctx, key, values...) })
+}
+
+func (m *Mock) ExpectHGetAll(key string) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.HGetAll(ctx, key) })
+}
+
+func (m *Mock) ExpectHDel(key string, fields ...string) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.HDel(ctx, key, fields...) })
+}
+
+func (m *Mock) ExpectLPush(key string, values ...interface{}) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.LPush(ctx, key, values...) })
+}
+
+func (m *Mock) ExpectRPush(key string, values ...interface{}) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.RPush(ctx, key, values...) })
+}
+
+func (m *Mock) ExpectLPop(key string) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.LPop(ctx, key) })
+}
+
+func (m *Mock) ExpectRPop(key string) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.RPop(ctx, key) })
+}
+
+func (m *Mock) ExpectLRange(key string, start, stop int64) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.LRange(ctx, key, start, stop) })
+}
+
+func (m *Mock) ExpectSAdd(key string, members ...interface{}) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.SAdd(ctx, key, members...) })
+}
+
+func (m *Mock) ExpectSMembers(key string) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.SMembers(ctx, key) })
+}
+
+func (m *Mock) ExpectSIsMember(key string, member interface{}) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.SIsMember(ctx, key, member) })
+}
+
+func (m *Mock) ExpectZAdd(key string, members ...redis.Z) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.ZAdd(ctx, key, members...) })
+}
+
+func (m *Mock) ExpectZRange(key string, start, stop int64) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.ZRange(ctx, key, start, stop) })
+}
+
+func (m *Mock) ExpectZScore(key, member string) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.ZScore(ctx, key, member) })
+}
+
+func (m *Mock) ExpectPublish(channel string, message interface{}) *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.Publish(ctx, channel, message) })
+}
+
+func (m *Mock) ExpectPing() *ExpectedCmd {
+	return m.ExpectCmd(func(c redis.Cmdable) { c.Ping(ctx) })
+}
@@ -0,0 +1,142 @@
+package redismock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestGinkgo(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "redismock")
+}
+
+var _ = Describe("Mock", func() {
+	ctx := context.TODO()
+	var db *redis.Client
+	var mock *Mock
+
+	BeforeEach(func() {
+		db, mock = NewClientMock()
+	})
+
+	AfterEach(func() {
+		_ = db.Close()
+	})
+
+	It("returns the expected values", func() {
+		mock.ExpectSet("key", "value", time.Minute).SetVal("OK")
+		mock.ExpectGet("key").SetVal("value")
+		mock.ExpectIncrBy("counter", 2).SetVal(2)
+		mock.ExpectHGetAll("hash").SetVal(map[string]string{"field": "value"})
+
+		Expect(db.Set(ctx, "key", "value", time.Minute).Err()).NotTo(HaveOccurred())
+		Expect(db.Get(ctx, "key").Val()).To(Equal("value"))
+		Expect(db.IncrBy(ctx, "counter", 2).Val()).To(Equal(int64(2)))
+		Expect(db.HGetAll(ctx, "hash").Val()).To(Equal(map[string]string{"field": "value"}))
+		Expect(mock.ExpectationsWereMet()).NotTo(HaveOccurred())
+	})
+
+	It("returns the expected errors", func() {
+		mock.ExpectGet("missing").RedisNil()
+		mock.Expect("del", "key").SetErr(errors.New("oops"))
+
+		Expect(db.Get(ctx, "missing").Err()).To(Equal(redis.Nil))
+		Expect(db.Del(ctx, "key").Err()).To(MatchError("oops"))
+	})
+
+	It("fails the unexpected commands", func() {
+		mock.ExpectGet("key").SetVal("value")
+
+		err := db.Get(ctx, "other").Err()
+		Expect(err).To(MatchError(`redismock: got command "get other", expected "get key"`))
+		Expect(db.Ping(ctx).Err()).To(HaveOccurred())
+		Expect(mock.ExpectationsWereMet()).To(MatchError("redismock: expected command was not called: get key"))
+	})
+
+	It("matches the expectations in any order", func() {
+		mock.MatchExpectationsInOrder(false)
+		mock.ExpectGet("a").SetVal("1")
+		mock.ExpectGet("b").SetVal("2")
+
+		Expect(db.Get(ctx, "b").Val()).To(Equal("2"))
+		Expect(db.Get(ctx, "a").Val()).To(Equal("1"))
+		Expect(mock.ExpectationsWereMet()).NotTo(HaveOccurred())
+	})
+
+	It("supports any command", func() {
+		mock.ExpectCmd(func(c redis.Cmdable) {
+			c.ZRangeByScore(ctx, "zset", &redis.ZRangeBy{Min: "0", Max: "10"})
+		}).SetVal([]string{"a", "b"})
+
+		vals, err := db.ZRangeByScore(ctx, "zset", &redis.ZRangeBy{Min: "0", Max: "10"}).Result()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vals).To(Equal([]string{"a", "b"}))
+	})
+
+	It("records the calls", func() {
+		mock.MatchExpectationsInOrder(false)
+		mock.ExpectLPush("list", "a").SetVal(1)
+
+		db.LPush(ctx, "list", "a")
+		db.Get(ctx, "key")
+
+		Expect(mock.Calls()).To(Equal([][]interface{}{
+			{"lpush", "list", "a"},
+			{"get", "key"},
+		}))
+
+		mock.ClearExpect()
+		Expect(mock.Calls()).To(BeEmpty())
+	})
+
+	It("supports pipelines", func() {
+		mock.ExpectIncr("counter").SetVal(1)
+		mock.ExpectExpire("counter", time.Hour).SetVal(true)
+
+		var incr *redis.IntCmd
+		_, err := db.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			incr = pipe.Incr(ctx, "counter")
+			pipe.Expire(ctx, "counter", time.Hour)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(incr.Val()).To(Equal(int64(1)))
+		Expect(mock.ExpectationsWereMet()).NotTo(HaveOccurred())
+	})
+
+	It("supports transactions", func() {
+		mock.ExpectSAdd("set", "a").SetVal(1)
+		mock.ExpectSMembers("set").SetVal([]string{"a"})
+
+		var members *redis.StringSliceCmd
+		_, err := db.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.SAdd(ctx, "set", "a")
+			members = pipe.SMembers(ctx, "set")
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(members.Val()).To(Equal([]string{"a"}))
+		Expect(mock.Calls()).To(HaveLen(2))
+	})
+
+	It("fails the pipelines with an unexpected command", func() {
+		_, err := db.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Get(ctx, "key")
+			return nil
+		})
+		Expect(err).To(MatchError(`redismock: unexpected command "get key"`))
+	})
+
+	It("rejects the values of the wrong type", func() {
+		mock.ExpectGet("key").SetVal(1.5)
+
+		Expect(db.Get(ctx, "key").Err()).To(MatchError("redismock: can't use float64 as the value of *redis.StringCmd"))
+	})
+})
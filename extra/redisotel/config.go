@@ -19,7 +19,8 @@ type config struct {
 	tp     trace.TracerProvider
 	tracer trace.Tracer
 
-	dbStmtEnabled bool
+	dbStmtEnabled   bool
+	dbStmtSanitized bool
 
 	// Metrics options.
 
@@ -113,6 +114,14 @@ func WithDBStatement(on bool) TracingOption {
 	})
 }
 
+// WithSanitizedDBStatement tells the tracing hook to replace the arguments
+// of the logged redis commands with "?", e.g. "set ? ?".
+func WithSanitizedDBStatement(on bool) TracingOption {
+	return tracingOption(func(conf *config) {
+		conf.dbStmtSanitized = on
+	})
+}
+
 //------------------------------------------------------------------------------
 
 type MetricsOption interface {
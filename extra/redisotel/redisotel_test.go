@@ -59,3 +59,33 @@ func TestWithDBStatement(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestWithSanitizedDBStatement(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	hook := newTracingHook(
+		"",
+		WithTracerProvider(provider),
+		WithSanitizedDBStatement(true),
+	)
+	ctx, span := provider.Tracer("redis-test").Start(context.TODO(), "redis-test")
+	cmd := redis.NewCmd(ctx, "set", "key", "secret")
+	defer span.End()
+
+	processHook := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		attrs := trace.SpanFromContext(ctx).(sdktrace.ReadOnlySpan).Attributes()
+		for _, attr := range attrs {
+			if attr.Key == semconv.DBStatementKey {
+				if got := attr.Value.AsString(); got != "set ? ?" {
+					t.Fatalf("got %q, expected sanitized db statement", got)
+				}
+				return nil
+			}
+		}
+		t.Fatal("Attribute with db statement should exist")
+		return nil
+	})
+	err := processHook(ctx, cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
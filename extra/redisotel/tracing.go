@@ -115,7 +115,12 @@ func (th *tracingHook) ProcessHook(hook redis.ProcessHook) redis.ProcessHook {
 		)
 
 		if th.conf.dbStmtEnabled {
-			cmdString := rediscmd.CmdString(cmd)
+			var cmdString string
+			if th.conf.dbStmtSanitized {
+				cmdString = rediscmd.SanitizedCmdString(cmd)
+			} else {
+				cmdString = rediscmd.CmdString(cmd)
+			}
 			attrs = append(attrs, semconv.DBStatement(cmdString))
 		}
 
@@ -147,7 +152,12 @@ func (th *tracingHook) ProcessPipelineHook(
 			attribute.Int("db.redis.num_cmd", len(cmds)),
 		)
 
-		summary, cmdsString := rediscmd.CmdsString(cmds)
+		var summary, cmdsString string
+		if th.conf.dbStmtSanitized {
+			summary, cmdsString = rediscmd.SanitizedCmdsString(cmds)
+		} else {
+			summary, cmdsString = rediscmd.CmdsString(cmds)
+		}
 		if th.conf.dbStmtEnabled {
 			attrs = append(attrs, semconv.DBStatement(cmdsString))
 		}
@@ -0,0 +1,280 @@
+// Package redisqueue implements a reliable queue of messages stored in
+// a Redis stream and shared by the consumers of a consumer group.
+package redisqueue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Options configures New.
+type Options struct {
+	// Group is the consumer group sharing the messages. Default is "queue".
+	Group string
+	// Consumer is the name of the consumer within the group.
+	// Default is the host name followed by a random suffix.
+	Consumer string
+
+	// Concurrency is the number of the messages handled at once by Consume.
+	// Default is 1.
+	Concurrency int
+
+	// VisibilityTimeout is the time after which the messages that are not
+	// acknowledged, e.g. because their handler failed or the consumer crashed,
+	// are claimed by another consumer using XAUTOCLAIM. Default is 30 seconds.
+	VisibilityTimeout time.Duration
+	// MaxRetries is the number of the retries of a message, after which
+	// it is moved to the dead-letter stream. Default is 3; -1 disables the retries.
+	MaxRetries int
+	// DeadLetterStream stores the messages exceeding MaxRetries.
+	// Default is the stream name followed by ":dead".
+	DeadLetterStream string
+
+	// BlockTimeout is the time XREADGROUP waits for new messages, which delays
+	// the shutdown of Consume. Default is 1 second.
+	BlockTimeout time.Duration
+
+	// MaxLen approximately trims the stream on Enqueue. Default is 0,
+	// which disables the trimming.
+	MaxLen int64
+}
+
+func (opt *Options) init(stream string) (*Options, error) {
+	o := Options{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Group == "" {
+		o.Group = "queue"
+	}
+	if o.Consumer == "" {
+		b := make([]byte, 4)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		host, _ := os.Hostname()
+		o.Consumer = host + "-" + hex.EncodeToString(b)
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 1
+	}
+	if o.VisibilityTimeout <= 0 {
+		o.VisibilityTimeout = 30 * time.Second
+	}
+	switch o.MaxRetries {
+	case -1:
+		o.MaxRetries = 0
+	case 0:
+		o.MaxRetries = 3
+	}
+	if o.DeadLetterStream == "" {
+		o.DeadLetterStream = stream + ":dead"
+	}
+	if o.BlockTimeout <= 0 {
+		o.BlockTimeout = time.Second
+	}
+	return &o, nil
+}
+
+// Message is a message consumed from a Queue.
+type Message struct {
+	ID     string
+	Values map[string]interface{}
+	// Deliveries is the number of times the message was delivered,
+	// i.e. 1 on the first attempt.
+	Deliveries int64
+}
+
+// Queue is a reliable queue of messages stored in a stream and shared by
+// the consumers of a consumer group. The messages are delivered at least once:
+// a message is acknowledged after its handler succeeds, and otherwise it is
+// retried after the visibility timeout until it is moved to the dead-letter stream.
+// Queue is safe for concurrent use by multiple goroutines.
+type Queue struct {
+	c      redis.Cmdable
+	stream string
+	opt    *Options
+}
+
+// New returns a Queue of the messages stored in the stream.
+func New(c redis.Cmdable, stream string, opt *Options) (*Queue, error) {
+	opt, err := opt.init(stream)
+	if err != nil {
+		return nil, err
+	}
+	return &Queue{
+		c:      c,
+		stream: stream,
+		opt:    opt,
+	}, nil
+}
+
+// Enqueue adds the message to the queue and returns its ID.
+func (q *Queue) Enqueue(ctx context.Context, values interface{}) (string, error) {
+	return q.c.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.stream,
+		MaxLen: q.opt.MaxLen,
+		Approx: q.opt.MaxLen > 0,
+		Values: values,
+	}).Result()
+}
+
+// Consume handles the messages of the queue until the context is done.
+// The message is acknowledged when the handler returns nil. On shutdown,
+// Consume stops reading the messages and waits for the running handlers.
+// It returns nil when the context is done, or the first error of Redis.
+//
+//	err := queue.Consume(ctx, func(ctx context.Context, msg *redisqueue.Message) error {
+//		return sendEmail(ctx, msg.Values["to"].(string))
+//	})
+func (q *Queue) Consume(ctx context.Context, handler func(ctx context.Context, msg *Message) error) error {
+	err := q.c.XGroupCreateMkStream(ctx, q.stream, q.opt.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	sem := make(chan struct{}, q.opt.Concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	dispatch := func(msg *Message) bool {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			// The message is claimed after the visibility timeout.
+			return false
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if handler(ctx, msg) == nil {
+				q.ack(msg.ID)
+			}
+		}()
+		return true
+	}
+
+	claimStart := "0-0"
+	var lastClaim time.Time
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= q.opt.VisibilityTimeout/2 {
+			lastClaim = time.Now()
+			msgs, next, err := q.claim(ctx, claimStart)
+			if err != nil {
+				return q.consumeErr(ctx, err)
+			}
+			claimStart = next
+			for _, msg := range msgs {
+				if !dispatch(msg) {
+					return nil
+				}
+			}
+		}
+
+		streams, err := q.c.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    q.opt.Group,
+			Consumer: q.opt.Consumer,
+			Streams:  []string{q.stream, ">"},
+			Count:    int64(q.opt.Concurrency),
+			Block:    q.opt.BlockTimeout,
+		}).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return q.consumeErr(ctx, err)
+		}
+		for _, stream := range streams {
+			for _, xmsg := range stream.Messages {
+				if !dispatch(&Message{ID: xmsg.ID, Values: xmsg.Values, Deliveries: 1}) {
+					return nil
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// consumeErr ignores the errors caused by the shutdown.
+func (q *Queue) consumeErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// claim claims the messages that were not acknowledged within the visibility
+// timeout and moves the messages exceeding the retries to the dead-letter stream.
+func (q *Queue) claim(ctx context.Context, start string) ([]*Message, string, error) {
+	xmsgs, next, err := q.c.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   q.stream,
+		Group:    q.opt.Group,
+		Consumer: q.opt.Consumer,
+		MinIdle:  q.opt.VisibilityTimeout,
+		Start:    start,
+		Count:    int64(q.opt.Concurrency),
+	}).Result()
+	if err != nil || len(xmsgs) == 0 {
+		return nil, next, err
+	}
+
+	// XAUTOCLAIM does not return the delivery counts. The range may include
+	// the messages being handled by the consumer.
+	pending, err := q.c.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream:   q.stream,
+		Group:    q.opt.Group,
+		Consumer: q.opt.Consumer,
+		Start:    xmsgs[0].ID,
+		End:      xmsgs[len(xmsgs)-1].ID,
+		Count:    int64(len(xmsgs) + q.opt.Concurrency),
+	}).Result()
+	if err != nil {
+		return nil, next, err
+	}
+	deliveries := make(map[string]int64, len(pending))
+	for _, p := range pending {
+		deliveries[p.ID] = p.RetryCount
+	}
+
+	msgs := make([]*Message, 0, len(xmsgs))
+	for _, xmsg := range xmsgs {
+		msg := &Message{ID: xmsg.ID, Values: xmsg.Values, Deliveries: deliveries[xmsg.ID]}
+		if msg.Deliveries > int64(q.opt.MaxRetries)+1 {
+			if err := q.deadLetter(ctx, msg); err != nil {
+				return nil, next, err
+			}
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, next, nil
+}
+
+// deadLetter moves the message to the dead-letter stream.
+func (q *Queue) deadLetter(ctx context.Context, msg *Message) error {
+	_, err := q.c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: q.opt.DeadLetterStream,
+			Values: msg.Values,
+		})
+		pipe.XAck(ctx, q.stream, q.opt.Group, msg.ID)
+		return nil
+	})
+	return err
+}
+
+// ack acknowledges the handled message even if Consume is shutting down.
+// If it fails, the message is delivered again.
+func (q *Queue) ack(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), q.opt.VisibilityTimeout)
+	defer cancel()
+	_ = q.c.XAck(ctx, q.stream, q.opt.Group, id).Err()
+}
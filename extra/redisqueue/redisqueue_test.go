@@ -0,0 +1,114 @@
+package redisqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/extra/redistest/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+var rdb *redis.Client
+
+func TestGinkgo(t *testing.T) {
+	rdb = redistest.NewClient(t, nil)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "redisqueue")
+}
+
+// Each spec starts with an empty server.
+var _ = BeforeEach(func() {
+	Expect(rdb.FlushAll(context.TODO()).Err()).NotTo(HaveOccurred())
+})
+
+var _ = Describe("Queue", func() {
+	ctx := context.TODO()
+
+	It("retries the failed messages and moves them to the dead-letter stream", func() {
+		queue, err := New(rdb, "jobs", &Options{
+			VisibilityTimeout: 50 * time.Millisecond,
+			MaxRetries:        1,
+			BlockTimeout:      10 * time.Millisecond,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		for _, job := range []string{"ok", "flaky", "broken"} {
+			_, err := queue.Enqueue(ctx, map[string]interface{}{"job": job})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var mu sync.Mutex
+		handled := make(map[string]int64)
+		done := make(chan error, 1)
+		go func() {
+			done <- queue.Consume(ctx, func(ctx context.Context, msg *Message) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				job := msg.Values["job"].(string)
+				handled[job] = msg.Deliveries
+				if job == "broken" || (job == "flaky" && msg.Deliveries == 1) {
+					return errors.New("failed")
+				}
+				return nil
+			})
+		}()
+
+		Eventually(func() int64 {
+			return rdb.XLen(ctx, "jobs:dead").Val()
+		}, 5*time.Second).Should(Equal(int64(1)))
+		cancel()
+		Expect(<-done).NotTo(HaveOccurred())
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(handled).To(Equal(map[string]int64{"ok": 1, "flaky": 2, "broken": 2}))
+
+		pending, err := rdb.XPending(context.Background(), "jobs", "queue").Result()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pending.Count).To(BeZero())
+
+		dead, err := rdb.XRange(context.Background(), "jobs:dead", "-", "+").Result()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dead).To(HaveLen(1))
+		Expect(dead[0].Values).To(Equal(map[string]interface{}{"job": "broken"}))
+	})
+
+	It("shares the messages between the consumers of the group", func() {
+		var ids []string
+		for _, consumer := range []string{"a", "b"} {
+			queue, err := New(rdb, "jobs", &Options{Consumer: consumer, BlockTimeout: 10 * time.Millisecond})
+			Expect(err).NotTo(HaveOccurred())
+			if consumer == "a" {
+				for i := 0; i < 10; i++ {
+					_, err := queue.Enqueue(ctx, map[string]interface{}{"i": i})
+					Expect(err).NotTo(HaveOccurred())
+				}
+			}
+
+			// Each consumer handles a message and stops.
+			ctx, cancel := context.WithCancel(ctx)
+			err = queue.Consume(ctx, func(ctx context.Context, msg *Message) error {
+				if ctx.Err() == nil {
+					ids = append(ids, msg.ID)
+				}
+				cancel()
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(ids).To(HaveLen(2))
+		Expect(ids[0]).NotTo(Equal(ids[1]))
+		consumers, err := rdb.XInfoConsumers(ctx, "jobs", "queue").Result()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(consumers).To(HaveLen(2))
+	})
+})
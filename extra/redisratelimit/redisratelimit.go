@@ -0,0 +1,260 @@
+// Package redisratelimit implements rate limiters shared by the processes
+// using the same Redis server. The limiters are implemented with Lua scripts,
+// so every decision is atomic. The limited keys are single Redis keys, so the
+// limiters work with redis.Client, redis.Ring and redis.ClusterClient.
+package redisratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "rate:"
+
+// gcra implements the generic cell rate algorithm. The key stores
+// the theoretical arrival time (TAT) of the next event in milliseconds.
+var gcra = redis.NewScript(`
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local period = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+
+local emission_interval = period / rate
+local increment = emission_interval * cost
+local burst_offset = emission_interval * burst
+
+local time = redis.call("time")
+local now = tonumber(time[1]) * 1000 + tonumber(time[2]) / 1000
+
+local tat = tonumber(redis.call("get", KEYS[1]) or now)
+tat = math.max(tat, now)
+
+local new_tat = tat + increment
+local diff = now - (new_tat - burst_offset)
+local remaining = math.floor(diff / emission_interval)
+if remaining < 0 then
+	local retry_after = math.ceil(-diff)
+	if cost > burst then
+		retry_after = -1
+	end
+	return {0, 0, retry_after, math.ceil(tat - now)}
+end
+
+local reset_after = math.ceil(new_tat - now)
+redis.call("set", KEYS[1], string.format("%.3f", new_tat), "px", reset_after)
+return {cost, remaining, -1, reset_after}
+`)
+
+// fixedWindow counts the events of the current window in the key,
+// which expires at the end of the window.
+var fixedWindow = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local count = tonumber(redis.call("get", KEYS[1]) or "0")
+local reset_after = redis.call("pttl", KEYS[1])
+local new_window = reset_after < 0
+if new_window then
+	reset_after = period
+end
+
+if count + cost > limit then
+	local retry_after = reset_after
+	if cost > limit then
+		retry_after = -1
+	end
+	return {0, math.max(limit - count, 0), retry_after, reset_after}
+end
+
+count = redis.call("incrby", KEYS[1], cost)
+if new_window then
+	redis.call("pexpire", KEYS[1], period)
+end
+return {cost, limit - count, -1, reset_after}
+`)
+
+// slidingWindow logs the times of the events of the last period
+// in the sorted set stored in the key.
+var slidingWindow = redis.NewScript(`
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local time = redis.call("time")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call("zremrangebyscore", KEYS[1], "-inf", now - period)
+local count = redis.call("zcard", KEYS[1])
+
+if count + cost > limit then
+	local retry_after = -1
+	if cost <= limit then
+		-- The events are allowed once enough of the oldest events leave the window.
+		local i = count + cost - limit - 1
+		local oldest = redis.call("zrange", KEYS[1], i, i, "withscores")
+		retry_after = math.max(tonumber(oldest[2]) + period - now, 0)
+	end
+	local reset_after = 0
+	if count > 0 then
+		local newest = redis.call("zrange", KEYS[1], -1, -1, "withscores")
+		reset_after = tonumber(newest[2]) + period - now
+	end
+	return {0, math.max(limit - count, 0), retry_after, reset_after}
+end
+
+for i = 1, cost do
+	redis.call("zadd", KEYS[1], now, ARGV[4] .. ":" .. i)
+end
+redis.call("pexpire", KEYS[1], period)
+return {cost, limit - count - cost, -1, period}
+`)
+
+// Limit is the number of events allowed per period.
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	// Burst is the number of events allowed at once by Limiter.Allow.
+	// Default is Rate.
+	Burst int
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d req/%s (burst %d)", l.Rate, l.Period, l.burst())
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Rate
+}
+
+// PerSecond returns a Limit allowing rate events per second.
+func PerSecond(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Second}
+}
+
+// PerMinute returns a Limit allowing rate events per minute.
+func PerMinute(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Minute}
+}
+
+// PerHour returns a Limit allowing rate events per hour.
+func PerHour(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Hour}
+}
+
+// Result is the decision of a Limiter.
+type Result struct {
+	// Limit is the applied limit.
+	Limit Limit
+
+	// Allowed is the number of the allowed events, either 0 or all of them.
+	Allowed int
+
+	// Remaining is the number of the events that would be allowed next.
+	Remaining int
+
+	// RetryAfter is the time until the denied events would be allowed,
+	// or -1 if they were allowed or they exceed the limit.
+	RetryAfter time.Duration
+
+	// ResetAfter is the time until the limiter returns to its initial state,
+	// e.g. for the X-RateLimit-Reset header.
+	ResetAfter time.Duration
+}
+
+// Limiter limits the rate of the events of the keys.
+type Limiter struct {
+	rdb redis.Cmdable
+}
+
+// NewLimiter returns a Limiter using the client, e.g. redis.Client,
+// redis.Ring or redis.ClusterClient.
+func NewLimiter(rdb redis.Cmdable) *Limiter {
+	return &Limiter{
+		rdb: rdb,
+	}
+}
+
+// Allow is a shortcut for AllowN(ctx, key, limit, 1).
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	return l.AllowN(ctx, key, limit, 1)
+}
+
+// AllowN reports whether n events may happen now using the generic cell rate
+// algorithm (GCRA), which spreads the events evenly over the period and allows
+// Limit.Burst events at once.
+func (l *Limiter) AllowN(ctx context.Context, key string, limit Limit, n int) (*Result, error) {
+	args := []interface{}{limit.burst(), limit.Rate, limit.Period.Milliseconds(), n}
+	return l.run(ctx, gcra, keyPrefix+"gcra:"+key, limit, args)
+}
+
+// AllowFixedWindow reports whether n events may happen now counting the events
+// of the fixed windows of Limit.Period. It is the cheapest algorithm, but allows
+// up to twice the rate around the end of a window.
+func (l *Limiter) AllowFixedWindow(ctx context.Context, key string, limit Limit, n int) (*Result, error) {
+	args := []interface{}{limit.Rate, limit.Period.Milliseconds(), n}
+	return l.run(ctx, fixedWindow, keyPrefix+"fixed:"+key, limit, args)
+}
+
+// AllowSlidingWindow reports whether n events may happen now counting the events
+// of the last Limit.Period. It is exact, but stores every event of the period.
+func (l *Limiter) AllowSlidingWindow(ctx context.Context, key string, limit Limit, n int) (*Result, error) {
+	// The events are stored as the unique members of a sorted set.
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	args := []interface{}{limit.Rate, limit.Period.Milliseconds(), n, hex.EncodeToString(b)}
+	return l.run(ctx, slidingWindow, keyPrefix+"sliding:"+key, limit, args)
+}
+
+// Reset resets the limiters of the key.
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	for _, name := range []string{"gcra:", "fixed:", "sliding:"} {
+		// The keys are deleted one by one, because they may be in different cluster slots.
+		if err := l.rdb.Del(ctx, keyPrefix+name+key).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Limiter) run(
+	ctx context.Context, script *redis.Script, key string, limit Limit, args []interface{},
+) (*Result, error) {
+	vals, err := script.Run(ctx, l.rdb, []string{key}, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) != 4 {
+		return nil, fmt.Errorf("redisratelimit: unexpected reply %v", vals)
+	}
+
+	res := &Result{
+		Limit:      limit,
+		Allowed:    int(vals[0]),
+		Remaining:  int(vals[1]),
+		RetryAfter: time.Duration(vals[2]) * time.Millisecond,
+		ResetAfter: time.Duration(vals[3]) * time.Millisecond,
+	}
+	if vals[2] < 0 {
+		res.RetryAfter = -1
+	}
+	return res, nil
+}
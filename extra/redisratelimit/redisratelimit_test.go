@@ -0,0 +1,116 @@
+package redisratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/extra/redistest/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+var rdb *redis.Client
+
+func TestGinkgo(t *testing.T) {
+	rdb = redistest.NewClient(t, nil)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "redisratelimit")
+}
+
+// Each spec starts with an empty server.
+var _ = BeforeEach(func() {
+	Expect(rdb.FlushAll(context.TODO()).Err()).NotTo(HaveOccurred())
+})
+
+var _ = Describe("Limiter", func() {
+	ctx := context.TODO()
+	var limiter *Limiter
+
+	BeforeEach(func() {
+		limiter = NewLimiter(rdb)
+	})
+
+	It("allows the burst with GCRA", func() {
+		limit := PerSecond(10)
+		for i := 0; i < 10; i++ {
+			res, err := limiter.Allow(ctx, "test", limit)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Allowed).To(Equal(1))
+			Expect(res.Remaining).To(Equal(9 - i))
+			Expect(res.RetryAfter).To(Equal(time.Duration(-1)))
+		}
+
+		res, err := limiter.Allow(ctx, "test", limit)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Allowed).To(Equal(0))
+		Expect(res.Remaining).To(Equal(0))
+		Expect(res.RetryAfter).To(BeNumerically("~", 100*time.Millisecond, 10*time.Millisecond))
+		Expect(res.ResetAfter).To(BeNumerically("~", time.Second, 10*time.Millisecond))
+	})
+
+	It("denies n events exceeding the burst with GCRA", func() {
+		res, err := limiter.AllowN(ctx, "test", PerSecond(10), 11)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Allowed).To(Equal(0))
+		Expect(res.RetryAfter).To(Equal(time.Duration(-1)))
+	})
+
+	It("counts the events of the fixed window", func() {
+		limit := PerMinute(5)
+		res, err := limiter.AllowFixedWindow(ctx, "test", limit, 3)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Allowed).To(Equal(3))
+		Expect(res.Remaining).To(Equal(2))
+		Expect(res.ResetAfter).To(Equal(time.Minute))
+
+		res, err = limiter.AllowFixedWindow(ctx, "test", limit, 3)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Allowed).To(Equal(0))
+		Expect(res.Remaining).To(Equal(2))
+		Expect(res.RetryAfter).To(BeNumerically("~", time.Minute, time.Second))
+
+		res, err = limiter.AllowFixedWindow(ctx, "test", limit, 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Allowed).To(Equal(2))
+		Expect(res.Remaining).To(Equal(0))
+
+		ttl, err := rdb.PTTL(ctx, "rate:fixed:test").Result()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ttl).To(BeNumerically("~", time.Minute, time.Second))
+	})
+
+	It("counts the events of the sliding window", func() {
+		limit := Limit{Rate: 2, Period: 200 * time.Millisecond}
+		for i := 0; i < 2; i++ {
+			res, err := limiter.AllowSlidingWindow(ctx, "test", limit, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Allowed).To(Equal(1))
+		}
+
+		res, err := limiter.AllowSlidingWindow(ctx, "test", limit, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Allowed).To(Equal(0))
+		Expect(res.RetryAfter).To(BeNumerically(">", 0))
+		Expect(res.RetryAfter).To(BeNumerically("<=", 200*time.Millisecond))
+
+		time.Sleep(res.RetryAfter + 10*time.Millisecond)
+
+		res, err = limiter.AllowSlidingWindow(ctx, "test", limit, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Allowed).To(Equal(1))
+	})
+
+	It("resets the limiters", func() {
+		_, err := limiter.AllowFixedWindow(ctx, "test", PerHour(1), 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(limiter.Reset(ctx, "test")).NotTo(HaveOccurred())
+
+		res, err := limiter.AllowFixedWindow(ctx, "test", PerHour(1), 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Allowed).To(Equal(1))
+	})
+})
@@ -0,0 +1,437 @@
+// Package redistest starts redis-server processes for the tests.
+//
+//	func TestFoo(t *testing.T) {
+//		rdb := redistest.NewClient(t, nil)
+//		...
+//	}
+//
+// The servers are stopped and the clients are closed when the test finishes.
+// redis-server is looked up in REDIS_SERVER or else in PATH. When it is not
+// found, the tests are skipped unless Options.Fallback is set.
+package redistest
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Options configures the started servers.
+type Options struct {
+	// Bin is the path of redis-server.
+	// Default is REDIS_SERVER or redis-server found in PATH.
+	Bin string
+
+	// Args are the additional configuration arguments,
+	// e.g. []string{"--appendonly", "yes"}.
+	Args []string
+
+	// TLS makes the server accept only TLS connections using a certificate
+	// generated for the test. The server must be built with TLS support.
+	TLS bool
+
+	// Fallback returns the address of a server used when redis-server is
+	// not found, e.g. the address of miniredis:
+	//
+	//	Fallback: func(tb testing.TB) string {
+	//		return miniredis.RunT(tb).Addr()
+	//	}
+	//
+	// Fallback is not used by StartCluster.
+	Fallback func(tb testing.TB) string
+
+	// StartTimeout is the time to wait for the server to accept the commands.
+	// Default is 10 seconds.
+	StartTimeout time.Duration
+}
+
+func (opt *Options) init() *Options {
+	o := Options{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Bin == "" {
+		o.Bin = os.Getenv("REDIS_SERVER")
+	}
+	if o.Bin == "" {
+		o.Bin = "redis-server"
+	}
+	if o.StartTimeout == 0 {
+		o.StartTimeout = 10 * time.Second
+	}
+	return &o
+}
+
+// Server is a started redis-server.
+type Server struct {
+	// Addr is the address of the server.
+	Addr string
+	// TLSConfig connects to the server started with Options.TLS.
+	TLSConfig *tls.Config
+
+	tb     testing.TB
+	cmd    *exec.Cmd
+	output *syncWriter
+	exited chan struct{}
+
+	stopOnce sync.Once
+	stopErr  error
+}
+
+// Start starts a server listening on a random port. The server
+// is stopped when the test finishes.
+func Start(tb testing.TB, opt *Options) *Server {
+	tb.Helper()
+	opt = opt.init()
+
+	bin, err := exec.LookPath(opt.Bin)
+	if err != nil {
+		if opt.Fallback != nil {
+			return &Server{Addr: opt.Fallback(tb), tb: tb}
+		}
+		tb.Skipf("redistest: %s not found: %s", opt.Bin, err)
+	}
+
+	var certs *testCerts
+	if opt.TLS {
+		if certs, err = newTestCerts(tb.TempDir()); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	s, err := start(tb, bin, opt, certs, opt.Args)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return s
+}
+
+func start(tb testing.TB, bin string, opt *Options, certs *testCerts, args []string) (*Server, error) {
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	dir := tb.TempDir()
+
+	s := &Server{
+		Addr:   net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+		tb:     tb,
+		output: new(syncWriter),
+		exited: make(chan struct{}),
+	}
+
+	baseArgs := []string{
+		"--bind", "127.0.0.1",
+		"--dir", dir,
+		"--save", "",
+		"--appendonly", "no",
+	}
+	if certs != nil {
+		s.TLSConfig = certs.clientConfig()
+		baseArgs = append(baseArgs, "--port", "0", "--tls-port", strconv.Itoa(port))
+		baseArgs = append(baseArgs, certs.args()...)
+	} else {
+		baseArgs = append(baseArgs, "--port", strconv.Itoa(port))
+	}
+
+	s.cmd = exec.Command(bin, append(baseArgs, args...)...)
+	s.cmd.Stdout = s.output
+	s.cmd.Stderr = s.output
+	if err := s.cmd.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		_ = s.cmd.Wait()
+		close(s.exited)
+	}()
+	tb.Cleanup(func() {
+		if err := s.Stop(); err != nil {
+			tb.Error(err)
+		}
+	})
+
+	if err := s.waitReady(opt.StartTimeout); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// waitReady waits until the server replies to PING.
+func (s *Server) waitReady(timeout time.Duration) error {
+	client := redis.NewClient(s.options())
+	defer client.Close()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		select {
+		case <-s.exited:
+			return fmt.Errorf("redistest: redis-server exited: %s", s.Output())
+		default:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err := client.Ping(ctx).Err()
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("redistest: redis-server is not ready: %w: %s", err, s.Output())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func (s *Server) options() *redis.Options {
+	return &redis.Options{
+		Addr:       s.Addr,
+		TLSConfig:  s.TLSConfig,
+		MaxRetries: -1,
+	}
+}
+
+// Client returns a client connected to the server. The client is closed
+// when the test finishes. The address and the TLS config of opt are set
+// by Client.
+func (s *Server) Client(opt *redis.Options) *redis.Client {
+	o := redis.Options{}
+	if opt != nil {
+		o = *opt
+	}
+	o.Addr = s.Addr
+	o.TLSConfig = s.TLSConfig
+
+	client := redis.NewClient(&o)
+	s.tb.Cleanup(func() {
+		_ = client.Close()
+	})
+	return client
+}
+
+// Output returns the output of redis-server.
+func (s *Server) Output() string {
+	if s.output == nil {
+		return ""
+	}
+	return s.output.String()
+}
+
+// Stop stops the server. It is called when the test finishes.
+func (s *Server) Stop() error {
+	if s.cmd == nil {
+		return nil
+	}
+	s.stopOnce.Do(func() {
+		select {
+		case <-s.exited:
+			return
+		default:
+		}
+		if err := s.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			s.stopErr = err
+			return
+		}
+		<-s.exited
+	})
+	return s.stopErr
+}
+
+// NewClient starts a server and returns a client connected to it.
+func NewClient(tb testing.TB, opt *Options) *redis.Client {
+	tb.Helper()
+	return Start(tb, opt).Client(nil)
+}
+
+// freePort returns a port that is free with the port of its cluster bus.
+func freePort() (int, error) {
+	for i := 0; i < 100; i++ {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return 0, err
+		}
+		port := ln.Addr().(*net.TCPAddr).Port
+		_ = ln.Close()
+
+		if port+10000 > 65535 {
+			continue
+		}
+		bus, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port+10000)))
+		if err != nil {
+			continue
+		}
+		_ = bus.Close()
+		return port, nil
+	}
+	return 0, errors.New("redistest: no free port")
+}
+
+// syncWriter is the output of redis-server written by the exec goroutines.
+type syncWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *syncWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(b)
+}
+
+func (w *syncWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+//------------------------------------------------------------------------------
+
+// Cluster is a started cluster of masters without replicas.
+type Cluster struct {
+	Servers []*Server
+
+	tb testing.TB
+}
+
+// StartCluster starts a cluster of n masters serving equal ranges of slots
+// and waits until the cluster is ready. The servers are stopped when
+// the test finishes.
+func StartCluster(tb testing.TB, n int, opt *Options) *Cluster {
+	tb.Helper()
+	opt = opt.init()
+
+	bin, err := exec.LookPath(opt.Bin)
+	if err != nil {
+		tb.Skipf("redistest: %s not found: %s", opt.Bin, err)
+	}
+
+	var certs *testCerts
+	if opt.TLS {
+		if certs, err = newTestCerts(tb.TempDir()); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	c := &Cluster{tb: tb}
+	for i := 0; i < n; i++ {
+		args := append([]string{
+			"--cluster-enabled", "yes",
+			"--cluster-config-file", "nodes.conf",
+			"--cluster-node-timeout", "5000",
+		}, opt.Args...)
+		if opt.TLS {
+			args = append(args, "--tls-cluster", "yes")
+		}
+		s, err := start(tb, bin, opt, certs, args)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		c.Servers = append(c.Servers, s)
+	}
+
+	if err := c.setup(opt.StartTimeout); err != nil {
+		tb.Fatal(err)
+	}
+	return c
+}
+
+// setup assigns the slots, introduces the nodes to each other and waits
+// until the cluster is ready.
+func (c *Cluster) setup(timeout time.Duration) error {
+	ctx := context.Background()
+
+	clients := make([]*redis.Client, len(c.Servers))
+	for i, s := range c.Servers {
+		clients[i] = redis.NewClient(s.options())
+		defer clients[i].Close()
+	}
+
+	const slots = 16384
+	for i, client := range clients {
+		start := i * slots / len(clients)
+		end := (i+1)*slots/len(clients) - 1
+		if err := client.ClusterAddSlotsRange(ctx, start, end).Err(); err != nil {
+			return fmt.Errorf("redistest: CLUSTER ADDSLOTSRANGE failed: %w", err)
+		}
+	}
+	first := c.Servers[0]
+	host, port, err := net.SplitHostPort(first.Addr)
+	if err != nil {
+		return err
+	}
+	for _, client := range clients[1:] {
+		if err := client.ClusterMeet(ctx, host, port).Err(); err != nil {
+			return fmt.Errorf("redistest: CLUSTER MEET failed: %w", err)
+		}
+	}
+
+	known := "cluster_known_nodes:" + strconv.Itoa(len(clients))
+	deadline := time.Now().Add(timeout)
+	for {
+		err := clusterReady(ctx, clients, known)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("redistest: cluster is not ready: %w", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func clusterReady(ctx context.Context, clients []*redis.Client, known string) error {
+	for _, client := range clients {
+		info, err := client.ClusterInfo(ctx).Result()
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(info, "cluster_state:ok") || !strings.Contains(info, known) {
+			return fmt.Errorf("%s: %s", client.Options().Addr, info)
+		}
+	}
+	return nil
+}
+
+// Addrs returns the addresses of the servers.
+func (c *Cluster) Addrs() []string {
+	addrs := make([]string, len(c.Servers))
+	for i, s := range c.Servers {
+		addrs[i] = s.Addr
+	}
+	return addrs
+}
+
+// Client returns a cluster client connected to the cluster. The client
+// is closed when the test finishes. The addresses and the TLS config of opt
+// are set by Client.
+func (c *Cluster) Client(opt *redis.ClusterOptions) *redis.ClusterClient {
+	o := redis.ClusterOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	o.Addrs = c.Addrs()
+	o.TLSConfig = c.Servers[0].TLSConfig
+
+	client := redis.NewClusterClient(&o)
+	c.tb.Cleanup(func() {
+		_ = client.Close()
+	})
+	return client
+}
+
+// NewClusterClient starts a cluster of n masters and returns a cluster
+// client connected to it.
+func NewClusterClient(tb testing.TB, n int, opt *Options) *redis.ClusterClient {
+	tb.Helper()
+	return StartCluster(tb, n, opt).Client(nil)
+}
@@ -0,0 +1,105 @@
+package redistest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"sync/atomic"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ctx = context.Background()
+
+func TestServer(t *testing.T) {
+	rdb := NewClient(t, nil)
+
+	if err := rdb.Set(ctx, "key", "value", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if val, err := rdb.Get(ctx, "key").Result(); err != nil || val != "value" {
+		t.Fatalf("got %q, %v", val, err)
+	}
+}
+
+func TestServerTLS(t *testing.T) {
+	s := Start(t, &Options{TLS: true})
+	if s.TLSConfig == nil {
+		t.Fatal("TLSConfig is not set")
+	}
+	if err := s.Client(nil).Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCluster(t *testing.T) {
+	rdb := NewClusterClient(t, 3, nil)
+
+	var masters int32
+	if err := rdb.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+		atomic.AddInt32(&masters, 1)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if masters != 3 {
+		t.Fatalf("got %d masters, wanted 3", masters)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if err := rdb.Set(ctx, key, key, 0).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFallback(t *testing.T) {
+	s := Start(t, &Options{
+		Bin: "redistest-missing-redis-server",
+		Fallback: func(tb testing.TB) string {
+			return "127.0.0.1:1234"
+		},
+	})
+	if s.Addr != "127.0.0.1:1234" {
+		t.Fatalf("got %q", s.Addr)
+	}
+	if addr := s.Client(nil).Options().Addr; addr != s.Addr {
+		t.Fatalf("got client addr %q", addr)
+	}
+	if err := s.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSkip(t *testing.T) {
+	var sub *testing.T
+	t.Run("missing", func(t *testing.T) {
+		sub = t
+		Start(t, &Options{Bin: "redistest-missing-redis-server"})
+		t.Fatal("the test is not skipped")
+	})
+	if !sub.Skipped() {
+		t.Fatal("the test is not skipped")
+	}
+}
+
+func TestCerts(t *testing.T) {
+	certs, err := newTestCerts(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tls.LoadX509KeyPair(certs.certFile, certs.keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		DNSName: "127.0.0.1",
+		Roots:   certs.pool,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,114 @@
+package redistest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// testCerts are a CA and a certificate signed by it for 127.0.0.1,
+// shared by the servers of a cluster.
+type testCerts struct {
+	caFile   string
+	certFile string
+	keyFile  string
+	pool     *x509.CertPool
+}
+
+func newTestCerts(dir string) (*testCerts, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "redistest CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	cert := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		// The certificate is also used by the cluster bus connections.
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, cert, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &testCerts{
+		caFile:   filepath.Join(dir, "ca.crt"),
+		certFile: filepath.Join(dir, "redis.crt"),
+		keyFile:  filepath.Join(dir, "redis.key"),
+		pool:     x509.NewCertPool(),
+	}
+	c.pool.AddCert(caCert)
+	for _, f := range []struct {
+		name  string
+		typ   string
+		bytes []byte
+	}{
+		{c.caFile, "CERTIFICATE", caDER},
+		{c.certFile, "CERTIFICATE", certDER},
+		{c.keyFile, "EC PRIVATE KEY", keyDER},
+	} {
+		data := pem.EncodeToMemory(&pem.Block{Type: f.typ, Bytes: f.bytes})
+		if err := os.WriteFile(f.name, data, 0o600); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// args returns the TLS configuration arguments of redis-server.
+func (c *testCerts) args() []string {
+	return []string{
+		"--tls-cert-file", c.certFile,
+		"--tls-key-file", c.keyFile,
+		"--tls-ca-cert-file", c.caFile,
+		"--tls-auth-clients", "no",
+	}
+}
+
+func (c *testCerts) clientConfig() *tls.Config {
+	return &tls.Config{
+		RootCAs:    c.pool,
+		ServerName: "localhost",
+		MinVersion: tls.VersionTLS12,
+	}
+}
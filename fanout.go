@@ -0,0 +1,223 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9/internal/hashtag"
+)
+
+// fanOut calls fn for each of the n tasks in its own goroutine with at most
+// limit goroutines running at once, or without a limit if limit <= 0.
+// It waits for all the tasks to finish.
+func fanOut(limit, n int, fn func(i int)) {
+	if n == 1 {
+		fn(0)
+		return
+	}
+
+	var sem chan struct{}
+	if limit > 0 && limit < n {
+		sem = make(chan struct{}, limit)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		if sem != nil {
+			sem <- struct{}{}
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fn(i)
+			if sem != nil {
+				<-sem
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// NodeError is the error returned by a cluster node.
+type NodeError struct {
+	Addr string
+	Err  error
+}
+
+func (e *NodeError) Error() string {
+	return e.Addr + ": " + e.Err.Error()
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+// NodeErrors is returned by ClusterClient.ForEachMaster, ForEachSlave and
+// ForEachShard with ClusterOptions.ReportAllNodeErrors when fn failed on more
+// than one node. errors.Is and errors.As match any of the errors. A single
+// failure is returned as is.
+type NodeErrors []*NodeError
+
+func (e NodeErrors) Error() string {
+	var b strings.Builder
+	b.WriteString("redis: ")
+	b.WriteString(strconv.Itoa(len(e)))
+	b.WriteString(" nodes failed: ")
+	for i, err := range e {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors of the nodes.
+func (e NodeErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+func (e NodeErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e NodeErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// forEachNode calls fn on the nodes concurrently, at most
+// ClusterOptions.FanOutParallelism at once. It returns the first error,
+// or NodeErrors with ClusterOptions.ReportAllNodeErrors.
+func (c *ClusterClient) forEachNode(
+	ctx context.Context,
+	nodes []*clusterNode,
+	fn func(ctx context.Context, client *Client) error,
+) error {
+	errs := make([]error, len(nodes))
+	first := int32(-1)
+	fanOut(c.opt.FanOutParallelism, len(nodes), func(i int) {
+		errs[i] = fn(ctx, nodes[i].Client)
+		if errs[i] != nil {
+			atomic.CompareAndSwapInt32(&first, -1, int32(i))
+		}
+	})
+
+	if first < 0 {
+		return nil
+	}
+	if !c.opt.ReportAllNodeErrors {
+		return errs[first]
+	}
+
+	var nodeErrs NodeErrors
+	for i, err := range errs {
+		if err != nil {
+			nodeErrs = append(nodeErrs, &NodeError{Addr: nodes[i].Client.opt.Addr, Err: err})
+		}
+	}
+	if len(nodeErrs) == 1 {
+		return nodeErrs[0].Err
+	}
+	return nodeErrs
+}
+
+// processCrossSlot executes MGET with keys in several slots as a pipeline
+// of a MGET per slot, so the nodes are queried concurrently. Only the read-only
+// MGET is split: MSET, DEL and the other writes of keys in several slots still
+// fail with CROSSSLOT, because split they would not be atomic.
+// It returns false if cmd is not split.
+func (c *ClusterClient) processCrossSlot(ctx context.Context, cmd Cmder) (bool, error) {
+	slotCmds, keyPos := splitCrossSlotCmd(ctx, cmd)
+	if slotCmds == nil {
+		return false, nil
+	}
+	_ = c.processPipeline(ctx, slotCmds)
+	return true, mergeCrossSlotCmd(cmd, slotCmds, keyPos)
+}
+
+// splitCrossSlotCmd returns the MGET commands executing cmd per slot and
+// the positions of their keys in cmd, or nil if the keys are in one slot
+// or cmd is not MGET.
+func splitCrossSlotCmd(ctx context.Context, cmd Cmder) ([]Cmder, [][]int) {
+	if _, ok := cmd.(*SliceCmd); !ok || cmd.Name() != "mget" {
+		return nil, nil
+	}
+
+	args := cmd.Args()
+	if len(args) < 3 {
+		return nil, nil
+	}
+
+	firstSlot := hashtag.Slot(cmd.stringArg(1))
+	crossSlot := false
+	for i := 2; i < len(args); i++ {
+		if hashtag.Slot(cmd.stringArg(i)) != firstSlot {
+			crossSlot = true
+			break
+		}
+	}
+	if !crossSlot {
+		return nil, nil
+	}
+
+	slotIndex := make(map[int]int)
+	var slotArgs [][]interface{}
+	var keyPos [][]int
+	for i := 1; i < len(args); i++ {
+		slot := hashtag.Slot(cmd.stringArg(i))
+		j, ok := slotIndex[slot]
+		if !ok {
+			j = len(slotArgs)
+			slotIndex[slot] = j
+			slotArgs = append(slotArgs, []interface{}{args[0]})
+			keyPos = append(keyPos, nil)
+		}
+		slotArgs[j] = append(slotArgs[j], args[i])
+		keyPos[j] = append(keyPos[j], i-1)
+	}
+
+	slotCmds := make([]Cmder, len(slotArgs))
+	for j, args := range slotArgs {
+		slotCmds[j] = NewSliceCmd(ctx, args...)
+	}
+	return slotCmds, keyPos
+}
+
+// mergeCrossSlotCmd sets the value of cmd to the merged values of the
+// commands of the slots, or returns their first error.
+func mergeCrossSlotCmd(cmd Cmder, slotCmds []Cmder, keyPos [][]int) error {
+	if err := cmdsFirstErr(slotCmds); err != nil {
+		return err
+	}
+
+	var n int
+	for _, pos := range keyPos {
+		n += len(pos)
+	}
+	vals := make([]interface{}, n)
+	for j, slotCmd := range slotCmds {
+		for k, val := range slotCmd.(*SliceCmd).Val() {
+			vals[keyPos[j][k]] = val
+		}
+	}
+	cmd.(*SliceCmd).SetVal(vals)
+	return nil
+}
@@ -0,0 +1,129 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFanOutLimit(t *testing.T) {
+	var running, maxRunning, calls int32
+	fanOut(3, 20, func(i int) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&calls, 1)
+	})
+
+	if calls != 20 {
+		t.Fatalf("got %d calls, wanted 20", calls)
+	}
+	if maxRunning > 3 {
+		t.Fatalf("got %d tasks running at once, wanted at most 3", maxRunning)
+	}
+}
+
+func TestNodeErrors(t *testing.T) {
+	errA := errors.New("a failed")
+	err := error(NodeErrors{
+		{Addr: ":7000", Err: errA},
+		{Addr: ":7001", Err: context.DeadlineExceeded},
+	})
+
+	if got, want := err.Error(), "redis: 2 nodes failed: :7000: a failed; :7001: context deadline exceeded"; got != want {
+		t.Fatalf("got %q, wanted %q", got, want)
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("errors.Is does not match the node errors")
+	}
+	var nodeErr *NodeError
+	if !errors.As(err, &nodeErr) || nodeErr.Addr != ":7000" {
+		t.Fatalf("got %v, wanted the error of :7000", nodeErr)
+	}
+}
+
+func TestForEachNodeErrors(t *testing.T) {
+	var nodes []*clusterNode
+	for _, addr := range []string{":7000", ":7001", ":7002"} {
+		client := NewClient(&Options{Addr: addr})
+		defer client.Close()
+		nodes = append(nodes, &clusterNode{Client: client})
+	}
+	errA := errors.New("a failed")
+	fn := func(ctx context.Context, client *Client) error {
+		if client.opt.Addr == ":7001" {
+			// Fail after the other node, so its error is the first.
+			time.Sleep(10 * time.Millisecond)
+			return context.DeadlineExceeded
+		}
+		if client.opt.Addr == ":7002" {
+			return errA
+		}
+		return nil
+	}
+
+	c := &ClusterClient{opt: &ClusterOptions{}}
+	if err := c.forEachNode(ctx, nodes, fn); err != errA {
+		t.Fatalf("got %v, wanted the first error %v", err, errA)
+	}
+
+	c.opt.ReportAllNodeErrors = true
+	err := c.forEachNode(ctx, nodes, fn)
+	nodeErrs, ok := err.(NodeErrors)
+	if !ok || len(nodeErrs) != 2 || nodeErrs[0].Addr != ":7001" || nodeErrs[1].Addr != ":7002" {
+		t.Fatalf("got %v, wanted the errors of :7001 and :7002", err)
+	}
+	if unwrapped := nodeErrs.Unwrap(); len(unwrapped) != 2 || !errors.Is(unwrapped[1], errA) {
+		t.Fatalf("got %v, wanted the node errors", unwrapped)
+	}
+}
+
+func TestSplitCrossSlotCmd(t *testing.T) {
+	// The keys a and b are in different slots, {a}1 is in the slot of a.
+	if cmds, _ := splitCrossSlotCmd(ctx, NewSliceCmd(ctx, "mget", "a", "{a}1")); cmds != nil {
+		t.Fatalf("got %v, wanted the keys of one slot not split", cmds)
+	}
+
+	cmd := NewSliceCmd(ctx, "mget", "a", "b", "{a}1")
+	cmds, keyPos := splitCrossSlotCmd(ctx, cmd)
+	if len(cmds) != 2 {
+		t.Fatalf("got %d commands, wanted 2", len(cmds))
+	}
+	if got, want := cmds[0].Args(), []interface{}{"mget", "a", "{a}1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, wanted %v", got, want)
+	}
+	if got, want := keyPos, [][]int{{0, 2}, {1}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, wanted %v", got, want)
+	}
+
+	cmds[0].(*SliceCmd).SetVal([]interface{}{"1", nil})
+	cmds[1].(*SliceCmd).SetVal([]interface{}{"2"})
+	if err := mergeCrossSlotCmd(cmd, cmds, keyPos); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cmd.Val(), []interface{}{"1", "2", nil}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, wanted %v", got, want)
+	}
+
+	// The writes are not split, so they stay atomic.
+	if cmds, _ := splitCrossSlotCmd(ctx, NewStatusCmd(ctx, "mset", "a", "1", "b", "2")); cmds != nil {
+		t.Fatalf("got %v, wanted MSET not split", cmds)
+	}
+	if cmds, _ := splitCrossSlotCmd(ctx, NewIntCmd(ctx, "del", "a", "b")); cmds != nil {
+		t.Fatalf("got %v, wanted DEL not split", cmds)
+	}
+
+	cmds[1].SetErr(errors.New("ERR failed"))
+	if err := mergeCrossSlotCmd(cmd, cmds, keyPos); err == nil || err.Error() != "ERR failed" {
+		t.Fatalf("got %v, wanted the error of the slot", err)
+	}
+}
@@ -0,0 +1,251 @@
+package redis
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal"
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// NewRedisError returns an error that is handled like the error replies of
+// the server, e.g. NewRedisError("LOADING Redis is loading the dataset in memory")
+// or NewRedisError("MOVED 3999 127.0.0.1:6381").
+func NewRedisError(msg string) Error {
+	return proto.RedisError(msg)
+}
+
+// ErrInjectedTimeout is a network timeout injected by a FaultInjector.
+var ErrInjectedTimeout net.Error = injectedTimeoutError{}
+
+type injectedTimeoutError struct{}
+
+func (injectedTimeoutError) Error() string   { return "redis: injected i/o timeout" }
+func (injectedTimeoutError) Timeout() bool   { return true }
+func (injectedTimeoutError) Temporary() bool { return true }
+
+// FaultRule describes the faults injected in the matching commands.
+type FaultRule struct {
+	// Commands are the lowercase names of the matching commands,
+	// e.g. "get". Default matches all the commands.
+	Commands []string
+
+	// Probability of the injection in a matching command, between 0 and 1.
+	// Default is 1, which injects the faults in every matching command.
+	Probability float64
+
+	// Times is the maximum number of the injections. Default is 0,
+	// which injects the faults until the rule is removed by Reset.
+	Times int
+
+	// Latency delays the command.
+	Latency time.Duration
+	// Drop closes the connections of the client before the command is sent,
+	// as if the server was restarted.
+	Drop bool
+	// Err is returned instead of sending the command, e.g. ErrInjectedTimeout
+	// or an error returned by NewRedisError.
+	Err error
+}
+
+func (r *FaultRule) matches(name string) bool {
+	if len(r.Commands) == 0 {
+		return true
+	}
+	for _, cmd := range r.Commands {
+		if cmd == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FaultInjector is a hook injecting latency, dropped connections and errors
+// in the commands, so the code handling the failures can be tested without
+// a faulty server. The faults are chosen by a random source seeded by the
+// seed, which makes the tests deterministic as long as the commands are
+// processed in the same order.
+//
+//	faults := redis.NewFaultInjector(1)
+//	faults.Add(redis.FaultRule{
+//		Commands:    []string{"get"},
+//		Probability: 0.1,
+//		Err:         redis.NewRedisError("LOADING Redis is loading the dataset in memory"),
+//	})
+//	rdb.AddHook(faults)
+//
+// With ClusterClient, add the hook to the node clients using OnNewNode, so
+// the injected MOVED and ASK errors are followed like the real redirections.
+// FaultInjector is safe for concurrent use by multiple goroutines.
+type FaultInjector struct {
+	mu       sync.Mutex
+	rand     *rand.Rand
+	rules    []*faultRule
+	conns    map[*faultConn]struct{}
+	injected int
+}
+
+type faultRule struct {
+	FaultRule
+	injected int
+}
+
+var _ Hook = (*FaultInjector)(nil)
+
+// NewFaultInjector returns a FaultInjector without rules
+// using the seed for the random source.
+func NewFaultInjector(seed int64) *FaultInjector {
+	return &FaultInjector{
+		rand:  rand.New(rand.NewSource(seed)),
+		conns: make(map[*faultConn]struct{}),
+	}
+}
+
+// Add adds the rule. All the rules matching a command are applied.
+func (f *FaultInjector) Add(rule FaultRule) {
+	if rule.Probability <= 0 {
+		rule.Probability = 1
+	}
+	f.mu.Lock()
+	f.rules = append(f.rules, &faultRule{FaultRule: rule})
+	f.mu.Unlock()
+}
+
+// Reset removes the rules.
+func (f *FaultInjector) Reset() {
+	f.mu.Lock()
+	f.rules = nil
+	f.mu.Unlock()
+}
+
+// Injected returns the number of the commands with injected faults.
+func (f *FaultInjector) Injected() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.injected
+}
+
+// fault is the combination of the rules applied to the commands.
+type fault struct {
+	latency time.Duration
+	drop    bool
+	err     error
+}
+
+func (f *FaultInjector) fault(cmds []Cmder) (flt fault, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, cmd := range cmds {
+		name := cmd.Name()
+		for _, rule := range f.rules {
+			if rule.Times > 0 && rule.injected >= rule.Times {
+				continue
+			}
+			if !rule.matches(name) {
+				continue
+			}
+			if rule.Probability < 1 && f.rand.Float64() >= rule.Probability {
+				continue
+			}
+
+			rule.injected++
+			ok = true
+			if rule.Latency > flt.latency {
+				flt.latency = rule.Latency
+			}
+			if rule.Drop {
+				flt.drop = true
+			}
+			if rule.Err != nil && flt.err == nil {
+				flt.err = rule.Err
+			}
+		}
+	}
+	if ok {
+		f.injected++
+	}
+	return flt, ok
+}
+
+// inject applies the fault and returns the injected error.
+func (f *FaultInjector) inject(ctx context.Context, flt fault) error {
+	if flt.latency > 0 {
+		if err := internal.Sleep(ctx, flt.latency); err != nil {
+			return err
+		}
+	}
+	if flt.drop {
+		f.dropConns()
+	}
+	return flt.err
+}
+
+func (f *FaultInjector) dropConns() {
+	f.mu.Lock()
+	conns := make([]*faultConn, 0, len(f.conns))
+	for cn := range f.conns {
+		conns = append(conns, cn)
+	}
+	f.mu.Unlock()
+
+	for _, cn := range conns {
+		_ = cn.Close()
+	}
+}
+
+func (f *FaultInjector) DialHook(next DialHook) DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		cn := &faultConn{Conn: conn, f: f}
+		f.mu.Lock()
+		f.conns[cn] = struct{}{}
+		f.mu.Unlock()
+		return cn, nil
+	}
+}
+
+func (f *FaultInjector) ProcessHook(next ProcessHook) ProcessHook {
+	return func(ctx context.Context, cmd Cmder) error {
+		if flt, ok := f.fault([]Cmder{cmd}); ok {
+			if err := f.inject(ctx, flt); err != nil {
+				cmd.SetErr(err)
+				return err
+			}
+		}
+		return next(ctx, cmd)
+	}
+}
+
+// ProcessPipelineHook injects the faults of the pipelined commands in the
+// whole pipeline, e.g. an error fails all the commands.
+func (f *FaultInjector) ProcessPipelineHook(next ProcessPipelineHook) ProcessPipelineHook {
+	return func(ctx context.Context, cmds []Cmder) error {
+		if flt, ok := f.fault(cmds); ok {
+			if err := f.inject(ctx, flt); err != nil {
+				setCmdsErr(cmds, err)
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+// faultConn is a connection that can be dropped by the FaultInjector.
+type faultConn struct {
+	net.Conn
+	f *FaultInjector
+}
+
+func (cn *faultConn) Close() error {
+	cn.f.mu.Lock()
+	delete(cn.f.conns, cn)
+	cn.f.mu.Unlock()
+	return cn.Conn.Close()
+}
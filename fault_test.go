@@ -0,0 +1,143 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFaultInjectorErrors(t *testing.T) {
+	client := newKVServer(map[string]string{"a": "1"}).client()
+	defer client.Close()
+
+	faults := NewFaultInjector(1)
+	faults.Add(FaultRule{
+		Commands: []string{"get"},
+		Times:    1,
+		Err:      NewRedisError("LOADING Redis is loading the dataset in memory"),
+	})
+	faults.Add(FaultRule{
+		Commands: []string{"mget"},
+		Err:      NewRedisError("MOVED 3999 127.0.0.1:6381"),
+	})
+	client.AddHook(faults)
+
+	if err := client.Get(ctx, "a").Err(); !IsLoading(err) {
+		t.Fatalf("got %v, wanted LOADING", err)
+	}
+	if val, err := client.Get(ctx, "a").Result(); err != nil || val != "1" {
+		t.Fatalf("got %q, %v", val, err)
+	}
+
+	err := client.MGet(ctx, "a").Err()
+	if addr, slot, ok := IsMoved(err); !ok || addr != "127.0.0.1:6381" || slot != 3999 {
+		t.Fatalf("got %v, wanted MOVED", err)
+	}
+	if n := faults.Injected(); n != 2 {
+		t.Fatalf("got %d injections, wanted 2", n)
+	}
+
+	faults.Reset()
+	if err := client.MGet(ctx, "a").Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFaultInjectorDeterministic(t *testing.T) {
+	run := func() []bool {
+		client := newKVServer(map[string]string{"a": "1"}).client()
+		defer client.Close()
+
+		faults := NewFaultInjector(42)
+		faults.Add(FaultRule{Probability: 0.5, Err: ErrInjectedTimeout})
+		client.AddHook(faults)
+
+		var failed []bool
+		for i := 0; i < 20; i++ {
+			err := client.Get(ctx, "a").Err()
+			if err != nil && !errors.Is(err, ErrInjectedTimeout) {
+				t.Fatal(err)
+			}
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+
+	first := run()
+	if !reflect.DeepEqual(first, run()) {
+		t.Fatal("the injected faults are not deterministic")
+	}
+	var n int
+	for _, failed := range first {
+		if failed {
+			n++
+		}
+	}
+	if n == 0 || n == len(first) {
+		t.Fatalf("got %d failures out of %d", n, len(first))
+	}
+}
+
+func TestFaultInjectorPipeline(t *testing.T) {
+	client := newKVServer(map[string]string{"a": "1"}).client()
+	defer client.Close()
+
+	faults := NewFaultInjector(1)
+	faults.Add(FaultRule{Commands: []string{"incrby"}, Err: ErrInjectedTimeout})
+	client.AddHook(faults)
+
+	cmds, err := client.Pipelined(ctx, func(pipe Pipeliner) error {
+		pipe.Get(ctx, "a")
+		pipe.IncrBy(ctx, "b", 1)
+		return nil
+	})
+	if err != ErrInjectedTimeout {
+		t.Fatalf("got %v", err)
+	}
+	for _, cmd := range cmds {
+		if cmd.Err() != ErrInjectedTimeout {
+			t.Fatalf("got %v for %s", cmd.Err(), cmd.Name())
+		}
+	}
+}
+
+func TestFaultInjectorLatency(t *testing.T) {
+	client := newKVServer(map[string]string{"a": "1"}).client()
+	defer client.Close()
+
+	faults := NewFaultInjector(1)
+	faults.Add(FaultRule{Latency: time.Minute})
+	client.AddHook(faults)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := client.Get(ctx, "a").Err(); err != context.DeadlineExceeded {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestFaultInjectorDrop(t *testing.T) {
+	client := newKVServer(map[string]string{"a": "1"}).client()
+	defer client.Close()
+
+	faults := NewFaultInjector(1)
+	client.AddHook(faults)
+
+	if err := client.Get(ctx, "a").Err(); err != nil {
+		t.Fatal(err)
+	}
+	faults.Add(FaultRule{Commands: []string{"get"}, Times: 1, Drop: true})
+
+	// The dropped connection fails the command and is replaced by a new one.
+	if err := client.Get(ctx, "a").Err(); err == nil {
+		t.Fatal("the connection is not dropped")
+	}
+	if val, err := client.Get(ctx, "a").Result(); err != nil || val != "1" {
+		t.Fatalf("got %q, %v", val, err)
+	}
+	if stats := client.PoolStats(); stats.Misses != 2 || stats.TotalConns != 1 {
+		t.Fatalf("got %+v", stats)
+	}
+}
@@ -12,6 +12,7 @@ type HashCmdable interface {
 	HGetAll(ctx context.Context, key string) *MapStringStringCmd
 	HIncrBy(ctx context.Context, key, field string, incr int64) *IntCmd
 	HIncrByFloat(ctx context.Context, key, field string, incr float64) *FloatCmd
+	HIncrByDecimal(ctx context.Context, key, field string, incr string) *StringCmd
 	HKeys(ctx context.Context, key string) *StringSliceCmd
 	HLen(ctx context.Context, key string) *IntCmd
 	HMGet(ctx context.Context, key string, fields ...string) *SliceCmd
@@ -80,6 +81,15 @@ func (c cmdable) HIncrByFloat(ctx context.Context, key, field string, incr float
 	return cmd
 }
 
+// HIncrByDecimal is like HIncrByFloat, but the increment and the reply are
+// decimal strings, so they are not rounded to float64.
+// See StringCmd.BigFloat to parse the reply.
+func (c cmdable) HIncrByDecimal(ctx context.Context, key, field string, incr string) *StringCmd {
+	cmd := NewStringCmd(ctx, "hincrbyfloat", key, field, incr)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
 func (c cmdable) HKeys(ctx context.Context, key string) *StringSliceCmd {
 	cmd := NewStringSliceCmd(ctx, "hkeys", key)
 	_ = c(ctx, cmd)
@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal/rand"
+)
+
+// hedgedProcess processes the read-only cmd on the node. If the node does not
+// reply within HedgeDelay, a copy of the cmd is sent to another node serving
+// the slot and the reply that arrives first is stored in the cmd.
+func (c *ClusterClient) hedgedProcess(ctx context.Context, node *clusterNode, slot int, cmd Cmder) error {
+	state, err := c.state.Get(ctx)
+	if err != nil {
+		return node.Client.Process(ctx, cmd)
+	}
+	hedge := hedgeNode(state.slotNodes(slot), node)
+	if hedge == nil {
+		return node.Client.Process(ctx, cmd)
+	}
+
+	type result struct {
+		cmd Cmder
+		err error
+	}
+	// Both requests use a copy of the cmd, because the slower one
+	// may still be writing its reply after the faster one returned.
+	ch := make(chan result, 2)
+	process := func(node *clusterNode) {
+		cmd := cloneCmd(cmd)
+		ch <- result{cmd: cmd, err: node.Client.Process(ctx, cmd)}
+	}
+	go process(node)
+
+	timer := time.NewTimer(c.opt.HedgeDelay)
+	defer timer.Stop()
+
+	var res result
+	select {
+	case res = <-ch:
+	case <-timer.C:
+		go process(hedge)
+		res = <-ch
+		// Prefer the other reply to a network error.
+		if res.err != nil && !isRedisError(res.err) {
+			res = <-ch
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	reflect.ValueOf(cmd).Elem().Set(reflect.ValueOf(res.cmd).Elem())
+	return res.err
+}
+
+// hedgeNode returns a random node other than the node that is not failing.
+func hedgeNode(nodes []*clusterNode, node *clusterNode) *clusterNode {
+	for _, i := range rand.Perm(len(nodes)) {
+		if n := nodes[i]; n != node && !n.Failing() {
+			return n
+		}
+	}
+	return nil
+}
+
+// cloneCmd returns a shallow copy of the cmd. The copy shares the arguments,
+// but reads the reply into its own value.
+func cloneCmd(cmd Cmder) Cmder {
+	v := reflect.ValueOf(cmd).Elem()
+	clone := reflect.New(v.Type())
+	clone.Elem().Set(v)
+	return clone.Interface().(Cmder)
+}
@@ -0,0 +1,100 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// hedgeServer replies to GET with the addr after the delay.
+func hedgeServer(addr string, delay time.Duration, gets *int32) net.Conn {
+	cn, server := net.Pipe()
+	go func() {
+		rd := proto.NewReader(server)
+		for {
+			v, err := rd.ReadReply()
+			if err != nil {
+				return
+			}
+			args, _ := v.([]interface{})
+			if len(args) == 0 {
+				return
+			}
+			switch args[0] {
+			case "hello":
+				_, err = server.Write(initHello)
+			case "get":
+				atomic.AddInt32(gets, 1)
+				time.Sleep(delay)
+				_, err = fmt.Fprintf(server, "$%d\r\n%s\r\n", len(addr), addr)
+			default:
+				_, err = server.Write([]byte("+OK\r\n"))
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return cn
+}
+
+func TestClusterHedgedReads(t *testing.T) {
+	var gets int32
+	delays := map[string]time.Duration{
+		"slow:6379": time.Second,
+		"fast:6379": 0,
+	}
+	client := NewClusterClient(&ClusterOptions{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return hedgeServer(addr, delays[addr], &gets), nil
+		},
+		ClusterSlots: func(ctx context.Context) ([]ClusterSlot, error) {
+			return []ClusterSlot{{
+				Start: 0,
+				End:   16383,
+				Nodes: []ClusterNode{{Addr: "fast:6379"}, {Addr: "slow:6379"}},
+			}}, nil
+		},
+		ReadOnly:         true,
+		HedgeDelay:       10 * time.Millisecond,
+		DisableIndentity: true,
+	})
+	defer client.Close()
+	client.cmdsInfoCache = newCmdsInfoCache(func(ctx context.Context) (map[string]*CommandInfo, error) {
+		return map[string]*CommandInfo{"get": {Name: "get", ReadOnly: true}}, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		start := time.Now()
+		val, err := client.Get(ctx, "key").Result()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val != "fast:6379" {
+			t.Fatalf("got %q, expected the reply of the fast node", val)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Fatalf("took %s, expected the hedged request to be used", elapsed)
+		}
+	}
+	if n := atomic.LoadInt32(&gets); n < 3 {
+		t.Fatalf("got %d requests, expected at least 3", n)
+	}
+}
+
+func TestCloneCmd(t *testing.T) {
+	cmd := NewStringCmd(ctx, "get", "key")
+	clone := cloneCmd(cmd).(*StringCmd)
+	clone.SetVal("value")
+	if cmd.Val() != "" {
+		t.Fatalf("got %q, expected the original cmd to be unchanged", cmd.Val())
+	}
+	if clone.Name() != "get" {
+		t.Fatalf("got %q, expected the arguments to be copied", clone.Name())
+	}
+}
@@ -0,0 +1,26 @@
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of the time of the connection pool and the retries.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SleepClock is like Sleep, but waits using the clock.
+// A nil clock is the system clock.
+func SleepClock(ctx context.Context, clock Clock, dur time.Duration) error {
+	if clock == nil {
+		return Sleep(ctx, dur)
+	}
+	select {
+	case <-clock.After(dur):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -217,4 +217,30 @@ var _ = Describe("Scan", func() {
 		Expect(Scan(&tt, i{"time"}, i{now.Format(time.RFC3339Nano)})).NotTo(HaveOccurred())
 		Expect(now.Unix()).To(Equal(tt.Time.Unix()))
 	})
+
+	It("scans the fields implementing Scanner by value and TextUnmarshaler by pointer", func() {
+		type Fields struct {
+			Login TimeRFC3339Nano `redis:"login"`
+			Time  *time.Time      `redis:"time"`
+		}
+
+		now := time.Now()
+
+		var fields Fields
+		Expect(Scan(&fields, i{"login", "time"}, i{now.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano)})).NotTo(HaveOccurred())
+		Expect(fields.Login.UnixNano()).To(Equal(now.UnixNano()))
+		Expect(fields.Time.UnixNano()).To(Equal(now.UnixNano()))
+	})
 })
+
+func BenchmarkScan(b *testing.B) {
+	keys := i{"string", "int", "uint64", "float64", "bool", "boolRef"}
+	vals := i{"hello", "123", "456", "1.5", "true", "false"}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		var d data
+		if err := Scan(&d, keys, vals); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -25,9 +25,8 @@ func (s *structMap) get(t reflect.Type) *structSpec {
 		return v.(*structSpec)
 	}
 
-	spec := newStructSpec(t, "redis")
-	s.m.Store(t, spec)
-	return spec
+	v, _ := s.m.LoadOrStore(t, newStructSpec(t, "redis"))
+	return v.(*structSpec)
 }
 
 //------------------------------------------------------------------------------
@@ -60,12 +59,7 @@ func newStructSpec(t reflect.Type, fieldTag string) *structSpec {
 			continue
 		}
 
-		// Use the built-in decoder.
-		kind := f.Type.Kind()
-		if kind == reflect.Pointer {
-			kind = f.Type.Elem().Kind()
-		}
-		out.set(tag, &structField{index: i, fn: decoders[kind]})
+		out.set(tag, newStructField(i, f))
 	}
 
 	return out
@@ -73,10 +67,56 @@ func newStructSpec(t reflect.Type, fieldTag string) *structSpec {
 
 //------------------------------------------------------------------------------
 
+var (
+	scannerType         = reflect.TypeOf((*Scanner)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
 // structField represents a single field in a target struct.
+// The way the field is decoded is resolved once per struct type,
+// so the scans don't look up the methods of the field.
 type structField struct {
 	index int
 	fn    decoderFunc
+
+	// elem is the type allocated for a nil pointer field,
+	// nil if the field is not a pointer.
+	elem reflect.Type
+	// The field, or its address if it is not a pointer, implements
+	// Scanner or encoding.TextUnmarshaler.
+	scanner         bool
+	textUnmarshaler bool
+}
+
+func newStructField(index int, f reflect.StructField) *structField {
+	field := &structField{index: index}
+
+	// Use the built-in decoder.
+	kind := f.Type.Kind()
+	if kind == reflect.Pointer {
+		field.elem = f.Type.Elem()
+		kind = field.elem.Kind()
+	}
+	field.fn = decoders[kind]
+
+	// The methods of the unexported fields can't be called.
+	if f.PkgPath != "" {
+		return field
+	}
+	ptrType := f.Type
+	if field.elem == nil {
+		if f.Type.Name() == "" {
+			return field
+		}
+		ptrType = reflect.PtrTo(f.Type)
+	}
+	switch {
+	case ptrType.Implements(scannerType):
+		field.scanner = true
+	case ptrType.Implements(textUnmarshalerType):
+		field.textUnmarshaler = true
+	}
+	return field
 }
 
 //------------------------------------------------------------------------------
@@ -93,26 +133,22 @@ func (s StructValue) Scan(key string, value string) error {
 	}
 
 	v := s.value.Field(field.index)
-	isPtr := v.Kind() == reflect.Ptr
-
-	if isPtr && v.IsNil() {
-		v.Set(reflect.New(v.Type().Elem()))
-	}
-	if !isPtr && v.Type().Name() != "" && v.CanAddr() {
-		v = v.Addr()
-		isPtr = true
+	if field.elem != nil && v.IsNil() {
+		v.Set(reflect.New(field.elem))
 	}
 
-	if isPtr && v.Type().NumMethod() > 0 && v.CanInterface() {
-		switch scan := v.Interface().(type) {
-		case Scanner:
-			return scan.ScanRedis(value)
-		case encoding.TextUnmarshaler:
-			return scan.UnmarshalText(util.StringToBytes(value))
+	if field.scanner || field.textUnmarshaler {
+		ptr := v
+		if field.elem == nil {
+			ptr = v.Addr()
+		}
+		if field.scanner {
+			return ptr.Interface().(Scanner).ScanRedis(value)
 		}
+		return ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText(util.StringToBytes(value))
 	}
 
-	if isPtr {
+	if field.elem != nil {
 		v = v.Elem()
 	}
 
@@ -16,3 +16,12 @@ func TestRetryBackoff(t *testing.T) {
 		Expect(backoff <= 512*time.Millisecond).To(BeTrue())
 	}
 }
+
+func TestFormatKeysAndValues(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(formatKeysAndValues("msg", nil)).To(Equal("msg"))
+	Expect(formatKeysAndValues("msg", []interface{}{"addr", "localhost:6379", "n", 1})).
+		To(Equal(`msg addr="localhost:6379" n="1"`))
+	Expect(formatKeysAndValues("msg", []interface{}{"key"})).To(Equal("msg key=MISSING"))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 type Logging interface {
@@ -24,3 +25,72 @@ func (l *logger) Printf(ctx context.Context, format string, v ...interface{}) {
 var Logger Logging = &logger{
 	log: log.New(os.Stderr, "redis: ", log.LstdFlags|log.Lshortfile),
 }
+
+// LeveledLogging is a structured logger with levels. keysAndValues
+// are alternating keys and values, e.g. "addr", addr, "err", err.
+type LeveledLogging interface {
+	Debug(ctx context.Context, msg string, keysAndValues ...interface{})
+	Info(ctx context.Context, msg string, keysAndValues ...interface{})
+	Warn(ctx context.Context, msg string, keysAndValues ...interface{})
+	Error(ctx context.Context, msg string, keysAndValues ...interface{})
+}
+
+// Leveled returns l or, if l is nil, a LeveledLogging that prints
+// the Info and higher levels with the global Logger.
+func Leveled(l LeveledLogging) LeveledLogging {
+	if l != nil {
+		return l
+	}
+	return printfLogger{}
+}
+
+// LeveledDebug is like Leveled, but the returned default logger
+// also prints the Debug level.
+func LeveledDebug(l LeveledLogging) LeveledLogging {
+	if l != nil {
+		return l
+	}
+	return printfLogger{debug: true}
+}
+
+type printfLogger struct {
+	debug bool
+}
+
+func (l printfLogger) Debug(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	if l.debug {
+		Logger.Printf(ctx, "%s", formatKeysAndValues(msg, keysAndValues))
+	}
+}
+
+func (printfLogger) Info(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	Logger.Printf(ctx, "%s", formatKeysAndValues(msg, keysAndValues))
+}
+
+func (printfLogger) Warn(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	Logger.Printf(ctx, "%s", formatKeysAndValues(msg, keysAndValues))
+}
+
+func (printfLogger) Error(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	Logger.Printf(ctx, "%s", formatKeysAndValues(msg, keysAndValues))
+}
+
+// formatKeysAndValues formats the msg followed by key=value pairs.
+func formatKeysAndValues(msg string, keysAndValues []interface{}) string {
+	if len(keysAndValues) == 0 {
+		return msg
+	}
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		b.WriteByte(' ')
+		fmt.Fprint(&b, keysAndValues[i])
+		b.WriteByte('=')
+		if i+1 < len(keysAndValues) {
+			fmt.Fprintf(&b, "%q", fmt.Sprint(keysAndValues[i+1]))
+		} else {
+			b.WriteString("MISSING")
+		}
+	}
+	return b.String()
+}
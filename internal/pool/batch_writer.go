@@ -0,0 +1,129 @@
+package pool
+
+import (
+	"net"
+	"sync"
+)
+
+const (
+	batchChunkSize = 32 << 10
+	// batchFlushSize is the number of buffered bytes written at once.
+	batchFlushSize = 16 * batchChunkSize
+)
+
+var chunkPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, batchChunkSize)
+		return &b
+	},
+}
+
+// batchWriter buffers the commands of a pipeline in pooled chunks, which
+// are written with a single vectored write (writev on TCP connections)
+// every batchFlushSize bytes. The memory used to write a pipeline
+// is bounded regardless of the number of commands.
+type batchWriter struct {
+	conn   net.Conn
+	chunks []*[]byte
+	bufs   net.Buffers
+	size   int
+	err    error
+}
+
+func (w *batchWriter) chunk() *[]byte {
+	if n := len(w.chunks); n > 0 {
+		if c := w.chunks[n-1]; len(*c) < cap(*c) {
+			return c
+		}
+	}
+	c := chunkPool.Get().(*[]byte)
+	w.chunks = append(w.chunks, c)
+	return c
+}
+
+func (w *batchWriter) Write(b []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := len(b)
+	for len(b) > 0 {
+		c := w.chunk()
+		m := cap(*c) - len(*c)
+		if m > len(b) {
+			m = len(b)
+		}
+		*c = append(*c, b[:m]...)
+		b = b[m:]
+		if err := w.wrote(m); err != nil {
+			return n - len(b), err
+		}
+	}
+	return n, nil
+}
+
+func (w *batchWriter) WriteString(s string) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := len(s)
+	for len(s) > 0 {
+		c := w.chunk()
+		m := cap(*c) - len(*c)
+		if m > len(s) {
+			m = len(s)
+		}
+		*c = append(*c, s[:m]...)
+		s = s[m:]
+		if err := w.wrote(m); err != nil {
+			return n - len(s), err
+		}
+	}
+	return n, nil
+}
+
+func (w *batchWriter) WriteByte(b byte) error {
+	if w.err != nil {
+		return w.err
+	}
+	c := w.chunk()
+	*c = append(*c, b)
+	return w.wrote(1)
+}
+
+func (w *batchWriter) wrote(n int) error {
+	w.size += n
+	if w.size >= batchFlushSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered chunks and returns them to the pool.
+func (w *batchWriter) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.size == 0 {
+		return nil
+	}
+	w.bufs = w.bufs[:0]
+	for _, c := range w.chunks {
+		w.bufs = append(w.bufs, *c)
+	}
+	// WriteTo consumes the slice, so a copy of it is written.
+	bufs := w.bufs
+	_, w.err = bufs.WriteTo(w.conn)
+	w.release()
+	return w.err
+}
+
+// release returns the chunks to the pool.
+func (w *batchWriter) release() {
+	for i, c := range w.chunks {
+		*c = (*c)[:0]
+		chunkPool.Put(c)
+		w.chunks[i] = nil
+	}
+	w.chunks = w.chunks[:0]
+	w.size = 0
+}
@@ -0,0 +1,81 @@
+package pool_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9/internal/pool"
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// writesConn records the writes to the connection.
+type writesConn struct {
+	net.Conn
+	buf    bytes.Buffer
+	writes []int
+}
+
+func (c *writesConn) Write(b []byte) (int, error) {
+	c.writes = append(c.writes, len(b))
+	return c.buf.Write(b)
+}
+
+func pipelineArgs(n int) [][]interface{} {
+	cmds := make([][]interface{}, n)
+	for i := range cmds {
+		cmds[i] = []interface{}{"set", "key:" + strconv.Itoa(i), bytes.Repeat([]byte("v"), i%100)}
+	}
+	return cmds
+}
+
+var _ = Describe("WithBatchWriter", func() {
+	It("writes the same data as WithWriter in bounded writes", func() {
+		cmds := pipelineArgs(10000)
+		cmds = append(cmds, []interface{}{"set", "big", bytes.Repeat([]byte("x"), 100<<10)})
+
+		var want bytes.Buffer
+		bw := bufio.NewWriter(&want)
+		wr := proto.NewWriter(bw)
+		for _, args := range cmds {
+			Expect(wr.WriteArgs(args)).NotTo(HaveOccurred())
+		}
+		Expect(bw.Flush()).NotTo(HaveOccurred())
+
+		netConn := &writesConn{Conn: newDummyConn()}
+		cn := pool.NewConn(netConn)
+		err := cn.WithBatchWriter(context.Background(), -1, func(wr *proto.Writer) error {
+			for _, args := range cmds {
+				if err := wr.WriteArgs(args); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(netConn.buf.Bytes()).To(Equal(want.Bytes()))
+
+		Expect(len(netConn.writes)).To(BeNumerically(">", 1))
+		for _, n := range netConn.writes {
+			Expect(n).To(BeNumerically("<=", 32<<10))
+		}
+	})
+
+	It("returns the write error", func() {
+		cn := pool.NewConn(newDummyConn())
+		err := cn.WithBatchWriter(context.Background(), -1, func(wr *proto.Writer) error {
+			for _, args := range pipelineArgs(100000) {
+				if err := wr.WriteArgs(args); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		Expect(err).To(Equal(errDummy))
+	})
+})
@@ -7,14 +7,18 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/redis/go-redis/v9/internal"
 	"github.com/redis/go-redis/v9/internal/proto"
 )
 
 var noDeadline = time.Time{}
 
+var lastConnID uint64 // atomic
+
 type Conn struct {
 	usedAt  int64 // atomic
 	netConn net.Conn
+	id      uint64
 
 	rd *proto.Reader
 	bw *bufio.Writer
@@ -23,11 +27,35 @@ type Conn struct {
 	Inited    bool
 	pooled    bool
 	createdAt time.Time
+	// clock sets usedAt, nil is the system clock.
+	clock internal.Clock
+
+	// DialTimings of the connection, set when it was dialed by the pool.
+	DialTimings DialTimings
+}
+
+// DialTimings holds the durations of dialing a connection. The phases are
+// recorded by dialers that support it, see ContextDialTimings.
+type DialTimings struct {
+	Dial    time.Duration // total time spent in the dialer
+	DNS     time.Duration // resolving the address
+	Connect time.Duration // establishing the network connection
+	TLS     time.Duration // TLS handshake
+}
+
+type dialTimingsKey struct{}
+
+// ContextDialTimings returns the timings to be filled by the dialer
+// or nil if the dial was not started by the pool.
+func ContextDialTimings(ctx context.Context) *DialTimings {
+	timings, _ := ctx.Value(dialTimingsKey{}).(*DialTimings)
+	return timings
 }
 
 func NewConn(netConn net.Conn) *Conn {
 	cn := &Conn{
 		netConn:   netConn,
+		id:        atomic.AddUint64(&lastConnID, 1),
 		createdAt: time.Now(),
 	}
 	cn.rd = proto.NewReader(netConn)
@@ -37,6 +65,11 @@ func NewConn(netConn net.Conn) *Conn {
 	return cn
 }
 
+// ID returns the process-wide unique id of the connection.
+func (cn *Conn) ID() uint64 {
+	return cn.id
+}
+
 func (cn *Conn) UsedAt() time.Time {
 	unix := atomic.LoadInt64(&cn.usedAt)
 	return time.Unix(unix, 0)
@@ -46,6 +79,12 @@ func (cn *Conn) SetUsedAt(tm time.Time) {
 	atomic.StoreInt64(&cn.usedAt, tm.Unix())
 }
 
+// SetPushHandler sets the handler of out-of-band RESP3 push messages
+// received on the connection. See proto.Reader.SetPushHandler.
+func (cn *Conn) SetPushHandler(fn func(push []interface{})) {
+	cn.rd.SetPushHandler(fn)
+}
+
 func (cn *Conn) SetNetConn(netConn net.Conn) {
 	cn.netConn = netConn
 	cn.rd.Reset(netConn)
@@ -56,6 +95,12 @@ func (cn *Conn) Write(b []byte) (int, error) {
 	return cn.netConn.Write(b)
 }
 
+// SetReadDeadline sets the read deadline of the underlying connection,
+// e.g. to interrupt a blocked read from another goroutine.
+func (cn *Conn) SetReadDeadline(tm time.Time) error {
+	return cn.netConn.SetReadDeadline(tm)
+}
+
 func (cn *Conn) RemoteAddr() net.Addr {
 	if cn.netConn != nil {
 		return cn.netConn.RemoteAddr()
@@ -71,7 +116,11 @@ func (cn *Conn) WithReader(
 			return err
 		}
 	}
-	return fn(cn.rd)
+	if err := fn(cn.rd); err != nil {
+		return err
+	}
+	cn.rd.AdaptBuffer()
+	return nil
 }
 
 func (cn *Conn) WithWriter(
@@ -94,13 +143,43 @@ func (cn *Conn) WithWriter(
 	return cn.bw.Flush()
 }
 
+// WithBatchWriter is like WithWriter, but the data is written in large
+// vectored writes of bounded size instead of through the connection buffer.
+// It is used to write pipelines.
+func (cn *Conn) WithBatchWriter(
+	ctx context.Context, timeout time.Duration, fn func(wr *proto.Writer) error,
+) error {
+	if timeout >= 0 {
+		if err := cn.netConn.SetWriteDeadline(cn.deadline(ctx, timeout)); err != nil {
+			return err
+		}
+	}
+
+	if cn.bw.Buffered() > 0 {
+		cn.bw.Reset(cn.netConn)
+	}
+
+	bw := &batchWriter{conn: cn.netConn}
+	defer bw.release()
+
+	if err := fn(proto.NewWriter(bw)); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
 func (cn *Conn) Close() error {
 	return cn.netConn.Close()
 }
 
 func (cn *Conn) deadline(ctx context.Context, timeout time.Duration) time.Time {
 	tm := time.Now()
-	cn.SetUsedAt(tm)
+	if cn.clock != nil {
+		cn.SetUsedAt(cn.clock.Now())
+	} else {
+		cn.SetUsedAt(tm)
+	}
 
 	if timeout > 0 {
 		tm = tm.Add(timeout)
@@ -12,3 +12,7 @@ func (cn *Conn) SetCreatedAt(tm time.Time) {
 func (cn *Conn) NetConn() net.Conn {
 	return cn.netConn
 }
+
+func (p *ConnPool) IdleShards() int {
+	return len(p.idleConns.shards)
+}
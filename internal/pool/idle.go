@@ -0,0 +1,112 @@
+package pool
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// procsPerIdleShard is the number of CPUs sharing a shard of the idle
+// connections, so there is a single shard on small machines.
+const procsPerIdleShard = 8
+
+// idleConns is the list of the idle connections. It is split into shards
+// with their own locks, so the goroutines getting and putting connections
+// on many CPUs don't contend on one mutex. The connections are taken in
+// LIFO or FIFO order within a shard.
+type idleConns struct {
+	fifo   bool
+	shards []idleShard
+
+	pushNext uint32 // atomic
+	popNext  uint32 // atomic
+}
+
+type idleShard struct {
+	mu    sync.Mutex
+	conns []*Conn
+
+	_ [32]byte // the shards don't share a cache line
+}
+
+func newIdleConns(fifo bool, poolSize int) *idleConns {
+	n := runtime.GOMAXPROCS(0) / procsPerIdleShard
+	if n > poolSize {
+		n = poolSize
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	l := &idleConns{
+		fifo:   fifo,
+		shards: make([]idleShard, n),
+	}
+	for i := range l.shards {
+		l.shards[i].conns = make([]*Conn, 0, poolSize/n)
+	}
+	return l
+}
+
+func (l *idleConns) next(counter *uint32) *idleShard {
+	if len(l.shards) == 1 {
+		return &l.shards[0]
+	}
+	return &l.shards[atomic.AddUint32(counter, 1)%uint32(len(l.shards))]
+}
+
+func (l *idleConns) push(cn *Conn) {
+	s := l.next(&l.pushNext)
+	s.mu.Lock()
+	s.conns = append(s.conns, cn)
+	s.mu.Unlock()
+}
+
+// pop returns an idle connection or nil if there are none.
+// The shards are tried in turn starting from the next one.
+func (l *idleConns) pop() *Conn {
+	start := l.next(&l.popNext)
+	if cn := start.pop(l.fifo); cn != nil {
+		return cn
+	}
+	for i := range l.shards {
+		if s := &l.shards[i]; s != start {
+			if cn := s.pop(l.fifo); cn != nil {
+				return cn
+			}
+		}
+	}
+	return nil
+}
+
+func (s *idleShard) pop(fifo bool) *Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.conns)
+	if n == 0 {
+		return nil
+	}
+
+	var cn *Conn
+	if fifo {
+		cn = s.conns[0]
+		copy(s.conns, s.conns[1:])
+		s.conns[n-1] = nil
+	} else {
+		cn = s.conns[n-1]
+		s.conns[n-1] = nil
+	}
+	s.conns = s.conns[:n-1]
+	return cn
+}
+
+// clear removes all the idle connections.
+func (l *idleConns) clear() {
+	for i := range l.shards {
+		s := &l.shards[i]
+		s.mu.Lock()
+		s.conns = nil
+		s.mu.Unlock()
+	}
+}
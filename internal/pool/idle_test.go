@@ -0,0 +1,75 @@
+package pool_test
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+
+	"github.com/redis/go-redis/v9/internal/pool"
+)
+
+var _ = Describe("sharded idle conns", func() {
+	ctx := context.Background()
+	var connPool *pool.ConnPool
+
+	BeforeEach(func() {
+		// The shards are created for the number of CPUs.
+		procs := runtime.GOMAXPROCS(32)
+		defer runtime.GOMAXPROCS(procs)
+
+		connPool = pool.NewConnPool(&pool.Options{
+			Dialer:          dummyDialer,
+			PoolSize:        100,
+			MaxIdleConns:    6,
+			PoolTimeout:     time.Second,
+			ConnMaxIdleTime: time.Hour,
+		})
+	})
+
+	AfterEach(func() {
+		connPool.Close()
+	})
+
+	It("finds the idle conns in all the shards", func() {
+		Expect(connPool.IdleShards()).To(Equal(4))
+
+		var cns []*pool.Conn
+		for i := 0; i < 8; i++ {
+			cn, err := connPool.Get(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			cns = append(cns, cn)
+		}
+		for _, cn := range cns {
+			connPool.Put(ctx, cn)
+		}
+		// The conns above MaxIdleConns are closed.
+		Expect(connPool.IdleLen()).To(Equal(6))
+		Expect(connPool.Len()).To(Equal(6))
+
+		got := make(map[*pool.Conn]bool)
+		for i := 0; i < 6; i++ {
+			cn, err := connPool.Get(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			got[cn] = true
+		}
+		Expect(got).To(HaveLen(6))
+		for _, cn := range cns[:6] {
+			Expect(got).To(HaveKey(cn))
+		}
+		Expect(connPool.IdleLen()).To(Equal(0))
+		Expect(connPool.Stats().Hits).To(Equal(uint32(6)))
+	})
+
+	It("is safe for concurrent use", func() {
+		perform(1000, func(int) {
+			cn, err := connPool.Get(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			connPool.Put(ctx, cn)
+		})
+		Expect(connPool.IdleLen()).To(BeNumerically("<=", 6))
+		Expect(connPool.IdleLen()).To(Equal(connPool.Len()))
+	})
+})
@@ -68,6 +68,21 @@ type Options struct {
 	MaxActiveConns  int
 	ConnMaxIdleTime time.Duration
 	ConnMaxLifetime time.Duration
+	// MaxReplyBuffer is the maximum size of the reused reply buffer
+	// of the connections, see proto.Reader.SetMaxBuffer.
+	MaxReplyBuffer int
+	// MaxInterned is the number of the interned reply values of
+	// the connections, see proto.Reader.SetInterning.
+	MaxInterned int
+	// MinReadBuffer and MaxReadBuffer bound the size of the read buffer
+	// of the connections, see proto.Reader.SetBufferBounds.
+	MinReadBuffer int
+	MaxReadBuffer int
+
+	Logger internal.LeveledLogging
+	// Clock checks the idle time and the lifetime of the connections.
+	// Default is the system clock.
+	Clock internal.Clock
 }
 
 type lastDialErrorWrap struct {
@@ -75,6 +90,10 @@ type lastDialErrorWrap struct {
 }
 
 type ConnPool struct {
+	// recycledID is the first field, so it is 64-bit aligned
+	// for the atomic operations on the 32-bit platforms.
+	recycledID uint64 // atomic, the last connection id at the time of Recycle
+
 	cfg *Options
 
 	dialErrorsNum uint32 // atomic
@@ -82,12 +101,15 @@ type ConnPool struct {
 
 	queue chan struct{}
 
-	connsMu   sync.Mutex
-	conns     []*Conn
-	idleConns []*Conn
+	connsMu sync.Mutex
+	conns   []*Conn
+
+	// idleConns are not guarded by connsMu, so getting and putting
+	// the idle connections does not lock the pool.
+	idleConns *idleConns
 
 	poolSize     int
-	idleConnsLen int
+	idleConnsLen int32 // atomic, including the idle connections being dialed
 
 	stats Stats
 
@@ -102,7 +124,7 @@ func NewConnPool(opt *Options) *ConnPool {
 
 		queue:     make(chan struct{}, opt.PoolSize),
 		conns:     make([]*Conn, 0, opt.PoolSize),
-		idleConns: make([]*Conn, 0, opt.PoolSize),
+		idleConns: newIdleConns(opt.PoolFIFO, opt.PoolSize),
 	}
 
 	p.connsMu.Lock()
@@ -116,18 +138,20 @@ func (p *ConnPool) checkMinIdleConns() {
 	if p.cfg.MinIdleConns == 0 {
 		return
 	}
-	for p.poolSize < p.cfg.PoolSize && p.idleConnsLen < p.cfg.MinIdleConns {
+	for p.poolSize < p.cfg.PoolSize && int(atomic.LoadInt32(&p.idleConnsLen)) < p.cfg.MinIdleConns {
 		select {
 		case p.queue <- struct{}{}:
 			p.poolSize++
-			p.idleConnsLen++
+			atomic.AddInt32(&p.idleConnsLen, 1)
 
 			go func() {
 				err := p.addIdleConn()
 				if err != nil && err != ErrClosed {
+					internal.Leveled(p.cfg.Logger).Warn(context.Background(),
+						"redis: adding idle connection failed", "err", err)
 					p.connsMu.Lock()
 					p.poolSize--
-					p.idleConnsLen--
+					atomic.AddInt32(&p.idleConnsLen, -1)
 					p.connsMu.Unlock()
 				}
 
@@ -155,7 +179,7 @@ func (p *ConnPool) addIdleConn() error {
 	}
 
 	p.conns = append(p.conns, cn)
-	p.idleConns = append(p.idleConns, cn)
+	p.idleConns.push(cn)
 	return nil
 }
 
@@ -210,8 +234,11 @@ func (p *ConnPool) dialConn(ctx context.Context, pooled bool) (*Conn, error) {
 		return nil, p.getLastDialError()
 	}
 
-	netConn, err := p.cfg.Dialer(ctx)
+	timings := new(DialTimings)
+	start := time.Now()
+	netConn, err := p.cfg.Dialer(context.WithValue(ctx, dialTimingsKey{}, timings))
 	if err != nil {
+		internal.Leveled(p.cfg.Logger).Debug(ctx, "redis: dial failed", "err", err)
 		p.setLastDialError(err)
 		if atomic.AddUint32(&p.dialErrorsNum, 1) == uint32(p.cfg.PoolSize) {
 			go p.tryDial()
@@ -220,7 +247,23 @@ func (p *ConnPool) dialConn(ctx context.Context, pooled bool) (*Conn, error) {
 	}
 
 	cn := NewConn(netConn)
+	if p.cfg.MaxReplyBuffer != 0 {
+		cn.rd.SetMaxBuffer(p.cfg.MaxReplyBuffer)
+	}
+	if p.cfg.MaxInterned > 0 {
+		cn.rd.SetInterning(p.cfg.MaxInterned)
+	}
+	if p.cfg.MinReadBuffer > 0 {
+		cn.rd.SetBufferBounds(p.cfg.MinReadBuffer, p.cfg.MaxReadBuffer)
+	}
+	if p.cfg.Clock != nil {
+		cn.clock = p.cfg.Clock
+		cn.createdAt = p.cfg.Clock.Now()
+		cn.SetUsedAt(cn.createdAt)
+	}
 	cn.pooled = pooled
+	cn.DialTimings = *timings
+	cn.DialTimings.Dial = time.Since(start)
 	return cn, nil
 }
 
@@ -232,8 +275,10 @@ func (p *ConnPool) tryDial() {
 
 		conn, err := p.cfg.Dialer(context.Background())
 		if err != nil {
+			internal.Leveled(p.cfg.Logger).Debug(context.Background(),
+				"redis: dial failed, retrying in 1s", "err", err)
 			p.setLastDialError(err)
-			time.Sleep(time.Second)
+			_ = internal.SleepClock(context.Background(), p.cfg.Clock, time.Second)
 			continue
 		}
 
@@ -266,10 +311,7 @@ func (p *ConnPool) Get(ctx context.Context) (*Conn, error) {
 	}
 
 	for {
-		p.connsMu.Lock()
 		cn, err := p.popIdle()
-		p.connsMu.Unlock()
-
 		if err != nil {
 			p.freeTurn()
 			return nil, err
@@ -343,56 +385,55 @@ func (p *ConnPool) popIdle() (*Conn, error) {
 	if p.closed() {
 		return nil, ErrClosed
 	}
-	n := len(p.idleConns)
-	if n == 0 {
+	cn := p.idleConns.pop()
+	if cn == nil {
 		return nil, nil
 	}
+	atomic.AddInt32(&p.idleConnsLen, -1)
 
-	var cn *Conn
-	if p.cfg.PoolFIFO {
-		cn = p.idleConns[0]
-		copy(p.idleConns, p.idleConns[1:])
-		p.idleConns = p.idleConns[:n-1]
-	} else {
-		idx := n - 1
-		cn = p.idleConns[idx]
-		p.idleConns = p.idleConns[:idx]
+	if p.cfg.MinIdleConns > 0 {
+		p.connsMu.Lock()
+		p.checkMinIdleConns()
+		p.connsMu.Unlock()
 	}
-	p.idleConnsLen--
-	p.checkMinIdleConns()
 	return cn, nil
 }
 
 func (p *ConnPool) Put(ctx context.Context, cn *Conn) {
 	if cn.rd.Buffered() > 0 {
-		internal.Logger.Printf(ctx, "Conn has unread data")
+		internal.Leveled(p.cfg.Logger).Warn(ctx, "redis: conn has unread data")
 		p.Remove(ctx, cn, BadConnError{})
 		return
 	}
 
-	if !cn.pooled {
+	if !cn.pooled || p.recycled(cn) {
 		p.Remove(ctx, cn, nil)
 		return
 	}
 
-	var shouldCloseConn bool
-
-	p.connsMu.Lock()
-
-	if p.cfg.MaxIdleConns == 0 || p.idleConnsLen < p.cfg.MaxIdleConns {
-		p.idleConns = append(p.idleConns, cn)
-		p.idleConnsLen++
-	} else {
-		p.removeConn(cn)
-		shouldCloseConn = true
+	if !p.reserveIdle() {
+		p.Remove(ctx, cn, nil)
+		return
 	}
-
-	p.connsMu.Unlock()
-
+	p.idleConns.push(cn)
 	p.freeTurn()
+}
 
-	if shouldCloseConn {
-		_ = p.closeConn(cn)
+// reserveIdle counts a connection being put to the idle connections,
+// unless there are MaxIdleConns already.
+func (p *ConnPool) reserveIdle() bool {
+	if p.cfg.MaxIdleConns == 0 {
+		atomic.AddInt32(&p.idleConnsLen, 1)
+		return true
+	}
+	for {
+		n := atomic.LoadInt32(&p.idleConnsLen)
+		if int(n) >= p.cfg.MaxIdleConns {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&p.idleConnsLen, n, n+1) {
+			return true
+		}
 	}
 }
 
@@ -441,10 +482,7 @@ func (p *ConnPool) Len() int {
 
 // IdleLen returns number of idle connections.
 func (p *ConnPool) IdleLen() int {
-	p.connsMu.Lock()
-	n := p.idleConnsLen
-	p.connsMu.Unlock()
-	return n
+	return int(atomic.LoadInt32(&p.idleConnsLen))
 }
 
 func (p *ConnPool) Stats() *Stats {
@@ -463,6 +501,19 @@ func (p *ConnPool) closed() bool {
 	return atomic.LoadUint32(&p._closed) == 1
 }
 
+// Recycle replaces the connections created so far with new ones. The connections
+// are closed when they are taken from or returned to the pool, so the connections
+// in use are not interrupted.
+func (p *ConnPool) Recycle() {
+	atomic.StoreUint64(&p.recycledID, atomic.LoadUint64(&lastConnID))
+}
+
+// recycled compares the connection ids rather than the creation times,
+// which may be equal when the time is set by a fake Clock.
+func (p *ConnPool) recycled(cn *Conn) bool {
+	return cn.id <= atomic.LoadUint64(&p.recycledID)
+}
+
 func (p *ConnPool) Filter(fn func(*Conn) bool) error {
 	p.connsMu.Lock()
 	defer p.connsMu.Unlock()
@@ -492,19 +543,29 @@ func (p *ConnPool) Close() error {
 	}
 	p.conns = nil
 	p.poolSize = 0
-	p.idleConns = nil
-	p.idleConnsLen = 0
+	p.idleConns.clear()
+	atomic.StoreInt32(&p.idleConnsLen, 0)
 	p.connsMu.Unlock()
 
 	return firstErr
 }
 
+func (p *ConnPool) now() time.Time {
+	if p.cfg.Clock != nil {
+		return p.cfg.Clock.Now()
+	}
+	return time.Now()
+}
+
 func (p *ConnPool) isHealthyConn(cn *Conn) bool {
-	now := time.Now()
+	now := p.now()
 
 	if p.cfg.ConnMaxLifetime > 0 && now.Sub(cn.createdAt) >= p.cfg.ConnMaxLifetime {
 		return false
 	}
+	if p.recycled(cn) {
+		return false
+	}
 	if p.cfg.ConnMaxIdleTime > 0 && now.Sub(cn.UsedAt()) >= p.cfg.ConnMaxIdleTime {
 		return false
 	}
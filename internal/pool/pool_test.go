@@ -66,6 +66,34 @@ var _ = Describe("ConnPool", func() {
 		}))
 	})
 
+	It("should replace recycled conns", func() {
+		connPool = pool.NewConnPool(&pool.Options{
+			Dialer:      dummyDialer,
+			PoolSize:    10,
+			PoolTimeout: time.Hour,
+		})
+
+		cn1, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cn2, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		connPool.Put(ctx, cn1)
+
+		connPool.Recycle()
+
+		// The conn in use is closed when it is returned.
+		connPool.Put(ctx, cn2)
+		Expect(connPool.Len()).To(Equal(1))
+
+		// The idle conn is replaced.
+		cn, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cn).NotTo(Equal(cn1))
+		Expect(connPool.Len()).To(Equal(1))
+		connPool.Put(ctx, cn)
+		Expect(connPool.IdleLen()).To(Equal(1))
+	})
+
 	It("should unblock client when conn is removed", func() {
 		// Reserve one connection.
 		cn, err := connPool.Get(ctx)
@@ -0,0 +1,76 @@
+package proto
+
+import (
+	"bufio"
+	"io"
+)
+
+// countingReader counts the bytes read from rd.
+type countingReader struct {
+	rd io.Reader
+	n  int
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.rd.Read(b)
+	c.n += n
+	return n, err
+}
+
+// bufferSizes tracks the moving average of the bytes read between the calls
+// of AdaptBuffer to size the read buffer.
+type bufferSizes struct {
+	min, max int
+	avg      int
+}
+
+// size returns the smallest power of two multiple of min not smaller than
+// the average, up to max.
+func (s *bufferSizes) size() int {
+	size := s.min
+	for size < s.avg && size < s.max {
+		size *= 2
+	}
+	if size > s.max {
+		size = s.max
+	}
+	return size
+}
+
+// SetBufferBounds sets the size of the read buffer to min bytes and makes
+// AdaptBuffer resize it up to max bytes. max <= min disables the resizing.
+func (r *Reader) SetBufferBounds(min, max int) {
+	r.sizes = bufferSizes{min: min, max: max}
+	if min > 0 && r.rd.Size() != min && r.rd.Buffered() == 0 {
+		r.rd = bufio.NewReaderSize(&r.src, min)
+	}
+}
+
+// AdaptBuffer records the number of the bytes read since the last call,
+// i.e. the replies of a command or a pipeline, and resizes the read buffer
+// to the moving average of these numbers within the bounds set by
+// SetBufferBounds. The buffer grows at once, so the workloads with large
+// replies don't refill a small buffer over and over, and shrinks only when
+// it is 4 times larger than needed, so it does not flap. The buffer reused
+// to read the bulk strings is released when it is twice larger than needed.
+func (r *Reader) AdaptBuffer() {
+	s := &r.sizes
+	if s.max <= s.min {
+		return
+	}
+
+	n := r.src.n
+	r.src.n = 0
+	s.avg += (n - s.avg) / 8
+
+	size := s.size()
+	if cap(r.buf) > s.min && cap(r.buf) > 2*s.avg {
+		r.buf = nil
+	}
+	if r.rd.Buffered() > 0 {
+		return
+	}
+	if cur := r.rd.Size(); size > cur || 4*size <= cur {
+		r.rd = bufio.NewReaderSize(&r.src, size)
+	}
+}
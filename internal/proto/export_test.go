@@ -0,0 +1,5 @@
+package proto
+
+func (r *Reader) BufferSize() int {
+	return r.rd.Size()
+}
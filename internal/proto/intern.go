@@ -0,0 +1,32 @@
+package proto
+
+// maxInternLen is the maximum length of the interned strings.
+const maxInternLen = 64
+
+// interner returns the same string for the repeated values, so they are
+// allocated once. It remembers up to max values and forgets them all when
+// it is full, so the values that stopped repeating are dropped.
+type interner struct {
+	strs map[string]string
+	max  int
+}
+
+func newInterner(max int) *interner {
+	return &interner{
+		strs: make(map[string]string),
+		max:  max,
+	}
+}
+
+func (in *interner) intern(b []byte) string {
+	// The conversion in the map index does not allocate.
+	if s, ok := in.strs[string(b)]; ok {
+		return s
+	}
+	if len(in.strs) >= in.max {
+		in.strs = make(map[string]string, len(in.strs))
+	}
+	s := string(b)
+	in.strs[s] = s
+	return s
+}
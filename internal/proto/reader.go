@@ -31,6 +31,19 @@ const (
 	RespPush      = '>' // ><len>\r\n... (same as Array)
 )
 
+const (
+	// maxReplyLen is the maximum length of a reply. Larger lengths are
+	// returned by the malformed replies, e.g. of a broken proxy.
+	maxReplyLen = math.MaxInt32
+	// maxPrealloc is the maximum number of the bytes of a string or
+	// the elements of an aggregate allocated before they are read,
+	// so that a malformed length does not allocate a huge buffer.
+	maxPrealloc = 1 << 16
+	// defaultMaxBuffer is the default maximum size of the buffer retained
+	// by a Reader to read bulk strings.
+	defaultMaxBuffer = 64 << 10
+)
+
 // Not used temporarily.
 // Redis has not used these two data types for the time being, and will implement them later.
 // Streamed           = "EOF:"
@@ -54,14 +67,59 @@ func ParseErrorReply(line []byte) error {
 
 type Reader struct {
 	rd *bufio.Reader
+	// src is the reader of rd, which counts the bytes read from it.
+	src countingReader
+	// sizes tunes the size of rd, see SetBufferBounds.
+	sizes bufferSizes
+
+	pushHandler func(push []interface{})
+
+	// buf is reused to read the bulk strings, which are copied from it.
+	buf    []byte
+	maxBuf int
+
+	interner *interner
 }
 
 func NewReader(rd io.Reader) *Reader {
-	return &Reader{
-		rd: bufio.NewReader(rd),
+	r := &Reader{
+		src:    countingReader{rd: rd},
+		maxBuf: defaultMaxBuffer,
+	}
+	r.rd = bufio.NewReader(&r.src)
+	return r
+}
+
+// SetMaxBuffer sets the maximum size of the buffer reused to read the bulk
+// strings. The buffer grown by a larger string is released after the string
+// is read. A negative size disables the buffer: every string is read into
+// its own slice, which is returned without a copy.
+func (r *Reader) SetMaxBuffer(n int) {
+	r.maxBuf = n
+	if cap(r.buf) > n {
+		r.buf = nil
 	}
 }
 
+// SetInterning makes the Reader return the same string for the repeated
+// status replies and bulk strings of at most 64 bytes, remembering up to n
+// distinct values. n <= 0 disables interning.
+func (r *Reader) SetInterning(n int) {
+	if n <= 0 {
+		r.interner = nil
+		return
+	}
+	r.interner = newInterner(n)
+}
+
+// str returns b as a string, interned if it is small enough.
+func (r *Reader) str(b []byte) string {
+	if r.interner != nil && len(b) <= maxInternLen {
+		return r.interner.intern(b)
+	}
+	return string(b)
+}
+
 func (r *Reader) Buffered() int {
 	return r.rd.Buffered()
 }
@@ -71,7 +129,26 @@ func (r *Reader) Peek(n int) ([]byte, error) {
 }
 
 func (r *Reader) Reset(rd io.Reader) {
-	r.rd.Reset(rd)
+	r.src = countingReader{rd: rd}
+	r.rd.Reset(&r.src)
+}
+
+// SetPushHandler sets the function that receives out-of-band RESP3 push messages.
+// When the handler is set, push messages are consumed by the Reader and skipped
+// the same way attributes are, so they never end up in a command reply.
+// A nil handler makes the Reader return push messages as regular replies,
+// which is what Pub/Sub connections need.
+func (r *Reader) SetPushHandler(fn func(push []interface{})) {
+	r.pushHandler = fn
+}
+
+func (r *Reader) handlePush(line []byte) error {
+	push, err := r.readSlice(line)
+	if err != nil {
+		return err
+	}
+	r.pushHandler(push)
+	return nil
 }
 
 // PeekReplyType returns the data type of the next response without advancing the Reader,
@@ -87,11 +164,21 @@ func (r *Reader) PeekReplyType() (byte, error) {
 		}
 		return r.PeekReplyType()
 	}
+	if b[0] == RespPush && r.pushHandler != nil {
+		line, err := r.readLine()
+		if err != nil {
+			return 0, err
+		}
+		if err = r.handlePush(line); err != nil {
+			return 0, err
+		}
+		return r.PeekReplyType()
+	}
 	return b[0], nil
 }
 
 // ReadLine Return a valid reply, it will check the protocol or redis error,
-// and discard the attribute type.
+// and discard the attribute type. Push messages are passed to the push handler, if any.
 func (r *Reader) ReadLine() ([]byte, error) {
 	line, err := r.readLine()
 	if err != nil {
@@ -114,6 +201,13 @@ func (r *Reader) ReadLine() ([]byte, error) {
 			return nil, err
 		}
 		return r.ReadLine()
+	case RespPush:
+		if r.pushHandler != nil {
+			if err = r.handlePush(line); err != nil {
+				return nil, err
+			}
+			return r.ReadLine()
+		}
 	}
 
 	// Compatible with RESP2
@@ -159,7 +253,7 @@ func (r *Reader) ReadReply() (interface{}, error) {
 
 	switch line[0] {
 	case RespStatus:
-		return string(line[1:]), nil
+		return r.str(line[1:]), nil
 	case RespInt:
 		return util.ParseInt(line[1:], 10, 64)
 	case RespFloat:
@@ -219,13 +313,65 @@ func (r *Reader) readStringReply(line []byte) (string, error) {
 		return "", err
 	}
 
-	b := make([]byte, n+2)
-	_, err = io.ReadFull(r.rd, b)
+	interned := r.interner != nil && n <= maxInternLen
+	if r.maxBuf < 0 && !interned {
+		b, err := readBulk(r.rd, nil, n)
+		if err != nil {
+			return "", err
+		}
+		return util.BytesToString(b[:n]), nil
+	}
+
+	b, err := readBulk(r.rd, r.buf, n)
 	if err != nil {
 		return "", err
 	}
+	s := r.str(b[:n])
+	if cap(b) <= r.maxBuf || interned {
+		r.buf = b[:0]
+	} else {
+		r.buf = nil
+	}
+	return s, nil
+}
+
+// readBulk reads the n bytes of a bulk string followed by CRLF into buf,
+// which is grown if needed, and returns it.
+func readBulk(rd io.Reader, buf []byte, n int) ([]byte, error) {
+	size := n + 2
+	buf = buf[:0]
+	if cap(buf) < size && size <= maxPrealloc {
+		buf = make([]byte, 0, size)
+	}
+
+	for len(buf) < size {
+		if len(buf) == cap(buf) {
+			// The length may be malformed, so the buffer grows
+			// with the data actually received.
+			newCap := 2 * cap(buf)
+			if newCap < maxPrealloc {
+				newCap = maxPrealloc
+			}
+			if newCap > size {
+				newCap = size
+			}
+			buf = append(make([]byte, 0, newCap), buf...)
+		}
 
-	return util.BytesToString(b[:n]), nil
+		end := cap(buf)
+		if end > size {
+			end = size
+		}
+		m, err := io.ReadFull(rd, buf[len(buf):end])
+		buf = buf[:len(buf)+m]
+		if err != nil {
+			if err == io.EOF && len(buf) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+	return buf, nil
 }
 
 func (r *Reader) readVerb(line []byte) (string, error) {
@@ -245,21 +391,21 @@ func (r *Reader) readSlice(line []byte) ([]interface{}, error) {
 		return nil, err
 	}
 
-	val := make([]interface{}, n)
-	for i := 0; i < len(val); i++ {
+	val := make([]interface{}, 0, preallocLen(n))
+	for i := 0; i < n; i++ {
 		v, err := r.ReadReply()
 		if err != nil {
 			if err == Nil {
-				val[i] = nil
+				val = append(val, nil)
 				continue
 			}
 			if err, ok := err.(RedisError); ok {
-				val[i] = err
+				val = append(val, err)
 				continue
 			}
 			return nil, err
 		}
-		val[i] = v
+		val = append(val, v)
 	}
 	return val, nil
 }
@@ -269,12 +415,17 @@ func (r *Reader) readMap(line []byte) (map[interface{}]interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	m := make(map[interface{}]interface{}, n)
+	m := make(map[interface{}]interface{}, preallocLen(n))
 	for i := 0; i < n; i++ {
 		k, err := r.ReadReply()
 		if err != nil {
 			return nil, err
 		}
+		switch k.(type) {
+		case []interface{}, map[interface{}]interface{}:
+			// The aggregates can't be the keys of a Go map.
+			return nil, fmt.Errorf("redis: unsupported map key type %T", k)
+		}
 		v, err := r.ReadReply()
 		if err != nil {
 			if err == Nil {
@@ -376,7 +527,11 @@ func (r *Reader) ReadString() (string, error) {
 
 	switch line[0] {
 	case RespStatus, RespInt, RespFloat:
-		return string(line[1:]), nil
+		if string(line[1:]) == "OK" {
+			// Avoid allocating the most common status reply.
+			return "OK", nil
+		}
+		return r.str(line[1:]), nil
 	case RespString:
 		return r.readStringReply(line)
 	case RespBool:
@@ -394,6 +549,26 @@ func (r *Reader) ReadString() (string, error) {
 	return "", fmt.Errorf("redis: can't parse reply=%.100q reading string", line)
 }
 
+// ReadStringLen reads the header of a bulk string reply and returns
+// the length of the value. The caller must then consume exactly n bytes
+// of the value followed by \r\n using Read.
+func (r *Reader) ReadStringLen() (int, error) {
+	line, err := r.ReadLine()
+	if err != nil {
+		return 0, err
+	}
+	if line[0] != RespString {
+		return 0, fmt.Errorf("redis: can't parse reply=%.100q reading string length", line)
+	}
+	return replyLen(line)
+}
+
+// Read reads raw bytes from the underlying buffered reader.
+// It is used to stream bulk string values, see ReadStringLen.
+func (r *Reader) Read(b []byte) (int, error) {
+	return r.rd.Read(b)
+}
+
 func (r *Reader) ReadBool() (bool, error) {
 	s, err := r.ReadString()
 	if err != nil {
@@ -530,7 +705,7 @@ func replyLen(line []byte) (n int, err error) {
 		return 0, err
 	}
 
-	if n < -1 {
+	if n < -1 || n > maxReplyLen {
 		return 0, fmt.Errorf("redis: invalid reply: %q", line)
 	}
 
@@ -544,6 +719,15 @@ func replyLen(line []byte) (n int, err error) {
 	return n, nil
 }
 
+// preallocLen returns the number of the elements of an aggregate
+// of length n to allocate before they are read.
+func preallocLen(n int) int {
+	if n > maxPrealloc {
+		return maxPrealloc
+	}
+	return n
+}
+
 // IsNilReply detects redis.Nil of RESP2.
 func IsNilReply(line []byte) bool {
 	return len(line) == 3 &&
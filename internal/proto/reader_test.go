@@ -3,6 +3,9 @@ package proto_test
 import (
 	"bytes"
 	"io"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/redis/go-redis/v9/internal/proto"
@@ -83,6 +86,216 @@ func TestReader_ReadLine(t *testing.T) {
 	}
 }
 
+func TestReader_PushHandler(t *testing.T) {
+	input := ">2\r\n$10\r\ninvalidate\r\n*1\r\n$3\r\nkey\r\n+OK\r\n"
+
+	r := proto.NewReader(bytes.NewReader([]byte(input)))
+	var pushes [][]interface{}
+	r.SetPushHandler(func(push []interface{}) {
+		pushes = append(pushes, push)
+	})
+
+	reply, err := r.ReadReply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != "OK" {
+		t.Errorf("got %v, expected OK", reply)
+	}
+	if len(pushes) != 1 || pushes[0][0] != "invalidate" {
+		t.Errorf("got %v, expected one invalidate push", pushes)
+	}
+
+	// Without a handler push messages are regular replies.
+	r = proto.NewReader(bytes.NewReader([]byte(input)))
+	reply, err = r.ReadReply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if push, ok := reply.([]interface{}); !ok || push[0] != "invalidate" {
+		t.Errorf("got %v, expected the push message", reply)
+	}
+}
+
+func bulkReplies(vals ...string) []byte {
+	var buf bytes.Buffer
+	for _, val := range vals {
+		buf.WriteString("$" + strconv.Itoa(len(val)) + "\r\n" + val + "\r\n")
+	}
+	return buf.Bytes()
+}
+
+func TestReader_ReuseBuffer(t *testing.T) {
+	large := strings.Repeat("x", 100<<10)
+	for _, maxBuf := range []int{1 << 20, 1 << 10, -1} {
+		r := proto.NewReader(bytes.NewReader(bulkReplies("hello", large, "world", "")))
+		r.SetMaxBuffer(maxBuf)
+
+		var got []string
+		for i := 0; i < 4; i++ {
+			s, err := r.ReadString()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, s)
+		}
+		// The strings read from the reused buffer are copies.
+		want := []string{"hello", large, "world", ""}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("maxBuf=%d: got %.20q", maxBuf, got)
+		}
+	}
+}
+
+func TestReader_AdaptBuffer(t *testing.T) {
+	large := strings.Repeat("x", 30<<10)
+	var vals []string
+	for i := 0; i < 50; i++ {
+		vals = append(vals, large)
+	}
+	for i := 0; i < 100; i++ {
+		vals = append(vals, "v"+strconv.Itoa(i))
+	}
+	r := proto.NewReader(bytes.NewReader(bulkReplies(vals...)))
+	r.SetBufferBounds(4<<10, 64<<10)
+
+	for i, want := range vals {
+		got, err := r.ReadString()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("got %.20q, wanted %.20q", got, want)
+		}
+		r.AdaptBuffer()
+
+		if i == 49 && r.BufferSize() < 32<<10 {
+			t.Fatalf("got buffer of %d bytes after the large replies", r.BufferSize())
+		}
+	}
+	if r.BufferSize() != 4<<10 {
+		t.Fatalf("got buffer of %d bytes after the small replies, wanted 4096", r.BufferSize())
+	}
+}
+
+func TestReader_ShortBulk(t *testing.T) {
+	r := proto.NewReader(strings.NewReader("$1000000\r\nshort"))
+	if _, err := r.ReadString(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("got %v, wanted ErrUnexpectedEOF", err)
+	}
+}
+
+func TestReader_Interning(t *testing.T) {
+	var input []byte
+	for i := 0; i < 200; i++ {
+		input = append(input, "+QUEUED\r\n"...)
+		input = append(input, bulkReplies("active")...)
+	}
+	r := proto.NewReader(bytes.NewReader(input))
+	r.SetInterning(10)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if s, err := r.ReadString(); err != nil || s != "QUEUED" {
+			t.Fatalf("got %q, %v", s, err)
+		}
+		if s, err := r.ReadString(); err != nil || s != "active" {
+			t.Fatalf("got %q, %v", s, err)
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("got %v allocs, wanted 0", allocs)
+	}
+
+	// The interned values are forgotten when there are too many of them.
+	var vals []string
+	for i := 0; i < 25; i++ {
+		vals = append(vals, "val"+strconv.Itoa(i))
+	}
+	large := strings.Repeat("x", 100)
+	r = proto.NewReader(bytes.NewReader(bulkReplies(append(append(vals, vals...), large)...)))
+	r.SetInterning(10)
+	for _, want := range append(append(vals, vals...), large) {
+		if got, err := r.ReadString(); err != nil || got != want {
+			t.Fatalf("got %q, %v, wanted %q", got, err, want)
+		}
+	}
+}
+
+func BenchmarkReader_ReadString_Large(b *testing.B) {
+	reply := bulkReplies(strings.Repeat("x", 256<<10))
+	rd := bytes.NewReader(reply)
+	r := proto.NewReader(rd)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(reply)))
+	for i := 0; i < b.N; i++ {
+		rd.Reset(reply)
+		r.Reset(rd)
+		if _, err := r.ReadString(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// readsCounter counts the reads, i.e. the system calls of a connection.
+type readsCounter struct {
+	rd    io.Reader
+	reads int
+}
+
+func (c *readsCounter) Read(b []byte) (int, error) {
+	c.reads++
+	return c.rd.Read(b)
+}
+
+func BenchmarkReader_AdaptBuffer(b *testing.B) {
+	// The reply of ZRANGE WITHSCORES of 1000 members.
+	var buf bytes.Buffer
+	buf.WriteString("*2000\r\n")
+	for i := 0; i < 1000; i++ {
+		member := "member:" + strconv.Itoa(i)
+		score := strconv.Itoa(i * 100)
+		buf.WriteString("$" + strconv.Itoa(len(member)) + "\r\n" + member + "\r\n")
+		buf.WriteString("$" + strconv.Itoa(len(score)) + "\r\n" + score + "\r\n")
+	}
+	reply := buf.Bytes()
+
+	for _, maxSize := range []int{-1, 64 << 10} {
+		b.Run("max="+strconv.Itoa(maxSize), func(b *testing.B) {
+			rd := bytes.NewReader(reply)
+			src := &readsCounter{rd: rd}
+			r := proto.NewReader(src)
+			r.SetBufferBounds(4<<10, maxSize)
+			b.ReportAllocs()
+			b.SetBytes(int64(len(reply)))
+			for i := 0; i < b.N; i++ {
+				rd.Reset(reply)
+				if _, err := r.ReadReply(); err != nil {
+					b.Fatal(err)
+				}
+				r.AdaptBuffer()
+			}
+			b.ReportMetric(float64(src.reads)/float64(b.N), "reads/op")
+		})
+	}
+}
+
+func BenchmarkReader_ParseReply_Interned(b *testing.B) {
+	buf := new(bytes.Buffer)
+	for i := 0; i < b.N; i++ {
+		buf.WriteString("$6\r\nactive\r\n")
+	}
+	p := proto.NewReader(buf)
+	p.SetInterning(100)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := p.ReadReply(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func benchmarkParseReply(b *testing.B, reply string, wanterr bool) {
 	buf := new(bytes.Buffer)
 	for i := 0; i < b.N; i++ {
@@ -98,3 +311,38 @@ func benchmarkParseReply(b *testing.B, reply string, wanterr bool) {
 		}
 	}
 }
+
+func FuzzReader_ReadReply(f *testing.F) {
+	for _, seed := range []string{
+		"+OK\r\n",
+		":1\r\n",
+		",123.456\r\n",
+		"#t\r\n",
+		"(3492890328409238509324850943850943825024385\r\n",
+		"-Error message\r\n",
+		"_\r\n",
+		"!21\r\nSYNTAX invalid syntax\r\n",
+		"$5\r\nhello\r\n",
+		"=9\r\ntxt:hello\r\n",
+		"*2\r\n$5\r\nhello\r\n$5\r\nworld\r\n",
+		"~2\r\n$5\r\nhello\r\n$5\r\nworld\r\n",
+		">2\r\n$5\r\nhello\r\n$5\r\nworld\r\n",
+		"%2\r\n$5\r\nhello\r\n$5\r\nworld\r\n+key\r\n+value\r\n",
+		"|1\r\n+key\r\n+value\r\n+hello\r\n",
+		// The malformed replies that used to panic.
+		"$9223372036854775807\r\n",
+		"*9223372036854775807\r\n",
+		"%1\r\n%0\r\n:1\r\n",
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		rd := proto.NewReader(bytes.NewReader(data))
+		for i := 0; i < 100; i++ {
+			if _, err := rd.ReadReply(); err == io.EOF {
+				return
+			}
+		}
+	})
+}
@@ -120,9 +120,11 @@ func Scan(b []byte, v interface{}) error {
 	case *net.IP:
 		*v = b
 		return nil
+	case encoding.TextUnmarshaler:
+		return v.UnmarshalText(b)
 	default:
 		return fmt.Errorf(
-			"redis: can't unmarshal %T (consider implementing BinaryUnmarshaler)", v)
+			"redis: can't unmarshal %T (consider implementing BinaryUnmarshaler or TextUnmarshaler)", v)
 	}
 }
 
@@ -2,6 +2,7 @@ package proto_test
 
 import (
 	"encoding/json"
+	"strings"
 
 	. "github.com/bsm/ginkgo/v2"
 	. "github.com/bsm/gomega"
@@ -22,6 +23,23 @@ func (s *testScanSliceStruct) UnmarshalBinary(b []byte) error {
 	return json.Unmarshal(b, s)
 }
 
+type testScanTextStruct struct {
+	Name string
+}
+
+func (s *testScanTextStruct) UnmarshalText(b []byte) error {
+	s.Name = strings.ToUpper(string(b))
+	return nil
+}
+
+var _ = Describe("Scan", func() {
+	It("should scan into TextUnmarshaler", func() {
+		var v testScanTextStruct
+		Expect(proto.Scan([]byte("hello"), &v)).NotTo(HaveOccurred())
+		Expect(v.Name).To(Equal("HELLO"))
+	})
+})
+
 var _ = Describe("ScanSlice", func() {
 	data := []string{
 		`{"ID":-1,"Name":"Back Yu"}`,
@@ -2,6 +2,7 @@ package proto
 
 import (
 	"encoding"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -11,6 +12,24 @@ import (
 	"github.com/redis/go-redis/v9/internal/util"
 )
 
+// ReaderArg is a command argument whose value is copied from R directly
+// to the connection, without materializing it in memory first.
+// Size must be the exact number of bytes R yields.
+//
+// If the command is retried or redirected, R is rewound when it implements
+// io.Seeker; otherwise writing the argument the second time fails.
+type ReaderArg struct {
+	R    io.Reader
+	Size int64
+
+	written bool
+	start   int64
+}
+
+func (a *ReaderArg) String() string {
+	return "<" + strconv.FormatInt(a.Size, 10) + " bytes>"
+}
+
 type writer interface {
 	io.Writer
 	io.ByteWriter
@@ -21,25 +40,31 @@ type writer interface {
 type Writer struct {
 	writer
 
-	lenBuf []byte
-	numBuf []byte
+	// buf is the encoded argument, written to the writer at once.
+	buf []byte
 }
 
 func NewWriter(wr writer) *Writer {
 	return &Writer{
 		writer: wr,
 
-		lenBuf: make([]byte, 64),
-		numBuf: make([]byte, 64),
+		buf: make([]byte, 0, 64),
 	}
 }
 
-func (w *Writer) WriteArgs(args []interface{}) error {
-	if err := w.WriteByte(RespArray); err != nil {
-		return err
-	}
+const (
+	// maxBufferedArg is the size of the strings copied to the buffer of
+	// the Writer; the larger strings are written directly.
+	maxBufferedArg = 1 << 10
+	// maxWriterBuf is the capacity of the buffer kept after an argument,
+	// e.g. a large marshaled value, is written.
+	maxWriterBuf = 64 << 10
+)
 
-	if err := w.writeLen(len(args)); err != nil {
+func (w *Writer) WriteArgs(args []interface{}) error {
+	w.buf = append(w.buf[:0], RespArray)
+	w.buf = appendLen(w.buf, int64(len(args)))
+	if _, err := w.Write(w.buf); err != nil {
 		return err
 	}
 
@@ -52,106 +77,176 @@ func (w *Writer) WriteArgs(args []interface{}) error {
 	return nil
 }
 
-func (w *Writer) writeLen(n int) error {
-	w.lenBuf = strconv.AppendUint(w.lenBuf[:0], uint64(n), 10)
-	w.lenBuf = append(w.lenBuf, '\r', '\n')
-	_, err := w.Write(w.lenBuf)
+func (w *Writer) WriteArg(v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		if len(v) > maxBufferedArg {
+			return w.bytes(util.StringToBytes(v))
+		}
+	case []byte:
+		if len(v) > maxBufferedArg {
+			return w.bytes(v)
+		}
+	case *ReaderArg:
+		return w.reader(v)
+	}
+
+	var err error
+	w.buf, err = AppendArg(w.buf[:0], v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(w.buf)
+	if cap(w.buf) > maxWriterBuf {
+		w.buf = make([]byte, 0, 64)
+	}
 	return err
 }
 
-func (w *Writer) WriteArg(v interface{}) error {
+// AppendArg appends the command argument v encoded as a RESP bulk string
+// to b and returns the extended buffer. Strings, byte slices, integers and
+// floats are encoded without allocations.
+func AppendArg(b []byte, v interface{}) ([]byte, error) {
+	// Fast paths for the most common arguments.
 	switch v := v.(type) {
-	case nil:
-		return w.string("")
 	case string:
-		return w.string(v)
-	case *string:
-		return w.string(*v)
+		return appendString(b, v), nil
 	case []byte:
-		return w.bytes(v)
+		return appendBytes(b, v), nil
 	case int:
-		return w.int(int64(v))
+		return appendInt(b, int64(v)), nil
+	case int64:
+		return appendInt(b, v), nil
+	case float64:
+		return appendFloat(b, v), nil
+	}
+	return appendArg(b, v)
+}
+
+func appendArg(b []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return appendString(b, ""), nil
+	case *string:
+		return appendString(b, *v), nil
 	case *int:
-		return w.int(int64(*v))
+		return appendInt(b, int64(*v)), nil
 	case int8:
-		return w.int(int64(v))
+		return appendInt(b, int64(v)), nil
 	case *int8:
-		return w.int(int64(*v))
+		return appendInt(b, int64(*v)), nil
 	case int16:
-		return w.int(int64(v))
+		return appendInt(b, int64(v)), nil
 	case *int16:
-		return w.int(int64(*v))
+		return appendInt(b, int64(*v)), nil
 	case int32:
-		return w.int(int64(v))
+		return appendInt(b, int64(v)), nil
 	case *int32:
-		return w.int(int64(*v))
-	case int64:
-		return w.int(v)
+		return appendInt(b, int64(*v)), nil
 	case *int64:
-		return w.int(*v)
+		return appendInt(b, *v), nil
 	case uint:
-		return w.uint(uint64(v))
+		return appendUint(b, uint64(v)), nil
 	case *uint:
-		return w.uint(uint64(*v))
+		return appendUint(b, uint64(*v)), nil
 	case uint8:
-		return w.uint(uint64(v))
+		return appendUint(b, uint64(v)), nil
 	case *uint8:
-		return w.uint(uint64(*v))
+		return appendUint(b, uint64(*v)), nil
 	case uint16:
-		return w.uint(uint64(v))
+		return appendUint(b, uint64(v)), nil
 	case *uint16:
-		return w.uint(uint64(*v))
+		return appendUint(b, uint64(*v)), nil
 	case uint32:
-		return w.uint(uint64(v))
+		return appendUint(b, uint64(v)), nil
 	case *uint32:
-		return w.uint(uint64(*v))
+		return appendUint(b, uint64(*v)), nil
 	case uint64:
-		return w.uint(v)
+		return appendUint(b, v), nil
 	case *uint64:
-		return w.uint(*v)
+		return appendUint(b, *v), nil
 	case float32:
-		return w.float(float64(v))
+		return appendFloat(b, float64(v)), nil
 	case *float32:
-		return w.float(float64(*v))
-	case float64:
-		return w.float(v)
+		return appendFloat(b, float64(*v)), nil
 	case *float64:
-		return w.float(*v)
+		return appendFloat(b, *v), nil
 	case bool:
 		if v {
-			return w.int(1)
+			return appendInt(b, 1), nil
 		}
-		return w.int(0)
+		return appendInt(b, 0), nil
 	case *bool:
 		if *v {
-			return w.int(1)
+			return appendInt(b, 1), nil
 		}
-		return w.int(0)
+		return appendInt(b, 0), nil
 	case time.Time:
-		w.numBuf = v.AppendFormat(w.numBuf[:0], time.RFC3339Nano)
-		return w.bytes(w.numBuf)
+		var num [64]byte
+		return appendBytes(b, v.AppendFormat(num[:0], time.RFC3339Nano)), nil
 	case time.Duration:
-		return w.int(v.Nanoseconds())
+		return appendInt(b, v.Nanoseconds()), nil
 	case encoding.BinaryMarshaler:
-		b, err := v.MarshalBinary()
+		data, err := v.MarshalBinary()
 		if err != nil {
-			return err
+			return b, err
 		}
-		return w.bytes(b)
+		return appendBytes(b, data), nil
 	case net.IP:
-		return w.bytes(v)
+		return appendBytes(b, v), nil
+	case encoding.TextMarshaler:
+		data, err := v.MarshalText()
+		if err != nil {
+			return b, err
+		}
+		return appendBytes(b, data), nil
+	case *ReaderArg:
+		return b, errors.New("redis: can't append ReaderArg, use Writer.WriteArg")
 	default:
-		return fmt.Errorf(
-			"redis: can't marshal %T (implement encoding.BinaryMarshaler)", v)
+		return b, fmt.Errorf(
+			"redis: can't marshal %T (implement encoding.BinaryMarshaler or encoding.TextMarshaler)", v)
 	}
 }
 
-func (w *Writer) bytes(b []byte) error {
-	if err := w.WriteByte(RespString); err != nil {
-		return err
-	}
+func appendLen(b []byte, n int64) []byte {
+	b = strconv.AppendInt(b, n, 10)
+	return append(b, '\r', '\n')
+}
+
+func appendBytes(b, data []byte) []byte {
+	b = append(b, RespString)
+	b = appendLen(b, int64(len(data)))
+	b = append(b, data...)
+	return append(b, '\r', '\n')
+}
+
+func appendString(b []byte, s string) []byte {
+	b = append(b, RespString)
+	b = appendLen(b, int64(len(s)))
+	b = append(b, s...)
+	return append(b, '\r', '\n')
+}
 
-	if err := w.writeLen(len(b)); err != nil {
+func appendInt(b []byte, n int64) []byte {
+	var num [20]byte
+	return appendBytes(b, strconv.AppendInt(num[:0], n, 10))
+}
+
+func appendUint(b []byte, n uint64) []byte {
+	var num [20]byte
+	return appendBytes(b, strconv.AppendUint(num[:0], n, 10))
+}
+
+func appendFloat(b []byte, f float64) []byte {
+	var num [32]byte
+	return appendBytes(b, strconv.AppendFloat(num[:0], f, 'f', -1, 64))
+}
+
+// bytes writes a large bulk string without copying it to the buffer.
+func (w *Writer) bytes(b []byte) error {
+	w.buf = append(w.buf[:0], RespString)
+	w.buf = appendLen(w.buf, int64(len(b)))
+	if _, err := w.Write(w.buf); err != nil {
 		return err
 	}
 
@@ -162,23 +257,47 @@ func (w *Writer) bytes(b []byte) error {
 	return w.crlf()
 }
 
-func (w *Writer) string(s string) error {
-	return w.bytes(util.StringToBytes(s))
-}
+func (w *Writer) reader(a *ReaderArg) error {
+	if err := a.rewind(); err != nil {
+		return err
+	}
 
-func (w *Writer) uint(n uint64) error {
-	w.numBuf = strconv.AppendUint(w.numBuf[:0], n, 10)
-	return w.bytes(w.numBuf)
-}
+	if err := w.WriteByte(RespString); err != nil {
+		return err
+	}
+
+	w.buf = appendLen(w.buf[:0], a.Size)
+	if _, err := w.Write(w.buf); err != nil {
+		return err
+	}
+
+	n, err := io.CopyN(w, a.R, a.Size)
+	if err == io.EOF {
+		return fmt.Errorf("redis: ReaderArg returned %d bytes, wanted %d", n, a.Size)
+	}
+	if err != nil {
+		return err
+	}
 
-func (w *Writer) int(n int64) error {
-	w.numBuf = strconv.AppendInt(w.numBuf[:0], n, 10)
-	return w.bytes(w.numBuf)
+	return w.crlf()
 }
 
-func (w *Writer) float(f float64) error {
-	w.numBuf = strconv.AppendFloat(w.numBuf[:0], f, 'f', -1, 64)
-	return w.bytes(w.numBuf)
+func (a *ReaderArg) rewind() error {
+	s, canSeek := a.R.(io.Seeker)
+	if !a.written {
+		a.written = true
+		if canSeek {
+			var err error
+			a.start, err = s.Seek(0, io.SeekCurrent)
+			return err
+		}
+		return nil
+	}
+	if !canSeek {
+		return errors.New("redis: ReaderArg can't be written twice, R does not implement io.Seeker")
+	}
+	_, err := s.Seek(a.start, io.SeekStart)
+	return err
 }
 
 func (w *Writer) crlf() error {
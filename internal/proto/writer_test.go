@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding"
 	"fmt"
+	"io"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,6 +25,14 @@ func (t *MyType) MarshalBinary() ([]byte, error) {
 	return []byte("hello"), nil
 }
 
+type MyTextType struct{}
+
+var _ encoding.TextMarshaler = (*MyTextType)(nil)
+
+func (t *MyTextType) MarshalText() ([]byte, error) {
+	return []byte("hello"), nil
+}
+
 var _ = Describe("WriteBuffer", func() {
 	var buf *bytes.Buffer
 	var wr *proto.Writer
@@ -68,12 +78,44 @@ var _ = Describe("WriteBuffer", func() {
 		Expect(buf.Len()).To(Equal(15))
 	})
 
+	It("should append text marshalable args", func() {
+		err := wr.WriteArgs([]interface{}{&MyTextType{}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(buf.String()).To(Equal("*1\r\n$5\r\nhello\r\n"))
+	})
+
 	It("should append net.IP", func() {
 		ip := net.ParseIP("192.168.1.1")
 		err := wr.WriteArgs([]interface{}{ip})
 		Expect(err).NotTo(HaveOccurred())
 		Expect(buf.String()).To(Equal(fmt.Sprintf("*1\r\n$16\r\n%s\r\n", bytes.NewBuffer(ip))))
 	})
+
+	It("should stream ReaderArg", func() {
+		arg := &proto.ReaderArg{R: strings.NewReader("hello"), Size: 5}
+		err := wr.WriteArgs([]interface{}{"set", "key", arg})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buf.String()).To(Equal("*3\r\n$3\r\nset\r\n$3\r\nkey\r\n$5\r\nhello\r\n"))
+
+		// Seekable readers are rewound on retries.
+		buf.Reset()
+		err = wr.WriteArgs([]interface{}{arg})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buf.String()).To(Equal("*1\r\n$5\r\nhello\r\n"))
+	})
+
+	It("should fail on short ReaderArg", func() {
+		arg := &proto.ReaderArg{R: strings.NewReader("hi"), Size: 5}
+		err := wr.WriteArgs([]interface{}{arg})
+		Expect(err).To(MatchError("redis: ReaderArg returned 2 bytes, wanted 5"))
+	})
+
+	It("should not write non-seekable ReaderArg twice", func() {
+		arg := &proto.ReaderArg{R: io.LimitReader(strings.NewReader("hello"), 5), Size: 5}
+		Expect(wr.WriteArgs([]interface{}{arg})).NotTo(HaveOccurred())
+		Expect(wr.WriteArgs([]interface{}{arg})).To(HaveOccurred())
+	})
 })
 
 type discard struct{}
@@ -102,6 +144,32 @@ func BenchmarkWriteBuffer_Append(b *testing.B) {
 	}
 }
 
+func BenchmarkWriteArgs_Set(b *testing.B) {
+	buf := proto.NewWriter(discard{})
+	args := []interface{}{"set", "key", []byte("value"), "px", int64(1500), "xx"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := buf.WriteArgs(args)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteArgs_ZAdd(b *testing.B) {
+	buf := proto.NewWriter(discard{})
+	args := []interface{}{"zadd", "key", 1.5, "a", 2.25, "b", 1e10, "c"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := buf.WriteArgs(args)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 var _ = Describe("WriteArg", func() {
 	var buf *bytes.Buffer
 	var wr *proto.Writer
@@ -150,5 +218,38 @@ var _ = Describe("WriteArg", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(buf.String()).To(Equal(expect))
 		})
+
+		It(fmt.Sprintf("should append arg of type %T", arg), func() {
+			b, err := proto.AppendArg([]byte("prefix"), arg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal("prefix" + expect))
+		})
 	}
+
+	It("should write large strings", func() {
+		s := strings.Repeat("x", 4096)
+		Expect(wr.WriteArg(s)).NotTo(HaveOccurred())
+		Expect(wr.WriteArg([]byte(s))).NotTo(HaveOccurred())
+		expect := "$4096\r\n" + s + "\r\n"
+		Expect(buf.String()).To(Equal(expect + expect))
+	})
+
+	It("should not allocate for the common args", func() {
+		wr := proto.NewWriter(discard{})
+		b := make([]byte, 0, 256)
+		for _, arg := range []interface{}{"hello", []byte("hello"), 12345, int64(12345), 10.3} {
+			allocs := testing.AllocsPerRun(100, func() {
+				if err := wr.WriteArg(arg); err != nil {
+					panic(err)
+				}
+				b, _ = proto.AppendArg(b[:0], arg)
+			})
+			Expect(allocs).To(BeZero(), "%T", arg)
+		}
+	})
+
+	It("should not append ReaderArg", func() {
+		_, err := proto.AppendArg(nil, &proto.ReaderArg{R: strings.NewReader("hi"), Size: 2})
+		Expect(err).To(HaveOccurred())
+	})
 })
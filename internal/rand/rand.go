@@ -16,6 +16,9 @@ func Intn(n int) int { return pseudo.Intn(n) }
 // It panics if n <= 0.
 func Int63n(n int64) int64 { return pseudo.Int63n(n) }
 
+// Float64 returns, as a float64, a pseudo-random number in [0.0,1.0).
+func Float64() float64 { return pseudo.Float64() }
+
 // Perm returns, as a slice of n ints, a pseudo-random permutation of the integers [0,n).
 func Perm(n int) []int { return pseudo.Perm(n) }
 
@@ -2,12 +2,24 @@ package redis
 
 import (
 	"context"
+	"strconv"
 )
 
 // ScanIterator is used to incrementally iterate over a collection of elements.
 type ScanIterator struct {
 	cmd *ScanCmd
 	pos int
+
+	// Values already returned, when deduplicating.
+	seen map[string]struct{}
+}
+
+// Dedup makes the iterator skip the values it has already returned,
+// since SCAN may return an element more than once. The returned values
+// are kept in memory until the iteration ends.
+func (it *ScanIterator) Dedup() *ScanIterator {
+	it.seen = make(map[string]struct{})
+	return it
 }
 
 // Err returns the last iterator error, if any.
@@ -17,6 +29,15 @@ func (it *ScanIterator) Err() error {
 
 // Next advances the cursor and returns true if more values can be read.
 func (it *ScanIterator) Next(ctx context.Context) bool {
+	for it.next(ctx) {
+		if it.seen == nil || !isSeen(it.seen, it.Val()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *ScanIterator) next(ctx context.Context) bool {
 	// Instantly return on errors.
 	if it.cmd.Err() != nil {
 		return false
@@ -64,3 +85,140 @@ func (it *ScanIterator) Val() string {
 	}
 	return v
 }
+
+// isSeen reports whether the value was seen before and marks it as seen.
+func isSeen(seen map[string]struct{}, v string) bool {
+	if _, ok := seen[v]; ok {
+		return true
+	}
+	seen[v] = struct{}{}
+	return false
+}
+
+//------------------------------------------------------------------------------
+
+// HashScanIterator is used to incrementally iterate over the field-value pairs
+// returned by HScan.
+type HashScanIterator struct {
+	it    ScanIterator
+	field string
+	value string
+
+	// Fields already returned, when deduplicating.
+	seen map[string]struct{}
+}
+
+// HashIterator creates a new HashScanIterator. The command must be an HScan
+// command returning values.
+func (cmd *ScanCmd) HashIterator() *HashScanIterator {
+	return &HashScanIterator{
+		it: ScanIterator{cmd: cmd},
+	}
+}
+
+// Dedup makes the iterator skip the fields it has already returned.
+func (it *HashScanIterator) Dedup() *HashScanIterator {
+	it.seen = make(map[string]struct{})
+	return it
+}
+
+// Err returns the last iterator error, if any.
+func (it *HashScanIterator) Err() error {
+	return it.it.Err()
+}
+
+// Next advances the cursor and returns true if more pairs can be read.
+func (it *HashScanIterator) Next(ctx context.Context) bool {
+	for {
+		// The pairs never span pages.
+		if !it.it.next(ctx) {
+			return false
+		}
+		it.field = it.it.Val()
+		if !it.it.next(ctx) {
+			return false
+		}
+		it.value = it.it.Val()
+
+		if it.seen == nil || !isSeen(it.seen, it.field) {
+			return true
+		}
+	}
+}
+
+// Field returns the field at the current cursor position.
+func (it *HashScanIterator) Field() string {
+	return it.field
+}
+
+// Value returns the value at the current cursor position.
+func (it *HashScanIterator) Value() string {
+	return it.value
+}
+
+//------------------------------------------------------------------------------
+
+// ZScanIterator is used to incrementally iterate over the members and scores
+// returned by ZScan.
+type ZScanIterator struct {
+	it  ScanIterator
+	val Z
+	err error
+
+	// Members already returned, when deduplicating.
+	seen map[string]struct{}
+}
+
+// ZIterator creates a new ZScanIterator. The command must be a ZScan command.
+func (cmd *ScanCmd) ZIterator() *ZScanIterator {
+	return &ZScanIterator{
+		it: ScanIterator{cmd: cmd},
+	}
+}
+
+// Dedup makes the iterator skip the members it has already returned.
+func (it *ZScanIterator) Dedup() *ZScanIterator {
+	it.seen = make(map[string]struct{})
+	return it
+}
+
+// Err returns the last iterator error, if any.
+func (it *ZScanIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.it.Err()
+}
+
+// Next advances the cursor and returns true if more members can be read.
+func (it *ZScanIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	for {
+		// The pairs never span pages.
+		if !it.it.next(ctx) {
+			return false
+		}
+		member := it.it.Val()
+		if !it.it.next(ctx) {
+			return false
+		}
+		score, err := strconv.ParseFloat(it.it.Val(), 64)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		if it.seen == nil || !isSeen(it.seen, member) {
+			it.val = Z{Score: score, Member: member}
+			return true
+		}
+	}
+}
+
+// Val returns the member and its score at the current cursor position.
+// The member is a string.
+func (it *ZScanIterator) Val() Z {
+	return it.val
+}
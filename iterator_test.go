@@ -99,6 +99,36 @@ var _ = Describe("ScanIterator", func() {
 		Expect(vals).To(ContainElement("x"))
 	})
 
+	It("should hscan pairs across multiple pages", func() {
+		Expect(hashSeed(71)).NotTo(HaveOccurred())
+
+		fields := make(map[string]string)
+		iter := client.HScan(ctx, hashKey, 0, "", 10).HashIterator().Dedup()
+		for iter.Next(ctx) {
+			fields[iter.Field()] = iter.Value()
+		}
+		Expect(iter.Err()).NotTo(HaveOccurred())
+		Expect(fields).To(HaveLen(71))
+		Expect(fields).To(HaveKeyWithValue("K01", "x"))
+		Expect(fields).To(HaveKeyWithValue("K71", "x"))
+	})
+
+	It("should zscan members across multiple pages", func() {
+		for i := 1; i <= 71; i++ {
+			Expect(client.ZAdd(ctx, "zset", redis.Z{Score: float64(i), Member: fmt.Sprintf("M%02d", i)}).Err()).NotTo(HaveOccurred())
+		}
+
+		var members []redis.Z
+		iter := client.ZScan(ctx, "zset", 0, "", 10).ZIterator()
+		for iter.Next(ctx) {
+			members = append(members, iter.Val())
+		}
+		Expect(iter.Err()).NotTo(HaveOccurred())
+		Expect(members).To(HaveLen(71))
+		Expect(members).To(ContainElement(redis.Z{Score: 1, Member: "M01"}))
+		Expect(members).To(ContainElement(redis.Z{Score: 71, Member: "M71"}))
+	})
+
 	It("should hscan without values across multiple pages", Label("NonRedisEnterprise"), func() {
 		Expect(hashSeed(71)).NotTo(HaveOccurred())
 
@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// kvServer is a fake server storing string keys.
+type kvServer struct {
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+func newKVServer(keys map[string]string) *kvServer {
+	return &kvServer{keys: keys}
+}
+
+func bulk(s string) string {
+	return "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"
+}
+
+func (s *kvServer) reply(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch args[0] {
+	case "hello":
+		return string(initHello)
+	case "get":
+		if val, ok := s.keys[args[1]]; ok {
+			return bulk(val)
+		}
+		return "_\r\n"
+	case "set":
+		s.keys[args[1]] = args[2]
+		return "+OK\r\n"
+	case "incrby":
+		n, _ := strconv.Atoi(s.keys[args[1]])
+		by, _ := strconv.Atoi(args[2])
+		s.keys[args[1]] = strconv.Itoa(n + by)
+		return ":" + s.keys[args[1]] + "\r\n"
+	case "mget":
+		reply := "*" + strconv.Itoa(len(args)-1) + "\r\n"
+		for _, key := range args[1:] {
+			if val, ok := s.keys[key]; ok {
+				reply += bulk(val)
+			} else {
+				reply += "_\r\n"
+			}
+		}
+		return reply
+	case "del":
+		var n int
+		for _, key := range args[1:] {
+			if _, ok := s.keys[key]; ok {
+				delete(s.keys, key)
+				n++
+			}
+		}
+		return ":" + strconv.Itoa(n) + "\r\n"
+	case "exists":
+		var n int
+		for _, key := range args[1:] {
+			if _, ok := s.keys[key]; ok {
+				n++
+			}
+		}
+		return ":" + strconv.Itoa(n) + "\r\n"
+	}
+	return "-ERR unexpected " + args[0] + "\r\n"
+}
+
+func (s *kvServer) client() *Client {
+	return s.clientWithOptions(&Options{})
+}
+
+// clientWithOptions returns a client connected to the server
+// using the other options.
+func (s *kvServer) clientWithOptions(opt *Options) *Client {
+	opt.Addr = "stub:6379"
+	opt.DisableIndentity = true
+	opt.Dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		cn, server := net.Pipe()
+		go func() {
+			rd := proto.NewReader(server)
+			for {
+				v, err := rd.ReadReply()
+				if err != nil {
+					return
+				}
+				vals, _ := v.([]interface{})
+				args := make([]string, len(vals))
+				for i, val := range vals {
+					args[i], _ = val.(string)
+				}
+				if len(args) == 0 {
+					return
+				}
+				if _, err := server.Write([]byte(s.reply(args))); err != nil {
+					return
+				}
+			}
+		}()
+		return cn, nil
+	}
+	return NewClient(opt)
+}
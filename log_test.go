@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type testLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *testLogger) log(level, msg string, keysAndValues []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, fmt.Sprint(level, " ", msg, " ", keysAndValues))
+}
+
+func (l *testLogger) Debug(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.log("DEBUG", msg, keysAndValues)
+}
+
+func (l *testLogger) Info(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.log("INFO", msg, keysAndValues)
+}
+
+func (l *testLogger) Warn(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.log("WARN", msg, keysAndValues)
+}
+
+func (l *testLogger) Error(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.log("ERROR", msg, keysAndValues)
+}
+
+func TestOptionsLogger(t *testing.T) {
+	subscribed := "*3\r\n$9\r\nsubscribe\r\n$2\r\nch\r\n:1\r\n"
+
+	logger := new(testLogger)
+	var dials int32
+	client := NewClient(&Options{
+		Addr: "stub:6379",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if atomic.AddInt32(&dials, 1) == 1 {
+				// The first connection is dropped after the subscription.
+				return &ConnStub{init: []byte(string(initHello) + subscribed)}, nil
+			}
+			return &ConnStub{init: initHello, resp: []byte(subscribed)}, nil
+		},
+		Logger:           logger,
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	pubsub := client.Subscribe(ctx, "ch")
+	defer pubsub.Close()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pubsub.Receive(ctx); err != io.EOF {
+		t.Fatalf("got %v, expected io.EOF", err)
+	}
+	if _, err := pubsub.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	expected := []string{
+		"WARN redis: discarding bad PubSub connection [addr stub:6379 err EOF]",
+	}
+	if fmt.Sprint(logger.entries) != fmt.Sprint(expected) {
+		t.Fatalf("got %q, expected %q", logger.entries, expected)
+	}
+}
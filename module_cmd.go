@@ -0,0 +1,174 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// Processor is implemented by every client type that is able to execute
+// an already constructed command: Client, ClusterClient, Ring, Conn, Tx
+// and Pipeliner.
+type Processor interface {
+	Process(ctx context.Context, cmd Cmder) error
+}
+
+var (
+	_ Processor = (*Client)(nil)
+	_ Processor = (*ClusterClient)(nil)
+	_ Processor = (*Ring)(nil)
+	_ Processor = (*Conn)(nil)
+	_ Processor = (*Tx)(nil)
+	_ Processor = (Pipeliner)(nil)
+)
+
+// ModuleCommand describes a command that is not natively supported by go-redis,
+// for example a command provided by a Redis module. The argument encoder and the
+// reply decoder are declared once and the ModuleCommand is then reused to build
+// typed commands:
+//
+//	type topkQuery struct {
+//		Key   string
+//		Items []string
+//	}
+//
+//	var topkQueryCmd = redis.NewModuleCommand("TOPK.QUERY",
+//		func(q topkQuery) []interface{} {
+//			args := []interface{}{q.Key}
+//			for _, item := range q.Items {
+//				args = append(args, item)
+//			}
+//			return args
+//		},
+//		func(reply interface{}) ([]interface{}, error) {
+//			v, _ := reply.([]interface{})
+//			return v, nil
+//		},
+//	)
+//
+//	res, err := topkQueryCmd.Run(ctx, rdb, topkQuery{Key: "topk", Items: []string{"a"}}).Result()
+//
+// ModuleCommand is safe for concurrent use by multiple goroutines.
+type ModuleCommand[A, T any] struct {
+	name   []interface{}
+	keyPos int8
+	encode func(args A) []interface{}
+	decode func(reply interface{}) (T, error)
+}
+
+// NewModuleCommand creates a ModuleCommand. The name may consist of several words,
+// e.g. "CF.RESERVE" or "CLIENT TRACKINGINFO". encode converts the arguments into
+// the command arguments that follow the name; decode converts the reply as returned
+// by the protocol reader (string, int64, float64, bool, *big.Int, []interface{},
+// map[interface{}]interface{} or nil) into the typed result. If decode is nil,
+// the reply is returned as is and a reply that is not a T is an error.
+func NewModuleCommand[A, T any](
+	name string,
+	encode func(args A) []interface{},
+	decode func(reply interface{}) (T, error),
+) *ModuleCommand[A, T] {
+	words := strings.Fields(name)
+	cmdName := make([]interface{}, len(words))
+	for i, w := range words {
+		cmdName[i] = w
+	}
+	m := &ModuleCommand[A, T]{
+		name:   cmdName,
+		encode: encode,
+		decode: decode,
+	}
+	if len(words) > 1 {
+		// The key follows the words of the name.
+		m.keyPos = int8(len(words))
+	}
+	return m
+}
+
+// WithFirstKeyPos returns a copy of the ModuleCommand whose commands have
+// the first key at the given position. It is used by ClusterClient and Ring
+// to route the command. The position counts the words of the command name,
+// and by default the first argument after the command name is the key.
+func (m *ModuleCommand[A, T]) WithFirstKeyPos(pos int8) *ModuleCommand[A, T] {
+	clone := *m
+	clone.keyPos = pos
+	return &clone
+}
+
+// Cmd builds the command without executing it.
+func (m *ModuleCommand[A, T]) Cmd(ctx context.Context, args A) *ModuleCmd[T] {
+	var cmdArgs []interface{}
+	if m.encode != nil {
+		cmdArgs = m.encode(args)
+	}
+	all := make([]interface{}, 0, len(m.name)+len(cmdArgs))
+	all = append(all, m.name...)
+	all = append(all, cmdArgs...)
+
+	cmd := &ModuleCmd[T]{
+		baseCmd: baseCmd{
+			ctx:  ctx,
+			args: all,
+		},
+		decode: m.decode,
+	}
+	if m.keyPos != 0 {
+		cmd.SetFirstKeyPos(m.keyPos)
+	}
+	return cmd
+}
+
+// Run builds the command and processes it using c.
+// When c is a Pipeliner the command is queued and its result
+// is available after the pipeline is executed.
+func (m *ModuleCommand[A, T]) Run(ctx context.Context, c Processor, args A) *ModuleCmd[T] {
+	cmd := m.Cmd(ctx, args)
+	_ = c.Process(ctx, cmd)
+	return cmd
+}
+
+//------------------------------------------------------------------------------
+
+// ModuleCmd is a command built by a ModuleCommand.
+type ModuleCmd[T any] struct {
+	baseCmd
+
+	val    T
+	decode func(reply interface{}) (T, error)
+}
+
+var _ Cmder = (*ModuleCmd[interface{}])(nil)
+
+func (cmd *ModuleCmd[T]) SetVal(val T) {
+	cmd.val = val
+}
+
+func (cmd *ModuleCmd[T]) Val() T {
+	return cmd.val
+}
+
+func (cmd *ModuleCmd[T]) Result() (T, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *ModuleCmd[T]) String() string {
+	return cmdString(cmd, cmd.val)
+}
+
+func (cmd *ModuleCmd[T]) readReply(rd *proto.Reader) error {
+	reply, err := rd.ReadReply()
+	if err != nil {
+		return err
+	}
+	if cmd.decode == nil {
+		v, ok := reply.(T)
+		if !ok {
+			return fmt.Errorf("redis: unexpected type=%T for %T", reply, cmd.val)
+		}
+		cmd.val = v
+		return nil
+	}
+	cmd.val, err = cmd.decode(reply)
+	return err
+}
@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestModuleCommand(t *testing.T) {
+	type incrArgs struct {
+		Key   string
+		Items []string
+	}
+
+	incr := NewModuleCommand("TOPK.INCRBY",
+		func(a incrArgs) []interface{} {
+			args := []interface{}{a.Key}
+			for _, item := range a.Items {
+				args = append(args, item, 1)
+			}
+			return args
+		},
+		func(reply interface{}) (int, error) {
+			items, ok := reply.([]interface{})
+			if !ok {
+				return 0, fmt.Errorf("unexpected reply: %T", reply)
+			}
+			return len(items), nil
+		},
+	)
+
+	client := NewClientStub([]byte("*2\r\n_\r\n_\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	cmd := incr.Run(ctx, client, incrArgs{Key: "topk", Items: []string{"a", "b"}})
+	n, err := cmd.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("got %d, wanted 2", n)
+	}
+
+	wanted := "TOPK.INCRBY topk a 1 b 1: 2"
+	if s := cmd.String(); s != wanted {
+		t.Fatalf("got %q, wanted %q", s, wanted)
+	}
+
+	if name := cmd.Name(); name != "topk.incrby" {
+		t.Fatalf("got %q, wanted %q", name, "topk.incrby")
+	}
+
+	withKeyPos := incr.WithFirstKeyPos(2).Cmd(ctx, incrArgs{Key: "topk"})
+	if pos := cmdFirstKeyPos(withKeyPos); pos != 2 {
+		t.Fatalf("got %d, wanted 2", pos)
+	}
+}
+
+func TestModuleCommandMultiWordName(t *testing.T) {
+	info := NewModuleCommand[string, string]("XINFO STREAM",
+		func(key string) []interface{} { return []interface{}{key} },
+		nil,
+	)
+
+	// The key follows the two words of the name.
+	cmd := info.Cmd(ctx, "stream")
+	if pos := cmdFirstKeyPos(cmd); pos != 2 {
+		t.Fatalf("got %d, wanted 2", pos)
+	}
+	if key := cmd.stringArg(cmdFirstKeyPos(cmd)); key != "stream" {
+		t.Fatalf("got key %q, wanted %q", key, "stream")
+	}
+}
+
+func TestModuleCommandNilDecode(t *testing.T) {
+	get := NewModuleCommand[string, string]("GET",
+		func(key string) []interface{} { return []interface{}{key} },
+		nil,
+	)
+
+	client := NewClientStub([]byte("$5\r\nvalue\r\n")).Cmdable.(*Client)
+	defer client.Close()
+	if val, err := get.Run(ctx, client, "key").Result(); err != nil || val != "value" {
+		t.Fatalf("got %q, %v, wanted %q", val, err, "value")
+	}
+
+	client = NewClientStub([]byte(":1\r\n")).Cmdable.(*Client)
+	defer client.Close()
+	if err := get.Run(ctx, client, "key").Err(); err == nil {
+		t.Fatal("got no error for an integer reply, wanted a type mismatch")
+	}
+}
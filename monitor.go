@@ -0,0 +1,162 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal"
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// MonitorEntry is a command executed by the server, as reported by MONITOR.
+type MonitorEntry struct {
+	Time time.Time
+	DB   int
+	// Address of the client that executed the command, "lua" for commands
+	// executed by scripts or "unix:<path>" for Unix socket clients.
+	Addr string
+	Args []string
+}
+
+func (e *MonitorEntry) String() string {
+	return fmt.Sprintf("MonitorEntry<%d %s: %s>", e.DB, e.Addr, strings.Join(e.Args, " "))
+}
+
+// MonitorStream runs MONITOR on a dedicated connection and streams the parsed
+// entries. The channel is closed when ctx is done or the connection fails;
+// the connection is closed together with the channel. Entries are not dropped,
+// so the consumer must keep up with the server to not block it.
+//
+// MONITOR has a significant performance cost on the server,
+// it is meant to be used for debugging.
+func (c *Client) MonitorStream(ctx context.Context) (<-chan *MonitorEntry, error) {
+	cn, err := c.newConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := NewStatusCmd(ctx, "monitor")
+	if err := cn.WithWriter(ctx, c.opt.WriteTimeout, func(wr *proto.Writer) error {
+		return writeCmd(wr, cmd)
+	}); err != nil {
+		_ = c.connPool.CloseConn(cn)
+		return nil, err
+	}
+	if err := cn.WithReader(ctx, c.opt.ReadTimeout, cmd.readReply); err != nil {
+		_ = c.connPool.CloseConn(cn)
+		return nil, err
+	}
+
+	ch := make(chan *MonitorEntry, 100)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		// Interrupts the read below.
+		_ = c.connPool.CloseConn(cn)
+	}()
+
+	go func() {
+		defer close(ch)
+		defer close(done)
+
+		for {
+			var line string
+			err := cn.WithReader(context.Background(), 0, func(rd *proto.Reader) error {
+				var err error
+				line, err = rd.ReadString()
+				return err
+			})
+			if err != nil {
+				if ctx.Err() == nil {
+					internal.Leveled(c.opt.Logger).Warn(ctx, "redis: MONITOR connection failed", "err", err)
+				}
+				return
+			}
+
+			entry, err := parseMonitorEntry(line)
+			if err != nil {
+				internal.Leveled(c.opt.Logger).Warn(ctx, "redis: invalid MONITOR entry", "err", err)
+				continue
+			}
+
+			select {
+			case ch <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// parseMonitorEntry parses a line like
+//
+//	1339518083.107412 [0 127.0.0.1:60866] "set" "key" "value"
+func parseMonitorEntry(line string) (*MonitorEntry, error) {
+	ts, rest, ok := strings.Cut(line, " [")
+	if !ok {
+		return nil, fmt.Errorf("can't parse MONITOR entry %q", line)
+	}
+	client, rest, ok := strings.Cut(rest, "] ")
+	if !ok {
+		return nil, fmt.Errorf("can't parse MONITOR entry %q", line)
+	}
+	db, addr, ok := strings.Cut(client, " ")
+	if !ok {
+		return nil, fmt.Errorf("can't parse MONITOR entry %q", line)
+	}
+
+	entry := &MonitorEntry{Addr: addr}
+
+	sec, usec, _ := strings.Cut(ts, ".")
+	s, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse MONITOR entry %q: %w", line, err)
+	}
+	us, _ := strconv.ParseInt(usec, 10, 64)
+	entry.Time = time.Unix(s, us*int64(time.Microsecond))
+
+	if entry.DB, err = strconv.Atoi(db); err != nil {
+		return nil, fmt.Errorf("can't parse MONITOR entry %q: %w", line, err)
+	}
+
+	// Arguments are quoted and escaped like Go string literals, e.g. "\x00".
+	for rest != "" {
+		end := quotedLen(rest)
+		if end < 0 {
+			return nil, fmt.Errorf("can't parse MONITOR entry %q", line)
+		}
+		arg, err := strconv.Unquote(rest[:end])
+		if err != nil {
+			return nil, fmt.Errorf("can't parse MONITOR entry %q: %w", line, err)
+		}
+		entry.Args = append(entry.Args, arg)
+		rest = strings.TrimPrefix(rest[end:], " ")
+	}
+
+	return entry, nil
+}
+
+// quotedLen returns the length of the quoted string s starts with, or -1.
+func quotedLen(s string) int {
+	if len(s) < 2 || s[0] != '"' {
+		return -1
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
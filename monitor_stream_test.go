@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseMonitorEntry(t *testing.T) {
+	entry, err := parseMonitorEntry(`1339518083.107412 [2 127.0.0.1:60866] "set" "key" "a \"quoted\"\x00 value"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &MonitorEntry{
+		Time: time.Unix(1339518083, 107412000),
+		DB:   2,
+		Addr: "127.0.0.1:60866",
+		Args: []string{"set", "key", "a \"quoted\"\x00 value"},
+	}
+	if !reflect.DeepEqual(entry, want) {
+		t.Fatalf("got %+v, expected %+v", entry, want)
+	}
+
+	entry, err = parseMonitorEntry(`1339518083.107412 [0 lua] "get" "key"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Addr != "lua" || len(entry.Args) != 2 {
+		t.Fatalf("got %+v", entry)
+	}
+
+	if _, err := parseMonitorEntry(`OK`); err == nil {
+		t.Fatal("got nil, expected an error")
+	}
+}
+
+func TestMonitorStream(t *testing.T) {
+	replies := "+OK\r\n" +
+		"+1339518083.107412 [0 127.0.0.1:60866] \"set\" \"a\" \"1\"\r\n" +
+		"+1339518083.107413 [0 127.0.0.1:60866] \"get\" \"a\"\r\n"
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &ConnStub{init: []byte(string(initHello) + replies)}, nil
+		},
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	ch, err := client.MonitorStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got [][]string
+	for entry := range ch {
+		got = append(got, entry.Args)
+	}
+	want := [][]string{{"set", "a", "1"}, {"get", "a"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, expected %v", got, want)
+	}
+}
+
+func TestMonitorStreamCancel(t *testing.T) {
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &silentConnStub{
+				ConnStub: ConnStub{init: []byte(string(initHello) + "+OK\r\n")},
+				closed:   make(chan struct{}),
+			}, nil
+		},
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	ch, err := client.MonitorStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("got an entry, expected the channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel is not closed after cancel")
+	}
+}
@@ -46,7 +46,9 @@ type Options struct {
 	OnConnect func(ctx context.Context, cn *Conn) error
 
 	// Protocol 2 or 3. Use the version to negotiate RESP version with redis-server.
-	// Default is 3.
+	// Default is 3, falling back to RESP2 if the server does not support the HELLO command.
+	// Setting Protocol to 3 explicitly pins RESP3: connecting to a server that
+	// rejects HELLO 3 fails instead of silently falling back to RESP2.
 	Protocol int
 	// Use the specified Username to authenticate the current connection
 	// with one of the connections defined in the ACL list when connecting
@@ -79,6 +81,19 @@ type Options struct {
 	// Maximum backoff between each retry.
 	// Default is 512 milliseconds; -1 disables backoff.
 	MaxRetryBackoff time.Duration
+	// ShouldRetry is called when a command failed with an error that
+	// is retried by default, e.g. a network error. attempt is the zero-based
+	// number of the failed attempt. Returning false stops retrying,
+	// e.g. to never retry non-idempotent commands like INCR or LPUSH.
+	// A pipeline is retried only if ShouldRetry returns true for all its commands.
+	ShouldRetry func(err error, attempt int, cmd Cmder) bool
+	// RetryBudget caps retries at a fraction of the requests. When it is exhausted,
+	// failed commands are not retried and return an error that matches
+	// ErrRetryBudgetExhausted. Default is nil, i.e. retries are not capped.
+	RetryBudget *RetryBudget
+	// Backoff before retrying commands that failed with LOADING, BUSY
+	// or CLUSTERDOWN errors. See ServerErrorBackoff for the defaults.
+	ServerErrorBackoff ServerErrorBackoff
 
 	// Dial timeout for establishing new connections.
 	// Default is 5 seconds.
@@ -99,6 +114,29 @@ type Options struct {
 	// See https://redis.uptrace.dev/guide/go-redis-debugging.html#timeouts
 	ContextTimeoutEnabled bool
 
+	// AutoPipelineWindow enables auto-pipelining: commands processed concurrently
+	// by different goroutines are transparently coalesced into pipelines. A batch
+	// is sent when AutoPipelineMaxBatch commands are queued or when the window elapses
+	// after the first command was queued. Blocking commands are never batched.
+	// A command whose context is done before its batch is sent is removed from
+	// the batch. Since a batch is shared by several commands, cancelling a command
+	// context does not abort a sent batch, but the batch is bounded by the latest
+	// deadline of its commands, or by ReadTimeout. Only the commands whose deadlines
+	// are at most the window apart are batched together; the other commands are
+	// sent on their own.
+	// Default is 0, auto-pipelining is disabled.
+	AutoPipelineWindow time.Duration
+	// Maximum number of commands in an auto-pipelining batch.
+	// Default is 100 commands.
+	AutoPipelineMaxBatch int
+
+	// MaxKeysPerCommand splits MGET, DEL, UNLINK and EXISTS commands with more
+	// keys into chunks of MaxKeysPerCommand keys, which are sent in a pipeline
+	// and whose results are merged, so a huge command does not block the server.
+	// The chunks are not executed atomically.
+	// Default is 0, the commands are not split.
+	MaxKeysPerCommand int
+
 	// Type of connection pool.
 	// true for FIFO pool, false for LIFO pool.
 	// Note that FIFO has slightly higher overhead compared to LIFO,
@@ -139,15 +177,128 @@ type Options struct {
 	// Default is to not close idle connections.
 	ConnMaxLifetime time.Duration
 
+	// MaxReplyBufferSize is the maximum size of the buffer each connection
+	// reuses to read the bulk string replies, which are then copied, so large
+	// replies don't allocate growing buffers. The buffer grown by a larger reply
+	// is released after the reply is read.
+	// Every idle connection of the pool may retain a buffer of this size, e.g.
+	// up to 40MiB with the default size and PoolSize of 10 * GOMAXPROCS
+	// on 64 CPUs.
+	// Default is 64KiB. -1 disables the buffer: every reply is read into its own
+	// slice, which is returned without a copy.
+	MaxReplyBufferSize int
+	// MaxInternedReplies enables interning of the status replies and the bulk
+	// strings of at most 64 bytes: each connection remembers up to
+	// MaxInternedReplies distinct values and returns the same string for
+	// the repeated ones instead of allocating it again. It reduces the heap churn
+	// of the workloads reading the same small values, e.g. enum-like hash fields,
+	// over and over.
+	// Default is 0, the replies are not interned.
+	MaxInternedReplies int
+	// MinReadBufferSize and MaxReadBufferSize bound the size of the read buffer
+	// of each connection, which follows the moving average of the sizes of the
+	// replies: it grows for the workloads with large replies, e.g. of sorted
+	// sets, so they are read with fewer system calls and buffer refills, and
+	// shrinks back when the replies become small, releasing the buffer reused
+	// to read large bulk strings too.
+	// Default is 4KiB and 64KiB. -1 for MaxReadBufferSize keeps the size fixed
+	// to MinReadBufferSize.
+	MinReadBufferSize int
+	MaxReadBufferSize int
+
 	// TLS Config to use. When set, TLS will be negotiated.
 	TLSConfig *tls.Config
 
 	// Limiter interface used to implement circuit breaker or rate limiter.
 	Limiter Limiter
 
+	// Logger receives the internal events of the client, e.g. failed dials,
+	// PubSub reconnects and failovers. Default logs with the global logger
+	// set by SetLogger.
+	Logger LeveledLogger
+
+	// Clock is the source of the time of the connection pool, i.e. the idle
+	// time and the lifetime of the connections, and of the retry backoffs.
+	// Default is the system clock.
+	Clock Clock
+
+	// WireDebug logs every command sent and its truncated reply together with
+	// the connection id at the Debug level of the Logger. It is meant for
+	// debugging protocol issues and can be switched with Client.SetWireDebug.
+	WireDebug bool
+
+	// CmdStatsEnabled records the pool wait, write and read timings of every
+	// command returned by Cmder.Stats, at the cost of a few clock readings
+	// per command. They are also recorded with OnSlowCommand.
+	// Default is false.
+	CmdStatsEnabled bool
+
+	// OnSlowCommand is called when a command, including retries, took longer
+	// than SlowCommandThreshold. It is called synchronously after the command
+	// completed, so it must be fast, e.g. log the command.
+	OnSlowCommand func(cmd CmdInfo, dur time.Duration)
+	// Minimum duration of a command to call OnSlowCommand.
+	// Default is 100 milliseconds.
+	SlowCommandThreshold time.Duration
+
+	// OnDial is called after a new connection is established and initialized
+	// with the durations of the dial phases, see DialStats. The sums over all
+	// connections are returned by Client.DialStats.
+	OnDial func(ctx context.Context, addr string, stats DialStats)
+
+	// AuditSampleRate is the fraction of commands, from 0 to 1, recorded
+	// without their values for Client.RecentCommands.
+	// Default is 0, which disables the recording.
+	AuditSampleRate float64
+	// Maximum number of commands kept for Client.RecentCommands.
+	// Default is 1000.
+	AuditBufferSize int
+
+	// ReadOnlyClient rejects the commands that may modify the data, e.g. SET
+	// or EVAL, with a ReadOnlyClientError before sending them. The commands
+	// are classified by the flags returned by the COMMAND command, which is
+	// sent once before the first command.
+	ReadOnlyClient bool
+
+	// Authorize is called for every command before it is sent, e.g. to forbid
+	// KEYS in production or to restrict the keys to a tenant prefix.
+	// A non-nil error is returned for the command, which is not sent.
+	// Pipelines are rejected as a whole. The commands sent by the client
+	// itself, e.g. HELLO or AUTH, are not authorized. CmdInfo.HasKeyPrefix
+	// rejects the commands whose keys can't be determined.
+	Authorize func(cmd CmdInfo) error
+
+	// Codec encodes and decodes the values of SetObject and GetObject.
+	// Default is JSONCodec. See CompressionCodec to compress large values.
+	Codec Codec
+
 	// Enables read only queries on slave/follower nodes.
 	readOnly bool
 
+	// Dispatches out-of-band RESP3 push messages, shared by all clones of the options.
+	pushRouter *pushRouter
+
+	// Server capabilities detected by the last initialized connection, shared by all clones of the options.
+	capabilities *capabilitiesHolder
+
+	// Switches wire-level debug logging, shared by all clones of the options.
+	wireDebug *wireDebug
+
+	// Counts the errors, shared by all clones of the options.
+	errStats *errStats
+
+	// Sums the dial stats, shared by all clones of the options.
+	dialStats *dialStats
+
+	// Keeps the sampled commands, shared by all clones of the options.
+	audit *auditLog
+
+	// Credentials set by ReAuthenticate, shared by all clones of the options.
+	credentials *credentialsHolder
+
+	// Command info used by ReadOnlyClient and Authorize, shared by all clones of the options.
+	cmdsInfo *cmdsInfoCache
+
 	// Disable set-lib on connect. Default is false.
 	DisableIndentity bool
 
@@ -175,6 +326,43 @@ func (opt *Options) init() {
 	if opt.Dialer == nil {
 		opt.Dialer = NewDialer(opt)
 	}
+	if opt.pushRouter == nil {
+		opt.pushRouter = newPushRouter()
+	}
+	if opt.capabilities == nil {
+		opt.capabilities = new(capabilitiesHolder)
+	}
+	if opt.wireDebug == nil {
+		opt.wireDebug = new(wireDebug)
+		opt.wireDebug.set(opt.WireDebug)
+	}
+	if opt.errStats == nil {
+		opt.errStats = new(errStats)
+	}
+	if opt.dialStats == nil {
+		opt.dialStats = new(dialStats)
+	}
+	if opt.credentials == nil {
+		opt.credentials = new(credentialsHolder)
+	}
+	if opt.AuditBufferSize == 0 {
+		opt.AuditBufferSize = 1000
+	}
+	if opt.audit == nil && opt.AuditSampleRate > 0 {
+		opt.audit = newAuditLog(opt.AuditSampleRate, opt.AuditBufferSize)
+	}
+	if opt.AutoPipelineMaxBatch == 0 {
+		opt.AutoPipelineMaxBatch = 100
+	}
+	if opt.MaxReplyBufferSize == 0 {
+		opt.MaxReplyBufferSize = 64 << 10
+	}
+	if opt.MinReadBufferSize == 0 {
+		opt.MinReadBufferSize = 4 << 10
+	}
+	if opt.MaxReadBufferSize == 0 {
+		opt.MaxReadBufferSize = 64 << 10
+	}
 	if opt.PoolSize == 0 {
 		opt.PoolSize = 10 * runtime.GOMAXPROCS(0)
 	}
@@ -222,6 +410,11 @@ func (opt *Options) init() {
 	case 0:
 		opt.MaxRetryBackoff = 512 * time.Millisecond
 	}
+	opt.ServerErrorBackoff.init()
+
+	if opt.SlowCommandThreshold == 0 {
+		opt.SlowCommandThreshold = 100 * time.Millisecond
+	}
 }
 
 func (opt *Options) clone() *Options {
@@ -237,10 +430,14 @@ func NewDialer(opt *Options) func(context.Context, string, string) (net.Conn, er
 			Timeout:   opt.DialTimeout,
 			KeepAlive: 5 * time.Minute,
 		}
-		if opt.TLSConfig == nil {
-			return netDialer.DialContext(ctx, network, addr)
+		timings := pool.ContextDialTimings(ctx)
+		if timings == nil {
+			if opt.TLSConfig == nil {
+				return netDialer.DialContext(ctx, network, addr)
+			}
+			return tls.DialWithDialer(netDialer, network, addr, opt.TLSConfig)
 		}
-		return tls.DialWithDialer(netDialer, network, addr, opt.TLSConfig)
+		return dialWithTimings(ctx, netDialer, network, addr, opt.TLSConfig, timings)
 	}
 }
 
@@ -267,6 +464,8 @@ func NewDialer(opt *Options) func(context.Context, string, string) (net.Conn, er
 //     URL attributes (scheme, host, userinfo, resp.), query parameters using these
 //     names will be treated as unknown parameters
 //   - unknown parameter names will result in an error
+//   - with the rediss scheme, "server_name" overrides the host name verified in
+//     the server certificate and "skip_verify=true" disables the verification
 //
 // Examples:
 //
@@ -346,6 +545,44 @@ func getHostPortWithDefaults(u *url.URL) (string, string) {
 	return host, port
 }
 
+// parseMultiHostURL parses a URL whose host is a comma-separated list of
+// addresses, e.g. of the cluster or the sentinel nodes. The returned URL has
+// the first address as its host. The addresses without a port get defaultPort.
+func parseMultiHostURL(rawURL, defaultPort string) (*url.URL, []string, error) {
+	authority := rawURL
+	var scheme, rest string
+	if i := strings.Index(authority, "://"); i >= 0 {
+		scheme, authority = authority[:i+3], authority[i+3:]
+	}
+	if i := strings.IndexAny(authority, "/?#"); i >= 0 {
+		authority, rest = authority[:i], authority[i:]
+	}
+	var userinfo string
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		userinfo, authority = authority[:i+1], authority[i+1:]
+	}
+
+	hosts := strings.Split(authority, ",")
+	addrs := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		hu, err := url.Parse("//" + host)
+		if err != nil || hu.Host == "" || hu.Host != host {
+			return nil, nil, fmt.Errorf("redis: invalid URL host: %q", host)
+		}
+		p := hu.Port()
+		if p == "" {
+			p = defaultPort
+		}
+		addrs = append(addrs, net.JoinHostPort(hu.Hostname(), p))
+	}
+
+	u, err := url.Parse(scheme + userinfo + hosts[0] + rest)
+	if err != nil {
+		return nil, nil, err
+	}
+	return u, addrs, nil
+}
+
 func setupUnixConn(u *url.URL) (*Options, error) {
 	o := &Options{
 		Network: "unix",
@@ -484,6 +721,9 @@ func setupConnParams(u *url.URL, o *Options) (*Options, error) {
 	} else {
 		o.ConnMaxLifetime = q.duration("max_conn_age")
 	}
+	if o.TLSConfig != nil {
+		setupTLSParams(&q, o.TLSConfig)
+	}
 	if q.err != nil {
 		return nil, q.err
 	}
@@ -496,6 +736,14 @@ func setupConnParams(u *url.URL, o *Options) (*Options, error) {
 	return o, nil
 }
 
+// setupTLSParams applies the TLS parameters of rediss URLs.
+func setupTLSParams(q *queryOptions, cfg *tls.Config) {
+	if q.has("server_name") {
+		cfg.ServerName = q.string("server_name")
+	}
+	cfg.InsecureSkipVerify = q.bool("skip_verify")
+}
+
 func getUserPassword(u *url.URL) (string, string) {
 	var user, password string
 	if u.User != nil {
@@ -523,5 +771,11 @@ func newConnPool(
 		MaxActiveConns:  opt.MaxActiveConns,
 		ConnMaxIdleTime: opt.ConnMaxIdleTime,
 		ConnMaxLifetime: opt.ConnMaxLifetime,
+		MaxReplyBuffer:  opt.MaxReplyBufferSize,
+		MaxInterned:     opt.MaxInternedReplies,
+		MinReadBuffer:   opt.MinReadBufferSize,
+		MaxReadBuffer:   opt.MaxReadBufferSize,
+		Logger:          opt.Logger,
+		Clock:           opt.Clock,
 	})
 }
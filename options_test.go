@@ -5,6 +5,7 @@ package redis
 import (
 	"crypto/tls"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -219,3 +220,85 @@ func TestReadTimeoutOptions(t *testing.T) {
 		}
 	}
 }
+
+func TestParseURLTLS(t *testing.T) {
+	o, err := ParseURL("rediss://10.0.0.1:6380/?server_name=redis.example.com&skip_verify=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.TLSConfig.ServerName != "redis.example.com" || !o.TLSConfig.InsecureSkipVerify {
+		t.Fatalf("got ServerName %q, InsecureSkipVerify %v", o.TLSConfig.ServerName, o.TLSConfig.InsecureSkipVerify)
+	}
+
+	o, err = ParseURL("rediss://redis.example.com:6380")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.TLSConfig.ServerName != "redis.example.com" || o.TLSConfig.InsecureSkipVerify {
+		t.Fatalf("got ServerName %q, InsecureSkipVerify %v", o.TLSConfig.ServerName, o.TLSConfig.InsecureSkipVerify)
+	}
+
+	// The TLS parameters require the rediss scheme.
+	if _, err := ParseURL("redis://localhost:6379/?skip_verify=true"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestParseFailoverURL(t *testing.T) {
+	cases := []struct {
+		url string
+		o   *FailoverOptions // expected value
+		err error
+	}{
+		{
+			url: "redis-sentinel://10.0.0.1,10.0.0.2:26380/mymaster",
+			o:   &FailoverOptions{MasterName: "mymaster", SentinelAddrs: []string{"10.0.0.1:26379", "10.0.0.2:26380"}},
+		}, {
+			url: "redis-sentinel://foo:bar@[::1]:26379,localhost/mymaster/2?dial_timeout=3&replica_only=true&sentinel_password=baz",
+			o: &FailoverOptions{
+				MasterName: "mymaster", SentinelAddrs: []string{"[::1]:26379", "localhost:26379"},
+				Username: "foo", Password: "bar", DB: 2,
+				DialTimeout: 3 * time.Second, ReplicaOnly: true, SentinelPassword: "baz",
+			},
+		}, {
+			url: "rediss-sentinel://localhost/mymaster?server_name=redis.example.com",
+			o: &FailoverOptions{
+				MasterName: "mymaster", SentinelAddrs: []string{"localhost:26379"},
+				TLSConfig: &tls.Config{ServerName: "redis.example.com", MinVersion: tls.VersionTLS12},
+			},
+		}, {
+			url: "redis-sentinel://localhost",
+			err: errors.New("redis: missing sentinel master name"),
+		}, {
+			url: "redis-sentinel://localhost/mymaster/db",
+			err: errors.New(`redis: invalid database number: "db"`),
+		}, {
+			url: "redis-sentinel://localhost,/mymaster",
+			err: errors.New(`redis: invalid URL host: ""`),
+		}, {
+			url: "redis-sentinel://localhost/mymaster?skip_verify=true",
+			err: errors.New("redis: unexpected option: skip_verify"),
+		}, {
+			url: "redis://localhost/mymaster",
+			err: errors.New("redis: invalid URL scheme: redis"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.url, func(t *testing.T) {
+			actual, err := ParseFailoverURL(tc.url)
+			if tc.err != nil {
+				if err == nil || err.Error() != tc.err.Error() {
+					t.Fatalf("got %v, expected %v", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, tc.o) {
+				t.Fatalf("got %+v, expected %+v", actual, tc.o)
+			}
+		})
+	}
+}
@@ -42,6 +42,32 @@ type Pipeliner interface {
 
 var _ Pipeliner = (*Pipeline)(nil)
 
+// PartialExecer is implemented by the pipelines supporting ExecPartial,
+// e.g. *Pipeline. It is not part of Pipeliner, so the callers holding
+// a Pipeliner type-assert it:
+//
+//	cmds, err := pipe.(redis.PartialExecer).ExecPartial(ctx)
+type PartialExecer interface {
+	// ExecPartial is like Exec, but only returns an error when the pipeline itself failed.
+	// Error replies are only reported by the commands they belong to.
+	ExecPartial(ctx context.Context) ([]Cmder, error)
+}
+
+var _ PartialExecer = (*Pipeline)(nil)
+
+// PipelineOptions configures automatic flushing of a pipeline, so producers
+// that queue a large number of commands don't accumulate unbounded memory.
+// When a threshold is reached, the queued commands are executed by Process
+// and only the commands queued after the last flush are returned by Exec.
+type PipelineOptions struct {
+	// Maximum number of queued commands.
+	// Default is 0, no limit.
+	MaxCommands int
+	// Maximum approximate size in bytes of the arguments of the queued commands.
+	// Default is 0, no limit.
+	MaxBuffered int
+}
+
 // Pipeline implements pipelining as described in
 // http://redis.io/topics/pipelining.
 // Please note: it is not safe for concurrent use by multiple goroutines.
@@ -51,6 +77,11 @@ type Pipeline struct {
 
 	exec pipelineExecer
 	cmds []Cmder
+
+	opt      PipelineOptions
+	buffered int
+	// The first error returned by an automatic flush.
+	flushErr error
 }
 
 func (c *Pipeline) init() {
@@ -74,15 +105,56 @@ func (c *Pipeline) Do(ctx context.Context, args ...interface{}) *Cmd {
 	return cmd
 }
 
-// Process queues the cmd for later execution.
+// Process queues the cmd for later execution. When a PipelineOptions
+// threshold is reached, the queued commands are executed and the error
+// of the first failed command is returned.
 func (c *Pipeline) Process(ctx context.Context, cmd Cmder) error {
 	c.cmds = append(c.cmds, cmd)
+
+	if c.opt.MaxBuffered > 0 {
+		c.buffered += argsSize(cmd.Args())
+	}
+	if (c.opt.MaxCommands > 0 && len(c.cmds) >= c.opt.MaxCommands) ||
+		(c.opt.MaxBuffered > 0 && c.buffered >= c.opt.MaxBuffered) {
+		return c.flush(ctx)
+	}
 	return nil
 }
 
+func (c *Pipeline) flush(ctx context.Context) error {
+	cmds := c.cmds
+	c.cmds = nil
+	c.buffered = 0
+
+	err := c.exec(ctx, cmds)
+	attributeErr(cmds, err)
+	if err != nil && c.flushErr == nil {
+		c.flushErr = err
+	}
+	return err
+}
+
+// argsSize returns the approximate number of bytes the args are encoded to.
+func argsSize(args []interface{}) int {
+	var n int
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case string:
+			n += len(arg)
+		case []byte:
+			n += len(arg)
+		default:
+			n += 8
+		}
+	}
+	return n
+}
+
 // Discard resets the pipeline and discards queued commands.
 func (c *Pipeline) Discard() {
 	c.cmds = c.cmds[:0]
+	c.buffered = 0
+	c.flushErr = nil
 }
 
 // Exec executes all previously queued commands using one
@@ -90,19 +162,62 @@ func (c *Pipeline) Discard() {
 //
 // Exec always returns list of commands and error of the first failed
 // command if any.
+//
+// When PipelineOptions are used, Exec returns the commands queued after
+// the last automatic flush, and the error also covers the automatic flushes.
 func (c *Pipeline) Exec(ctx context.Context) ([]Cmder, error) {
+	flushErr := c.flushErr
+	c.flushErr = nil
+
 	if len(c.cmds) == 0 {
-		return nil, nil
+		return nil, flushErr
 	}
 
 	cmds := c.cmds
 	c.cmds = nil
+	c.buffered = 0
+
+	err := c.exec(ctx, cmds)
+	attributeErr(cmds, err)
+	if flushErr != nil {
+		return cmds, flushErr
+	}
+	return cmds, err
+}
 
-	return cmds, c.exec(ctx, cmds)
+// ExecPartial is like Exec, but an error reply to one of the commands does not
+// fail the whole pipeline: each command carries its own error, nil for the
+// commands that succeeded, and the returned error is only set when the pipeline
+// itself failed, e.g. on a network error.
+func (c *Pipeline) ExecPartial(ctx context.Context) ([]Cmder, error) {
+	cmds, err := c.Exec(ctx)
+	if err == nil {
+		return cmds, nil
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Err(); err != nil && !isRedisError(err) {
+			return cmds, err
+		}
+	}
+	if isRedisError(err) {
+		return cmds, nil
+	}
+	return cmds, err
+}
+
+// attributeErr sets the error of a pipeline that failed before any reply was read,
+// e.g. because a connection could not be obtained, on all of its commands.
+func attributeErr(cmds []Cmder, err error) {
+	if err != nil && cmdsFirstErr(cmds) == nil {
+		setCmdsErr(cmds, err)
+	}
 }
 
+// Pipelined queues the commands using fn and executes them. If fn returns
+// an error, the queued commands are discarded and not executed.
 func (c *Pipeline) Pipelined(ctx context.Context, fn func(Pipeliner) error) ([]Cmder, error) {
 	if err := fn(c); err != nil {
+		c.Discard()
 		return nil, err
 	}
 	return c.Exec(ctx)
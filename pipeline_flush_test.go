@@ -0,0 +1,191 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+)
+
+func newCountingClient(writes *int32) *Client {
+	return NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &countingConnStub{
+				ConnStub: ConnStub{
+					init: initHello,
+					resp: []byte("+OK\r\n"),
+				},
+				writes: writes,
+			}, nil
+		},
+		DisableIndentity: true,
+	})
+}
+
+func TestPipelineMaxCommands(t *testing.T) {
+	var writes int32
+	client := newCountingClient(&writes)
+	defer client.Close()
+
+	pipe := client.PipelineWithOptions(PipelineOptions{MaxCommands: 3})
+	for i := 0; i < 7; i++ {
+		if err := pipe.Set(ctx, "key", "value", 0).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := pipe.Len(); n != 1 {
+		t.Fatalf("got %d queued commands, expected 1", n)
+	}
+
+	cmds, err := pipe.Exec(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cmds) != 1 {
+		t.Fatalf("got %d commands, expected 1", len(cmds))
+	}
+	// HELLO, 2 automatic flushes and Exec.
+	if n := atomic.LoadInt32(&writes); n != 4 {
+		t.Fatalf("got %d writes, expected 4", n)
+	}
+}
+
+func TestPipelineMaxBuffered(t *testing.T) {
+	var writes int32
+	client := newCountingClient(&writes)
+	defer client.Close()
+
+	pipe := client.PipelineWithOptions(PipelineOptions{MaxBuffered: 100})
+	pipe.Set(ctx, "key", string(make([]byte, 60)), 0)
+	if n := pipe.Len(); n != 1 {
+		t.Fatalf("got %d queued commands, expected 1", n)
+	}
+	pipe.Set(ctx, "key", string(make([]byte, 60)), 0)
+	if n := pipe.Len(); n != 0 {
+		t.Fatalf("got %d queued commands, expected 0", n)
+	}
+
+	cmds, err := pipe.Exec(ctx)
+	if err != nil || cmds != nil {
+		t.Fatalf("got %v, %v, expected nothing to execute", cmds, err)
+	}
+}
+
+func TestPipelinedDiscardsOnError(t *testing.T) {
+	var writes int32
+	client := newCountingClient(&writes)
+	defer client.Close()
+
+	pipe := client.Pipeline()
+	fnErr := errors.New("fn failed")
+	_, err := pipe.Pipelined(ctx, func(pipe Pipeliner) error {
+		pipe.Set(ctx, "key", "value", 0)
+		return fnErr
+	})
+	if err != fnErr {
+		t.Fatalf("got %v, expected %v", err, fnErr)
+	}
+	if n := pipe.Len(); n != 0 {
+		t.Fatalf("got %d queued commands, expected 0", n)
+	}
+	if n := atomic.LoadInt32(&writes); n != 0 {
+		t.Fatalf("got %d writes, expected 0", n)
+	}
+}
+
+func TestPipelineDiscardResetsFlushErr(t *testing.T) {
+	dialErr := errors.New("dial failed")
+	var dials int32
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if atomic.AddInt32(&dials, 1) == 1 {
+				return nil, dialErr
+			}
+			return &ConnStub{init: initHello, resp: []byte("+OK\r\n")}, nil
+		},
+		MaxRetries:       -1,
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	pipe := client.PipelineWithOptions(PipelineOptions{MaxCommands: 1})
+	if err := pipe.Set(ctx, "key", "value", 0).Err(); err != dialErr {
+		t.Fatalf("got %v, expected %v", err, dialErr)
+	}
+	pipe.Discard()
+
+	pipe.Get(ctx, "key")
+	if _, err := pipe.Exec(ctx); err != nil {
+		t.Fatalf("got %v, expected the flush error to be discarded", err)
+	}
+}
+
+func TestPipelineExecPartial(t *testing.T) {
+	client := NewClientStub([]byte("+OK\r\n-ERR wrong value\r\n+OK\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	pipe := client.Pipeline()
+	set1 := pipe.Set(ctx, "key1", "value", 0)
+	set2 := pipe.Set(ctx, "key2", "value", 0)
+	set3 := pipe.Set(ctx, "key3", "value", 0)
+
+	cmds, err := pipe.(PartialExecer).ExecPartial(ctx)
+	if err != nil {
+		t.Fatalf("got %v, expected nil", err)
+	}
+	if len(cmds) != 3 {
+		t.Fatalf("got %d commands, expected 3", len(cmds))
+	}
+	if set1.Err() != nil || set3.Err() != nil {
+		t.Fatalf("got %v and %v, expected nil", set1.Err(), set3.Err())
+	}
+	if err := set2.Err(); err == nil || err.Error() != "ERR wrong value" {
+		t.Fatalf("got %v, expected ERR wrong value", err)
+	}
+}
+
+func TestPipelineDialErrAttribution(t *testing.T) {
+	dialErr := errors.New("dial failed")
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, dialErr
+		},
+		MaxRetries: -1,
+	})
+	defer client.Close()
+
+	pipe := client.Pipeline()
+	get := pipe.Get(ctx, "key")
+	if _, err := pipe.(PartialExecer).ExecPartial(ctx); err != dialErr {
+		t.Fatalf("got %v, expected %v", err, dialErr)
+	}
+	if err := get.Err(); err != dialErr {
+		t.Fatalf("got %v, expected %v", err, dialErr)
+	}
+}
+
+func TestTxPipelineResults(t *testing.T) {
+	client := NewClientStub([]byte("+OK\r\n+QUEUED\r\n+QUEUED\r\n*2\r\n:1\r\n+OK\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	var incr *IntCmd
+	var set *StatusCmd
+	cmds, err := client.TxPipelined(ctx, func(pipe Pipeliner) error {
+		incr = pipe.Incr(ctx, "counter")
+		set = pipe.Set(ctx, "index", "value", 0)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cmds) != 2 {
+		t.Fatalf("got %d commands, expected 2", len(cmds))
+	}
+	if n, err := incr.Result(); err != nil || n != 1 {
+		t.Fatalf("got %d, %v, expected 1", n, err)
+	}
+	if s, err := set.Result(); err != nil || s != "OK" {
+		t.Fatalf("got %q, %v, expected OK", s, err)
+	}
+}
@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+)
+
+func newHelloErrClient(protocol int) *Client {
+	return newHelloReplyClient(protocol, "-ERR unknown command 'HELLO'\r\n")
+}
+
+func newHelloReplyClient(protocol int, hello string) *Client {
+	return NewClient(&Options{
+		Protocol: protocol,
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &ConnStub{
+				init: []byte(hello),
+				resp: []byte("+PONG\r\n"),
+			}, nil
+		},
+		DisableIndentity: true,
+	})
+}
+
+func TestProtocolFallback(t *testing.T) {
+	client := newHelloErrClient(0)
+	defer client.Close()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatalf("got %v, expected RESP2 fallback", err)
+	}
+}
+
+func TestProtocolPinnedRESP3(t *testing.T) {
+	client := newHelloErrClient(3)
+	defer client.Close()
+
+	err := client.Ping(ctx).Err()
+	if err == nil {
+		t.Fatal("got nil, expected an error")
+	}
+	if !strings.Contains(err.Error(), "RESP3 is required") {
+		t.Fatalf("got %q, expected RESP3 error", err)
+	}
+	if !HasErrorPrefix(err, "unknown command") {
+		t.Fatalf("got %q, expected the HELLO error to be wrapped", err)
+	}
+}
+
+func TestProtocolPinnedRESP3AuthError(t *testing.T) {
+	client := newHelloReplyClient(3, "-WRONGPASS invalid username-password pair or user is disabled.\r\n")
+	defer client.Close()
+
+	err := client.Ping(ctx).Err()
+	if err == nil || !strings.HasPrefix(err.Error(), "WRONGPASS ") {
+		t.Fatalf("got %v, expected WRONGPASS error", err)
+	}
+}
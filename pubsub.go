@@ -2,9 +2,13 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9/internal"
@@ -14,11 +18,16 @@ import (
 
 // PubSub implements Pub/Sub commands as described in
 // http://redis.io/topics/pubsub. Message receiving is NOT safe
-// for concurrent use by multiple goroutines.
+// for concurrent use by multiple goroutines, but subscriptions can be
+// changed and pings sent by other goroutines while a goroutine is
+// blocked receiving messages.
 //
 // PubSub automatically reconnects to Redis Server and resubscribes
-// to the channels in case of network errors.
+// to the channels in case of network errors. Use OnReconnect to be
+// notified about reconnections.
 type PubSub struct {
+	dropped uint64 // atomic, first for 64-bit alignment
+
 	opt *Options
 
 	newConn   func(ctx context.Context, channels []string) (*pool.Conn, error)
@@ -30,8 +39,18 @@ type PubSub struct {
 	patterns  map[string]struct{}
 	schannels map[string]struct{}
 
-	closed bool
-	exit   chan struct{}
+	closed   bool
+	closeErr error
+	exit     chan struct{}
+	onClose  func()
+
+	// Subscriptions confirmed by the server on the current connection.
+	confirmed confirmedSubscriptions
+
+	// The error that caused the last connection to be discarded,
+	// reported to onReconnect once a new connection is established.
+	lostErr     error
+	onReconnect func(*Reconnect)
 
 	cmd *Cmd
 
@@ -45,22 +64,18 @@ func (c *PubSub) init() {
 }
 
 func (c *PubSub) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	channels := mapKeys(c.channels)
 	channels = append(channels, mapKeys(c.patterns)...)
 	channels = append(channels, mapKeys(c.schannels)...)
 	return fmt.Sprintf("PubSub(%s)", strings.Join(channels, ", "))
 }
 
-func (c *PubSub) connWithLock(ctx context.Context) (*pool.Conn, error) {
-	c.mu.Lock()
-	cn, err := c.conn(ctx, nil)
-	c.mu.Unlock()
-	return cn, err
-}
-
 func (c *PubSub) conn(ctx context.Context, newChannels []string) (*pool.Conn, error) {
 	if c.closed {
-		return nil, pool.ErrClosed
+		return nil, c.closeErr
 	}
 	if c.cn != nil {
 		return c.cn, nil
@@ -80,9 +95,46 @@ func (c *PubSub) conn(ctx context.Context, newChannels []string) (*pool.Conn, er
 	}
 
 	c.cn = cn
+
+	if c.lostErr != nil {
+		if c.onReconnect != nil {
+			// The handler may use the PubSub, so don't call it with the lock held.
+			go c.onReconnect(&Reconnect{
+				Err:       c.lostErr,
+				Channels:  mapKeys(c.channels),
+				Patterns:  mapKeys(c.patterns),
+				SChannels: mapKeys(c.schannels),
+			})
+		}
+		c.lostErr = nil
+	}
 	return cn, nil
 }
 
+// Reconnect is passed to the OnReconnect handler after PubSub has replaced
+// a broken connection and resubscribed to the channels and patterns.
+type Reconnect struct {
+	// The error that caused the previous connection to be discarded.
+	Err error
+	// Channels, patterns and shard channels the new connection is subscribed to.
+	Channels  []string
+	Patterns  []string
+	SChannels []string
+}
+
+func (r *Reconnect) String() string {
+	return fmt.Sprintf("Reconnect<%s>", r.Err)
+}
+
+// OnReconnect sets the handler that is called in a separate goroutine
+// every time PubSub has reconnected after a network error,
+// e.g. to reload the state that could be missed while disconnected.
+func (c *PubSub) OnReconnect(fn func(*Reconnect)) {
+	c.mu.Lock()
+	c.onReconnect = fn
+	c.mu.Unlock()
+}
+
 func (c *PubSub) writeCmd(ctx context.Context, cn *pool.Conn, cmd Cmder) error {
 	return cn.WithWriter(ctx, c.opt.WriteTimeout, func(wr *proto.Writer) error {
 		return writeCmd(wr, cmd)
@@ -165,14 +217,23 @@ func (c *PubSub) closeTheCn(reason error) error {
 		return nil
 	}
 	if !c.closed {
-		internal.Logger.Printf(c.getContext(), "redis: discarding bad PubSub connection: %s", reason)
+		internal.Leveled(c.opt.Logger).Warn(c.getContext(), "redis: discarding bad PubSub connection",
+			"addr", c.opt.Addr, "err", reason)
+		c.lostErr = reason
 	}
 	err := c.closeConn(c.cn)
 	c.cn = nil
+	// The new connection has to confirm the subscriptions again.
+	c.confirmed = confirmedSubscriptions{}
 	return err
 }
 
 func (c *PubSub) Close() error {
+	return c.close(pool.ErrClosed)
+}
+
+// close closes the PubSub, making the receivers return err.
+func (c *PubSub) close(err error) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -180,9 +241,13 @@ func (c *PubSub) Close() error {
 		return pool.ErrClosed
 	}
 	c.closed = true
+	c.closeErr = err
 	close(c.exit)
+	if c.onClose != nil {
+		c.onClose()
+	}
 
-	return c.closeTheCn(pool.ErrClosed)
+	return c.closeTheCn(err)
 }
 
 // Subscribe the client to the specified channels. It returns
@@ -326,6 +391,12 @@ func (c *PubSub) Ping(ctx context.Context, payload ...string) error {
 	return err
 }
 
+// Dropped returns the number of messages dropped by Channel or
+// ChannelWithSubscriptions because the consumer fell behind.
+func (c *PubSub) Dropped() uint64 {
+	return atomic.LoadUint64(&c.dropped)
+}
+
 // Subscription received after a successful subscription to channel.
 type Subscription struct {
 	// Can be "subscribe", "unsubscribe", "psubscribe" or "punsubscribe".
@@ -352,6 +423,45 @@ func (m *Message) String() string {
 	return fmt.Sprintf("Message<%s: %s>", m.Channel, m.Payload)
 }
 
+// KeyspaceEvent is a keyspace notification parsed from a Message,
+// see https://redis.io/docs/manual/keyspace-notifications/.
+type KeyspaceEvent struct {
+	DB    int
+	Key   string
+	Event string
+}
+
+// KeyspaceEvent parses a message received on a "__keyspace@<db>__:<key>"
+// or "__keyevent@<db>__:<event>" channel. It reports false if the message
+// is not a keyspace notification.
+func (m *Message) KeyspaceEvent() (*KeyspaceEvent, bool) {
+	var keyspace bool
+	var rest string
+	switch {
+	case strings.HasPrefix(m.Channel, "__keyspace@"):
+		keyspace = true
+		rest = m.Channel[len("__keyspace@"):]
+	case strings.HasPrefix(m.Channel, "__keyevent@"):
+		rest = m.Channel[len("__keyevent@"):]
+	default:
+		return nil, false
+	}
+
+	db, name, ok := strings.Cut(rest, "__:")
+	if !ok {
+		return nil, false
+	}
+	n, err := strconv.Atoi(db)
+	if err != nil {
+		return nil, false
+	}
+
+	if keyspace {
+		return &KeyspaceEvent{DB: n, Key: name, Event: m.Payload}, true
+	}
+	return &KeyspaceEvent{DB: n, Key: m.Payload, Event: name}, true
+}
+
 // Pong received as result of a PING command issued by another client.
 type Pong struct {
 	Payload string
@@ -421,28 +531,156 @@ func (c *PubSub) newMessage(reply interface{}) (interface{}, error) {
 // is not received in time. This is low-level API and in most cases
 // Channel should be used instead.
 func (c *PubSub) ReceiveTimeout(ctx context.Context, timeout time.Duration) (interface{}, error) {
+	return c.receive(ctx, timeout, false)
+}
+
+// ReceiveContext acts like Receive, but returns ctx.Err() as soon as
+// the context is canceled or its deadline is exceeded before a message
+// is received. The subscriptions are kept, so ReceiveContext can be called
+// again with another context. This is low-level API and in most cases
+// Channel should be used instead.
+func (c *PubSub) ReceiveContext(ctx context.Context) (interface{}, error) {
+	return c.receive(ctx, 0, true)
+}
+
+func (c *PubSub) receive(ctx context.Context, timeout time.Duration, withContext bool) (interface{}, error) {
+	c.mu.Lock()
 	if c.cmd == nil {
 		c.cmd = NewCmd(ctx)
 	}
-
-	// Don't hold the lock to allow subscriptions and pings.
-
-	cn, err := c.connWithLock(ctx)
+	cmd := c.cmd
+	cn, err := c.conn(ctx, nil)
+	c.mu.Unlock()
 	if err != nil {
 		return nil, err
 	}
 
-	err = cn.WithReader(context.Background(), timeout, func(rd *proto.Reader) error {
-		return c.cmd.readReply(rd)
+	readCtx := context.Background()
+	var stopWatching func()
+	if withContext {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		readCtx = ctx
+
+		// Interrupt the blocked read when the context is canceled.
+		done := make(chan struct{})
+		exited := make(chan struct{})
+		go func() {
+			defer close(exited)
+			select {
+			case <-ctx.Done():
+				_ = cn.SetReadDeadline(time.Now())
+			case <-done:
+			}
+		}()
+		stopWatching = func() {
+			close(done)
+			<-exited
+		}
+	}
+
+	// Don't hold the lock while reading to allow subscriptions and pings.
+
+	err = cn.WithReader(readCtx, timeout, func(rd *proto.Reader) error {
+		return cmd.readReply(rd)
 	})
 
+	if stopWatching != nil {
+		// Wait for the watcher, so it does not set the deadline of the next read,
+		// and clear the deadline it may have set after the read returned.
+		stopWatching()
+		_ = cn.SetReadDeadline(time.Time{})
+	}
+
+	if withContext && err != nil {
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			// The read was interrupted by the context, the connection is still usable.
+			c.releaseConnWithLock(ctx, cn, err, true)
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			// The read deadline can expire slightly before the context.
+			return nil, context.DeadlineExceeded
+		}
+	}
+
 	c.releaseConnWithLock(ctx, cn, err, timeout > 0)
 
 	if err != nil {
+		c.mu.Lock()
+		if c.closed {
+			// The blocked read was interrupted by Close.
+			err = c.closeErr
+		}
+		c.mu.Unlock()
 		return nil, err
 	}
 
-	return c.newMessage(c.cmd.Val())
+	msg, err := c.newMessage(cmd.Val())
+	if sub, ok := msg.(*Subscription); ok {
+		c.mu.Lock()
+		c.confirmed.update(sub)
+		c.mu.Unlock()
+	}
+	return msg, err
+}
+
+// Subscriptions describes the subscriptions confirmed by the server.
+type Subscriptions struct {
+	Channels  []string
+	Patterns  []string
+	SChannels []string
+	// Count is the number of subscriptions reported by the last confirmation.
+	Count int
+}
+
+type confirmedSubscriptions struct {
+	channels, patterns, schannels map[string]struct{}
+	count                         int
+}
+
+func (s *confirmedSubscriptions) update(sub *Subscription) {
+	s.count = sub.Count
+	switch sub.Kind {
+	case "subscribe":
+		s.channels = addKey(s.channels, sub.Channel)
+	case "psubscribe":
+		s.patterns = addKey(s.patterns, sub.Channel)
+	case "ssubscribe":
+		s.schannels = addKey(s.schannels, sub.Channel)
+	case "unsubscribe":
+		delete(s.channels, sub.Channel)
+	case "punsubscribe":
+		delete(s.patterns, sub.Channel)
+	case "sunsubscribe":
+		delete(s.schannels, sub.Channel)
+	}
+}
+
+func addKey(m map[string]struct{}, key string) map[string]struct{} {
+	if m == nil {
+		m = make(map[string]struct{})
+	}
+	m[key] = struct{}{}
+	return m
+}
+
+// Subscriptions returns the channels, patterns and shard channels whose
+// subscription was confirmed by the server on the current connection.
+// Confirmations are tracked while messages are received, e.g. using Receive
+// or Channel, so a subscription that is requested but not confirmed yet
+// is not included. It helps to diagnose why messages are not received.
+func (c *PubSub) Subscriptions() *Subscriptions {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return &Subscriptions{
+		Channels:  mapKeys(c.confirmed.channels),
+		Patterns:  mapKeys(c.confirmed.patterns),
+		SChannels: mapKeys(c.confirmed.schannels),
+		Count:     c.confirmed.count,
+	}
 }
 
 // Receive returns a message as a Subscription, Message, Pong or error.
@@ -491,7 +729,8 @@ func (c *PubSub) getContext() context.Context {
 // Receive* APIs can not be used after channel is created.
 //
 // go-redis periodically sends ping messages to test connection health
-// and re-subscribes if ping can not received for 1 minute.
+// and reconnects and re-subscribes if the ping is not answered within
+// the health check interval.
 func (c *PubSub) Channel(opts ...ChannelOption) <-chan *Message {
 	c.chOnce.Do(func() {
 		c.msgCh = newChannel(c, opts...)
@@ -529,6 +768,15 @@ func (c *PubSub) ChannelWithSubscriptions(opts ...ChannelOption) <-chan interfac
 	return c.allCh.allCh
 }
 
+// pubSubBackoff returns the delay before receiving again after errCount
+// consecutive errors, e.g. while the server is unreachable.
+func pubSubBackoff(errCount int) time.Duration {
+	if errCount > 10 {
+		errCount = 10
+	}
+	return internal.RetryBackoff(errCount-1, 100*time.Millisecond, 5*time.Second)
+}
+
 type ChannelOption func(c *channel)
 
 // WithChannelSize specifies the Go chan size that is used to buffer incoming messages.
@@ -541,7 +789,8 @@ func WithChannelSize(size int) ChannelOption {
 }
 
 // WithChannelHealthCheckInterval specifies the health check interval.
-// PubSub will ping Redis Server if it does not receive any messages within the interval.
+// PubSub will ping Redis Server if it does not receive any messages within the interval,
+// and reconnect if nothing is received within the interval after the ping.
 // To disable health check, use zero interval.
 //
 // The default is 3 seconds.
@@ -561,6 +810,33 @@ func WithChannelSendTimeout(d time.Duration) ChannelOption {
 	}
 }
 
+// ChannelPolicy defines what Channel does when the Go channel is full,
+// i.e. when the consumer falls behind.
+type ChannelPolicy int
+
+const (
+	// ChannelDropAfterTimeout waits for the send timeout and then drops the message.
+	// It is the default policy.
+	ChannelDropAfterTimeout ChannelPolicy = iota
+	// ChannelBlock waits until the consumer receives the message or the PubSub is closed.
+	// While blocked, messages are not read from the connection and the health check
+	// is suspended.
+	ChannelBlock
+	// ChannelDropNewest drops the received message.
+	ChannelDropNewest
+	// ChannelDropOldest drops the oldest buffered message to make room for the received one.
+	ChannelDropOldest
+)
+
+// WithChannelPolicy specifies what to do when the Go channel is full.
+//
+// The default is ChannelDropAfterTimeout.
+func WithChannelPolicy(policy ChannelPolicy) ChannelOption {
+	return func(c *channel) {
+		c.policy = policy
+	}
+}
+
 type channel struct {
 	pubSub *PubSub
 
@@ -571,6 +847,10 @@ type channel struct {
 	chanSize        int
 	chanSendTimeout time.Duration
 	checkInterval   time.Duration
+	policy          ChannelPolicy
+
+	// Set while a message waits for the consumer, so replies are not read.
+	delivering int32
 }
 
 func newChannel(pubSub *PubSub, opts ...ChannelOption) *channel {
@@ -590,6 +870,8 @@ func newChannel(pubSub *PubSub, opts ...ChannelOption) *channel {
 	return c
 }
 
+var errPubSubHealthCheck = errors.New("redis: PubSub health check ping is not answered")
+
 func (c *channel) initHealthCheck() {
 	ctx := context.TODO()
 	c.ping = make(chan struct{}, 1)
@@ -598,18 +880,37 @@ func (c *channel) initHealthCheck() {
 		timer := time.NewTimer(time.Minute)
 		timer.Stop()
 
+		// Whether a ping was sent and nothing was received since then.
+		var pinged bool
 		for {
 			timer.Reset(c.checkInterval)
 			select {
 			case <-c.ping:
+				pinged = false
 				if !timer.Stop() {
 					<-timer.C
 				}
 			case <-timer.C:
+				if atomic.LoadInt32(&c.delivering) == 1 {
+					// The ping can't be answered while the consumer is slow.
+					pinged = false
+					continue
+				}
+				if pinged {
+					// The connection is probably half-open, e.g. dropped by a NAT gateway:
+					// writes succeed, but nothing is received.
+					pinged = false
+					c.pubSub.mu.Lock()
+					c.pubSub.reconnect(ctx, errPubSubHealthCheck)
+					c.pubSub.mu.Unlock()
+					continue
+				}
 				if pingErr := c.pubSub.Ping(ctx); pingErr != nil {
 					c.pubSub.mu.Lock()
 					c.pubSub.reconnect(ctx, pingErr)
 					c.pubSub.mu.Unlock()
+				} else {
+					pinged = true
 				}
 			case <-c.pubSub.exit:
 				return
@@ -618,6 +919,58 @@ func (c *channel) initHealthCheck() {
 	}()
 }
 
+// deliver sends the message to ch according to the channel policy.
+func deliver[T any](ctx context.Context, c *channel, ch chan T, msg T, timer *time.Timer) {
+	switch c.policy {
+	case ChannelBlock:
+		select {
+		case ch <- msg:
+			return
+		default:
+		}
+		atomic.StoreInt32(&c.delivering, 1)
+		defer atomic.StoreInt32(&c.delivering, 0)
+		select {
+		case ch <- msg:
+		case <-c.pubSub.exit:
+		}
+	case ChannelDropNewest:
+		select {
+		case ch <- msg:
+		default:
+			atomic.AddUint64(&c.pubSub.dropped, 1)
+		}
+	case ChannelDropOldest:
+		for {
+			select {
+			case ch <- msg:
+				return
+			default:
+			}
+			select {
+			case <-ch:
+				atomic.AddUint64(&c.pubSub.dropped, 1)
+			default:
+			}
+		}
+	default:
+		atomic.StoreInt32(&c.delivering, 1)
+		defer atomic.StoreInt32(&c.delivering, 0)
+		timer.Reset(c.chanSendTimeout)
+		select {
+		case ch <- msg:
+			if !timer.Stop() {
+				<-timer.C
+			}
+		case <-timer.C:
+			atomic.AddUint64(&c.pubSub.dropped, 1)
+			internal.Leveled(c.pubSub.opt.Logger).Warn(
+				ctx, "redis: channel is full (message is dropped)",
+				"pubsub", c, "timeout", c.chanSendTimeout)
+		}
+	}
+}
+
 // initMsgChan must be in sync with initAllChan.
 func (c *channel) initMsgChan() {
 	ctx := context.TODO()
@@ -631,12 +984,12 @@ func (c *channel) initMsgChan() {
 		for {
 			msg, err := c.pubSub.Receive(ctx)
 			if err != nil {
-				if err == pool.ErrClosed {
+				if err == pool.ErrClosed || err == ErrShutdown {
 					close(c.msgCh)
 					return
 				}
 				if errCount > 0 {
-					time.Sleep(100 * time.Millisecond)
+					time.Sleep(pubSubBackoff(errCount))
 				}
 				errCount++
 				continue
@@ -656,19 +1009,10 @@ func (c *channel) initMsgChan() {
 			case *Pong:
 				// Ignore.
 			case *Message:
-				timer.Reset(c.chanSendTimeout)
-				select {
-				case c.msgCh <- msg:
-					if !timer.Stop() {
-						<-timer.C
-					}
-				case <-timer.C:
-					internal.Logger.Printf(
-						ctx, "redis: %s channel is full for %s (message is dropped)",
-						c, c.chanSendTimeout)
-				}
+				deliver(ctx, c, c.msgCh, msg, timer)
 			default:
-				internal.Logger.Printf(ctx, "redis: unknown message type: %T", msg)
+				internal.Leveled(c.pubSub.opt.Logger).Error(ctx, "redis: unknown message type",
+					"type", fmt.Sprintf("%T", msg))
 			}
 		}
 	}()
@@ -687,12 +1031,12 @@ func (c *channel) initAllChan() {
 		for {
 			msg, err := c.pubSub.Receive(ctx)
 			if err != nil {
-				if err == pool.ErrClosed {
+				if err == pool.ErrClosed || err == ErrShutdown {
 					close(c.allCh)
 					return
 				}
 				if errCount > 0 {
-					time.Sleep(100 * time.Millisecond)
+					time.Sleep(pubSubBackoff(errCount))
 				}
 				errCount++
 				continue
@@ -710,19 +1054,10 @@ func (c *channel) initAllChan() {
 			case *Pong:
 				// Ignore.
 			case *Subscription, *Message:
-				timer.Reset(c.chanSendTimeout)
-				select {
-				case c.allCh <- msg:
-					if !timer.Stop() {
-						<-timer.C
-					}
-				case <-timer.C:
-					internal.Logger.Printf(
-						ctx, "redis: %s channel is full for %s (message is dropped)",
-						c, c.chanSendTimeout)
-				}
+				deliver(ctx, c, c.allCh, msg, timer)
 			default:
-				internal.Logger.Printf(ctx, "redis: unknown message type: %T", msg)
+				internal.Leveled(c.pubSub.opt.Logger).Error(ctx, "redis: unknown message type",
+					"type", fmt.Sprintf("%T", msg))
 			}
 		}
 	}()
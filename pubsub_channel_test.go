@@ -0,0 +1,254 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newMessagesClient(n int) *Client {
+	var msgs string
+	for i := 0; i < n; i++ {
+		payload := fmt.Sprintf("m%d", i)
+		msgs += fmt.Sprintf("*3\r\n$7\r\nmessage\r\n$2\r\nch\r\n$%d\r\n%s\r\n", len(payload), payload)
+	}
+
+	var dials int32
+	return NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if atomic.AddInt32(&dials, 1) == 1 {
+				return &ConnStub{init: []byte(string(initHello) + msgs)}, nil
+			}
+			return &ConnStub{init: initHello}, nil
+		},
+		DisableIndentity: true,
+	})
+}
+
+func TestPubSubChannelBlock(t *testing.T) {
+	client := newMessagesClient(5)
+	defer client.Close()
+
+	pubsub := client.Subscribe(ctx, "ch")
+	ch := pubsub.Channel(
+		WithChannelSize(1),
+		WithChannelSendTimeout(time.Millisecond),
+		WithChannelHealthCheckInterval(0),
+		WithChannelPolicy(ChannelBlock),
+	)
+
+	// Let the consumer fall behind.
+	time.Sleep(50 * time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		select {
+		case msg := <-ch:
+			if want := fmt.Sprintf("m%d", i); msg.Payload != want {
+				t.Fatalf("got %q, expected %q", msg.Payload, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("message %d is not received", i)
+		}
+	}
+
+	if err := pubsub.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("got a message, expected the channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel is not closed")
+	}
+}
+
+func TestPubSubChannelBlockHealthCheck(t *testing.T) {
+	client := newMessagesClient(5)
+	defer client.Close()
+
+	pubsub := client.Subscribe(ctx, "ch")
+	defer pubsub.Close()
+
+	var healthCheckErrs int32
+	pubsub.OnReconnect(func(r *Reconnect) {
+		if r.Err == errPubSubHealthCheck {
+			atomic.AddInt32(&healthCheckErrs, 1)
+		}
+	})
+	ch := pubsub.Channel(
+		WithChannelSize(1),
+		WithChannelHealthCheckInterval(10*time.Millisecond),
+		WithChannelPolicy(ChannelBlock),
+	)
+
+	// The slow consumer blocks the delivery for several health check intervals.
+	time.Sleep(100 * time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		select {
+		case msg := <-ch:
+			if want := fmt.Sprintf("m%d", i); msg.Payload != want {
+				t.Fatalf("got %q, expected %q", msg.Payload, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("message %d is not received", i)
+		}
+	}
+	if n := atomic.LoadInt32(&healthCheckErrs); n != 0 {
+		t.Fatalf("got %d health check reconnects, expected none", n)
+	}
+}
+
+// silentConnStub answers HELLO and then never sends anything, like a half-open connection.
+type silentConnStub struct {
+	ConnStub
+	closed chan struct{}
+}
+
+func (c *silentConnStub) Read(b []byte) (int, error) {
+	if len(c.init) > 0 {
+		return c.ConnStub.Read(b)
+	}
+	<-c.closed
+	return 0, net.ErrClosed
+}
+
+func (c *silentConnStub) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestPubSubChannelHealthCheck(t *testing.T) {
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &silentConnStub{
+				ConnStub: ConnStub{init: initHello},
+				closed:   make(chan struct{}),
+			}, nil
+		},
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	pubsub := client.Subscribe(ctx, "ch")
+	defer pubsub.Close()
+
+	events := make(chan *Reconnect, 1)
+	pubsub.OnReconnect(func(r *Reconnect) {
+		select {
+		case events <- r:
+		default:
+		}
+	})
+	_ = pubsub.Channel(WithChannelHealthCheckInterval(20 * time.Millisecond))
+
+	select {
+	case r := <-events:
+		if r.Err != errPubSubHealthCheck {
+			t.Fatalf("got %v, expected errPubSubHealthCheck", r.Err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("unanswered ping did not trigger a reconnect")
+	}
+}
+
+func TestMessageKeyspaceEvent(t *testing.T) {
+	tests := []struct {
+		msg  Message
+		want *KeyspaceEvent
+	}{
+		{Message{Channel: "__keyspace@0__:user:1", Payload: "set"}, &KeyspaceEvent{DB: 0, Key: "user:1", Event: "set"}},
+		{Message{Channel: "__keyevent@3__:expired", Payload: "session:a"}, &KeyspaceEvent{DB: 3, Key: "session:a", Event: "expired"}},
+		{Message{Channel: "__keyspace@0__:a__:b", Payload: "del"}, &KeyspaceEvent{DB: 0, Key: "a__:b", Event: "del"}},
+		{Message{Channel: "news", Payload: "hello"}, nil},
+		{Message{Channel: "__keyspace@x__:key", Payload: "set"}, nil},
+	}
+	for _, test := range tests {
+		got, ok := test.msg.KeyspaceEvent()
+		if test.want == nil {
+			if ok {
+				t.Fatalf("%s: got %+v, expected no event", test.msg.Channel, got)
+			}
+			continue
+		}
+		if !ok || *got != *test.want {
+			t.Fatalf("%s: got %+v, expected %+v", test.msg.Channel, got, test.want)
+		}
+	}
+}
+
+func TestPubSubSubscriptions(t *testing.T) {
+	replies := "*3\r\n$9\r\nsubscribe\r\n$3\r\nch1\r\n:1\r\n" +
+		"*3\r\n$10\r\npsubscribe\r\n$2\r\np*\r\n:2\r\n" +
+		"*3\r\n$9\r\nsubscribe\r\n$3\r\nch2\r\n:3\r\n" +
+		"*3\r\n$11\r\nunsubscribe\r\n$3\r\nch1\r\n:2\r\n"
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &ConnStub{init: []byte(string(initHello) + replies)}, nil
+		},
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	pubsub := client.Subscribe(ctx, "ch1", "ch2")
+	defer pubsub.Close()
+
+	if subs := pubsub.Subscriptions(); len(subs.Channels) != 0 {
+		t.Fatalf("got %v, expected no confirmed subscriptions", subs.Channels)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := pubsub.Receive(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	subs := pubsub.Subscriptions()
+	if len(subs.Channels) != 1 || subs.Channels[0] != "ch2" {
+		t.Fatalf("got %v, expected [ch2]", subs.Channels)
+	}
+	if len(subs.Patterns) != 1 || subs.Patterns[0] != "p*" {
+		t.Fatalf("got %v, expected [p*]", subs.Patterns)
+	}
+	if subs.Count != 2 {
+		t.Fatalf("got %d, expected 2", subs.Count)
+	}
+}
+
+func TestPubSubChannelDropPolicies(t *testing.T) {
+	for _, test := range []struct {
+		policy ChannelPolicy
+		want   []string
+	}{
+		{ChannelDropNewest, []string{"m0", "m1"}},
+		{ChannelDropOldest, []string{"m3", "m4"}},
+	} {
+		client := newMessagesClient(5)
+		pubsub := client.Subscribe(ctx, "ch")
+		ch := pubsub.Channel(
+			WithChannelSize(2),
+			WithChannelHealthCheckInterval(0),
+			WithChannelPolicy(test.policy),
+		)
+
+		// Let the consumer fall behind.
+		for i := 0; i < 100 && pubsub.Dropped() < 3; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if n := pubsub.Dropped(); n != 3 {
+			t.Fatalf("policy %d: got %d dropped messages, expected 3", test.policy, n)
+		}
+		for _, want := range test.want {
+			if msg := <-ch; msg.Payload != want {
+				t.Fatalf("policy %d: got %q, expected %q", test.policy, msg.Payload, want)
+			}
+		}
+
+		_ = pubsub.Close()
+		_ = client.Close()
+	}
+}
@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPubSubConcurrentSubscribe(t *testing.T) {
+	subscribed := "*3\r\n$9\r\nsubscribe\r\n$2\r\nch\r\n:1\r\n"
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &ConnStub{init: initHello, resp: []byte(subscribed)}, nil
+		},
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	pubsub := client.Subscribe(ctx)
+	defer pubsub.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			msg, err := pubsub.Receive(ctx)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if _, ok := msg.(*Subscription); !ok {
+				t.Errorf("got %T, expected *Subscription", msg)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				ch := fmt.Sprintf("ch%d-%d", i, j)
+				if err := pubsub.Subscribe(ctx, ch); err != nil {
+					t.Error(err)
+				}
+				if err := pubsub.Unsubscribe(ctx, ch); err != nil {
+					t.Error(err)
+				}
+				if err := pubsub.Ping(ctx); err != nil {
+					t.Error(err)
+				}
+				_ = pubsub.String()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestPubSubReceiveContext(t *testing.T) {
+	var server net.Conn
+	connected := make(chan struct{})
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var cn net.Conn
+			cn, server = net.Pipe()
+			go func() { _, _ = io.Copy(io.Discard, server) }()
+			go func() {
+				_, _ = server.Write(initHello)
+				close(connected)
+			}()
+			return cn, nil
+		},
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	pubsub := client.Subscribe(ctx, "ch")
+	defer pubsub.Close()
+	<-connected
+
+	ctx1, cancel := context.WithCancel(ctx)
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err := pubsub.ReceiveContext(ctx1); err != context.Canceled {
+		t.Fatalf("got %v, expected context.Canceled", err)
+	}
+
+	ctx2, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := pubsub.ReceiveContext(ctx2); err != context.DeadlineExceeded {
+		t.Fatalf("got %v, expected context.DeadlineExceeded", err)
+	}
+
+	go func() {
+		_, _ = server.Write([]byte("*3\r\n$7\r\nmessage\r\n$2\r\nch\r\n$5\r\nhello\r\n"))
+	}()
+	msg, err := pubsub.ReceiveContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := msg.(*Message); !ok || m.Payload != "hello" {
+		t.Fatalf("got %v, expected hello", msg)
+	}
+}
@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPubSubOnReconnect(t *testing.T) {
+	subscribed := "*3\r\n$9\r\nsubscribe\r\n$2\r\nch\r\n:1\r\n"
+
+	var dials int32
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if atomic.AddInt32(&dials, 1) == 1 {
+				// The first connection is dropped after the subscription.
+				return &ConnStub{init: []byte(string(initHello) + subscribed)}, nil
+			}
+			return &ConnStub{init: initHello, resp: []byte(subscribed)}, nil
+		},
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	pubsub := client.Subscribe(ctx, "ch")
+	defer pubsub.Close()
+
+	events := make(chan *Reconnect, 1)
+	pubsub.OnReconnect(func(r *Reconnect) {
+		events <- r
+	})
+
+	if msg, err := pubsub.Receive(ctx); err != nil {
+		t.Fatal(err)
+	} else if _, ok := msg.(*Subscription); !ok {
+		t.Fatalf("got %T, expected *Subscription", msg)
+	}
+
+	if _, err := pubsub.Receive(ctx); err != io.EOF {
+		t.Fatalf("got %v, expected io.EOF", err)
+	}
+
+	select {
+	case r := <-events:
+		if r.Err != io.EOF {
+			t.Fatalf("got %v, expected io.EOF", r.Err)
+		}
+		if len(r.Channels) != 1 || r.Channels[0] != "ch" {
+			t.Fatalf("got %v, expected [ch]", r.Channels)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("reconnect event is not received")
+	}
+
+	if msg, err := pubsub.Receive(ctx); err != nil {
+		t.Fatal(err)
+	} else if _, ok := msg.(*Subscription); !ok {
+		t.Fatalf("got %T, expected *Subscription", msg)
+	}
+}
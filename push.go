@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"sync"
+)
+
+// PushHandler handles an out-of-band RESP3 push message, e.g. a client-side
+// caching "invalidate" message. kind is the first element of the push message
+// and payload holds the remaining elements.
+//
+// The handler is called synchronously by the goroutine that reads the reply
+// of the command the push message preceded, so it must be fast and must not
+// execute commands using the same connection.
+type PushHandler func(kind string, payload []interface{})
+
+// pushRouter dispatches push messages received on command connections
+// by their kind. Push messages without a registered handler are dropped.
+// Pub/Sub connections don't use the router: for them, push messages are the replies.
+type pushRouter struct {
+	mu       sync.RWMutex
+	handlers map[string]PushHandler
+}
+
+func newPushRouter() *pushRouter {
+	return &pushRouter{
+		handlers: make(map[string]PushHandler),
+	}
+}
+
+// register sets the handler for the kind. A nil handler unregisters it.
+func (r *pushRouter) register(kind string, handler PushHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if handler == nil {
+		delete(r.handlers, kind)
+		return
+	}
+	r.handlers[kind] = handler
+}
+
+func (r *pushRouter) dispatch(push []interface{}) {
+	if len(push) == 0 {
+		return
+	}
+	kind, _ := push[0].(string)
+
+	r.mu.RLock()
+	handler := r.handlers[kind]
+	r.mu.RUnlock()
+
+	if handler != nil {
+		handler(kind, push[1:])
+	}
+}
+
+// RegisterPushHandler registers the handler for out-of-band RESP3 push messages
+// of the given kind, e.g. "invalidate". Registering a nil handler removes it.
+//
+// Push messages received on command connections never corrupt command replies:
+// they are consumed and passed to the handler, or dropped if there is none.
+// Push messages are only sent by the server when the RESP3 protocol is used.
+func (c *Client) RegisterPushHandler(kind string, handler PushHandler) {
+	c.opt.pushRouter.register(kind, handler)
+}
+
+// RegisterPushHandler registers the handler for out-of-band RESP3 push messages
+// of the given kind received from any cluster node. See Client.RegisterPushHandler.
+func (c *ClusterClient) RegisterPushHandler(kind string, handler PushHandler) {
+	c.opt.pushRouter.register(kind, handler)
+}
+
+// RegisterPushHandler registers the handler for out-of-band RESP3 push messages
+// of the given kind received from any ring shard. See Client.RegisterPushHandler.
+func (c *Ring) RegisterPushHandler(kind string, handler PushHandler) {
+	c.opt.pushRouter.register(kind, handler)
+}
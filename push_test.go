@@ -0,0 +1,32 @@
+package redis
+
+import (
+	"testing"
+)
+
+func TestPushHandler(t *testing.T) {
+	client := NewClientStub([]byte(">2\r\n+invalidate\r\n*1\r\n+key\r\n$5\r\nvalue\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	invalidated := make(chan interface{}, 1)
+	client.RegisterPushHandler("invalidate", func(kind string, payload []interface{}) {
+		invalidated <- payload[0]
+	})
+
+	val, err := client.Get(ctx, "key").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val != "value" {
+		t.Fatalf("got %q, wanted %q", val, "value")
+	}
+
+	select {
+	case keys := <-invalidated:
+		if keys.([]interface{})[0] != "key" {
+			t.Fatalf("got %v, wanted [key]", keys)
+		}
+	default:
+		t.Fatal("push handler was not called")
+	}
+}
@@ -0,0 +1,122 @@
+package redis
+
+import (
+	"context"
+)
+
+// ReadOnlyClientError is returned for the write commands rejected
+// by Options.ReadOnlyClient. The commands are not sent to the server.
+type ReadOnlyClientError struct {
+	// Cmd is the name of the rejected command.
+	Cmd string
+}
+
+func (e *ReadOnlyClientError) Error() string {
+	return "redis: " + e.Cmd + " is not allowed by a read-only client"
+}
+
+// isWriteCmd reports whether the command may modify the data according
+// to the command info returned by COMMAND. Unknown commands are considered
+// to be writes. PUBLISH is not a write even though it is replicated.
+func isWriteCmd(info map[string]*CommandInfo, name string) bool {
+	cmdInfo := info[name]
+	if cmdInfo == nil {
+		return true
+	}
+	var mayReplicate, pubsub bool
+	for _, flag := range cmdInfo.Flags {
+		switch flag {
+		case "write":
+			return true
+		case "may_replicate":
+			mayReplicate = true
+		case "pubsub":
+			pubsub = true
+		}
+	}
+	return mayReplicate && !pubsub
+}
+
+// checkReadOnlyCmds returns a ReadOnlyClientError for the first write command.
+func checkReadOnlyCmds(info map[string]*CommandInfo, cmds []Cmder) error {
+	for _, cmd := range cmds {
+		if name := cmd.Name(); isWriteCmd(info, name) {
+			return &ReadOnlyClientError{Cmd: name}
+		}
+	}
+	return nil
+}
+
+// checkCmds rejects the commands not allowed by Options.ReadOnlyClient
+// or Options.Authorize before they are sent.
+func (c *baseClient) checkCmds(ctx context.Context, cmds ...Cmder) error {
+	if !c.opt.ReadOnlyClient && c.opt.Authorize == nil {
+		return nil
+	}
+	info, err := c.opt.cmdsInfo.Get(ctx)
+	if err != nil {
+		return err
+	}
+	return checkCmds(c.opt, info, cmds)
+}
+
+// checkCmds rejects the pipelined commands not allowed by Options.ReadOnlyClient
+// or Options.Authorize. Single commands are checked by the node clients.
+func (c *ClusterClient) checkCmds(ctx context.Context, cmds []Cmder) error {
+	if !c.opt.ReadOnlyClient && c.opt.Authorize == nil {
+		return nil
+	}
+	info, err := c.cmdsInfoCache.Get(ctx)
+	if err != nil {
+		return err
+	}
+	// The pipelined commands may be sent to several nodes, so Addr is not set.
+	opt := &Options{
+		ReadOnlyClient: c.opt.ReadOnlyClient,
+		Authorize:      c.opt.Authorize,
+	}
+	return checkCmds(opt, info, cmds)
+}
+
+func checkCmds(opt *Options, info map[string]*CommandInfo, cmds []Cmder) error {
+	if opt.ReadOnlyClient {
+		if err := checkReadOnlyCmds(info, cmds); err != nil {
+			return err
+		}
+	}
+	if opt.Authorize != nil {
+		return authorizeCmds(opt, info, cmds)
+	}
+	return nil
+}
+
+// initCmdsInfo sets up the command info of the options used by
+// Options.ReadOnlyClient and Options.Authorize.
+func (c *baseClient) initCmdsInfo() {
+	if (c.opt.ReadOnlyClient || c.opt.Authorize != nil) && c.opt.cmdsInfo == nil {
+		c.opt.cmdsInfo = newCmdsInfoCache(c.cmdsInfo)
+	}
+}
+
+// cmdsInfo returns the command info used by Options.ReadOnlyClient and Options.Authorize.
+func (c *baseClient) cmdsInfo(ctx context.Context) (map[string]*CommandInfo, error) {
+	base := &baseClient{
+		opt:      c.opt.unguarded(),
+		connPool: c.connPool,
+	}
+	cmd := NewCommandsInfoCmd(ctx, "command")
+	_ = base.process(ctx, cmd)
+	return cmd.Result()
+}
+
+// unguarded returns the options without the client-side checks of the commands,
+// e.g. for the commands sent by the client itself.
+func (opt *Options) unguarded() *Options {
+	if !opt.ReadOnlyClient && opt.Authorize == nil {
+		return opt
+	}
+	clone := opt.clone()
+	clone.ReadOnlyClient = false
+	clone.Authorize = nil
+	return clone
+}
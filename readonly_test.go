@@ -0,0 +1,135 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// readOnlyServer replies to COMMAND with the info of a few commands
+// and counts the other commands.
+func readOnlyServer(sent *int32) net.Conn {
+	const commandReply = "*5\r\n" +
+		"*6\r\n$3\r\nget\r\n:2\r\n*2\r\n+readonly\r\n+fast\r\n:1\r\n:1\r\n:1\r\n" +
+		"*6\r\n$3\r\nset\r\n:-3\r\n*2\r\n+write\r\n+denyoom\r\n:1\r\n:1\r\n:1\r\n" +
+		"*6\r\n$4\r\neval\r\n:-3\r\n*2\r\n+noscript\r\n+may_replicate\r\n:0\r\n:0\r\n:0\r\n" +
+		"*6\r\n$7\r\npublish\r\n:3\r\n*2\r\n+pubsub\r\n+may_replicate\r\n:0\r\n:0\r\n:0\r\n" +
+		"*6\r\n$7\r\ncommand\r\n:-1\r\n*1\r\n+loading\r\n:0\r\n:0\r\n:0\r\n"
+
+	cn, server := net.Pipe()
+	go func() {
+		rd := proto.NewReader(server)
+		for {
+			v, err := rd.ReadReply()
+			if err != nil {
+				return
+			}
+			args, _ := v.([]interface{})
+			if len(args) == 0 {
+				return
+			}
+			switch args[0] {
+			case "hello":
+				_, err = server.Write(initHello)
+			case "command":
+				_, err = server.Write([]byte(commandReply))
+			case "publish":
+				atomic.AddInt32(sent, 1)
+				_, err = server.Write([]byte(":0\r\n"))
+			default:
+				atomic.AddInt32(sent, 1)
+				_, err = server.Write([]byte("+OK\r\n"))
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return cn
+}
+
+func TestReadOnlyClient(t *testing.T) {
+	var sent int32
+	client := NewClient(&Options{
+		Addr: "stub:6379",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return readOnlyServer(&sent), nil
+		},
+		ReadOnlyClient:   true,
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	if err := client.Get(ctx, "key").Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Publish(ctx, "channel", "message").Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cmd := range []Cmder{
+		client.Set(ctx, "key", "value", 0),
+		client.Eval(ctx, "return 1", nil),
+		client.Do(ctx, "unknown"),
+	} {
+		var roErr *ReadOnlyClientError
+		if err := cmd.Err(); !errors.As(err, &roErr) || roErr.Cmd != cmd.Name() {
+			t.Fatalf("got %v, expected a ReadOnlyClientError for %s", err, cmd.Name())
+		}
+	}
+
+	// A pipeline with a write command is not sent.
+	cmds, err := client.Pipelined(ctx, func(pipe Pipeliner) error {
+		pipe.Get(ctx, "key")
+		pipe.Set(ctx, "key", "value", 0)
+		return nil
+	})
+	if _, ok := err.(*ReadOnlyClientError); !ok {
+		t.Fatalf("got %v, expected a ReadOnlyClientError", err)
+	}
+	for _, cmd := range cmds {
+		if cmd.Err() != err {
+			t.Fatalf("got %v, expected %v", cmd.Err(), err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&sent); n != 2 {
+		t.Fatalf("got %d commands sent, expected GET and PUBLISH", n)
+	}
+}
+
+func TestUniversalOptionsReadOnlyClient(t *testing.T) {
+	var sent int32
+	client := NewUniversalClient(&UniversalOptions{
+		Addrs: []string{"stub:6379"},
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return readOnlyServer(&sent), nil
+		},
+		ReadOnlyClient:   true,
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	var roErr *ReadOnlyClientError
+	if err := client.Set(ctx, "key", "value", 0).Err(); !errors.As(err, &roErr) {
+		t.Fatalf("got %v, expected a ReadOnlyClientError", err)
+	}
+
+	opt := (&UniversalOptions{
+		MasterName:        "master",
+		ReadOnlyClient:    true,
+		Codec:             JSONCodec{},
+		MaxKeysPerCommand: 10,
+	}).Failover()
+	if o := opt.clientOptions(); !o.ReadOnlyClient || o.Codec == nil || o.MaxKeysPerCommand != 10 {
+		t.Fatalf("got %+v, expected the options of the master", o)
+	}
+	// The sentinels only serve the SENTINEL commands.
+	if o := opt.sentinelOptions("sentinel:26379"); o.ReadOnlyClient || o.MaxKeysPerCommand != 10 {
+		t.Fatalf("got %+v, expected the options of the sentinel", o)
+	}
+}
@@ -0,0 +1,350 @@
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// recordedEvent is the data sent to the server or received from it.
+type recordedEvent struct {
+	sent bool
+	data []byte
+}
+
+type recordedConn struct {
+	events []recordedEvent
+}
+
+func (cn *recordedConn) add(sent bool, data []byte) {
+	if n := len(cn.events); n > 0 && cn.events[n-1].sent == sent {
+		cn.events[n-1].data = append(cn.events[n-1].data, data...)
+		return
+	}
+	cn.events = append(cn.events, recordedEvent{
+		sent: sent,
+		data: append([]byte(nil), data...),
+	})
+}
+
+// writeRecording writes the connections in the format:
+//
+//	conn 1
+//	> "*2\r\n$3\r\nget\r\n$3\r\nkey\r\n"
+//	< "$5\r\nvalue\r\n"
+func writeRecording(w io.Writer, conns []*recordedConn) (int64, error) {
+	var buf bytes.Buffer
+	for i, cn := range conns {
+		fmt.Fprintf(&buf, "conn %d\n", i+1)
+		for _, ev := range cn.events {
+			dir := "<"
+			if ev.sent {
+				dir = ">"
+			}
+			buf.WriteString(dir + " " + strconv.Quote(string(ev.data)) + "\n")
+		}
+	}
+	return buf.WriteTo(w)
+}
+
+func readRecording(rd io.Reader) ([]*recordedConn, error) {
+	var conns []*recordedConn
+	br := bufio.NewReader(rd)
+	for lineNum := 1; ; lineNum++ {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			if err := parseRecordingLine(&conns, line); err != nil {
+				return nil, fmt.Errorf("redis: recording line %d: %w", lineNum, err)
+			}
+		}
+		if err == io.EOF {
+			return conns, nil
+		}
+	}
+}
+
+func parseRecordingLine(conns *[]*recordedConn, line string) error {
+	if strings.HasPrefix(line, "conn ") {
+		*conns = append(*conns, new(recordedConn))
+		return nil
+	}
+	dir, quoted, ok := strings.Cut(line, " ")
+	if !ok || (dir != ">" && dir != "<") {
+		return fmt.Errorf("invalid line %q", line)
+	}
+	if len(*conns) == 0 {
+		return errors.New("data before the first conn")
+	}
+	data, err := strconv.Unquote(quoted)
+	if err != nil {
+		return err
+	}
+	(*conns)[len(*conns)-1].add(dir == ">", []byte(data))
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// Recorder is a hook recording the data sent and received by the connections
+// of the client, so it can be replayed by a Replayer. Disable the client
+// identity using Options.DisableIndentity, so the recording does not change
+// with the version of the library. Recorder is safe for concurrent use by
+// multiple goroutines.
+//
+//	rec := redis.NewRecorder()
+//	rdb.AddHook(rec)
+//	...
+//	err := rec.Save("testdata/session.golden")
+type Recorder struct {
+	mu    sync.Mutex
+	conns []*recordedConn
+}
+
+var _ Hook = (*Recorder)(nil)
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// WriteTo writes the recording to w.
+func (r *Recorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return writeRecording(w, r.conns)
+}
+
+// Save writes the recording to the file.
+func (r *Recorder) Save(name string) error {
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(name, buf.Bytes(), 0o644)
+}
+
+func (r *Recorder) DialHook(next DialHook) DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		r.mu.Lock()
+		cn := new(recordedConn)
+		r.conns = append(r.conns, cn)
+		r.mu.Unlock()
+		return &recorderConn{Conn: conn, r: r, cn: cn}, nil
+	}
+}
+
+func (r *Recorder) ProcessHook(next ProcessHook) ProcessHook {
+	return next
+}
+
+func (r *Recorder) ProcessPipelineHook(next ProcessPipelineHook) ProcessPipelineHook {
+	return next
+}
+
+type recorderConn struct {
+	net.Conn
+	r  *Recorder
+	cn *recordedConn
+}
+
+func (c *recorderConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.r.mu.Lock()
+		c.cn.add(false, b[:n])
+		c.r.mu.Unlock()
+	}
+	return n, err
+}
+
+func (c *recorderConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.r.mu.Lock()
+		c.cn.add(true, b[:n])
+		c.r.mu.Unlock()
+	}
+	return n, err
+}
+
+//------------------------------------------------------------------------------
+
+// Replayer is a fake server replaying a recording of a Recorder. The
+// connections dialed by Dialer are served in the order of the recorded
+// connections: the commands sent must match the recorded commands, which
+// are answered with the recorded replies.
+//
+//	replayer, err := redis.LoadReplayer("testdata/session.golden")
+//	...
+//	rdb := redis.NewClient(&redis.Options{
+//		Dialer:           replayer.Dialer,
+//		DisableIndentity: true,
+//	})
+//	...
+//	if err := replayer.Err(); err != nil {
+//		t.Fatal(err)
+//	}
+type Replayer struct {
+	mu     sync.Mutex
+	conns  []*recordedConn
+	dialed int
+	// pending is the number of the dialed connections whose recorded
+	// commands were not all sent.
+	pending int
+	err     error
+}
+
+// NewReplayer returns a Replayer of the recording written by Recorder.WriteTo.
+func NewReplayer(rd io.Reader) (*Replayer, error) {
+	conns, err := readRecording(rd)
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{conns: conns}, nil
+}
+
+// LoadReplayer returns a Replayer of the recording saved by Recorder.Save.
+func LoadReplayer(name string) (*Replayer, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewReplayer(f)
+}
+
+// Dialer returns a connection to the next recorded connection.
+// It can be used as Options.Dialer.
+func (r *Replayer) Dialer(ctx context.Context, network, addr string) (net.Conn, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dialed >= len(r.conns) {
+		err := fmt.Errorf("redis: replay: unexpected connection %d", r.dialed+1)
+		r.setErr(err)
+		return nil, err
+	}
+	rc := r.conns[r.dialed]
+	r.dialed++
+	r.pending++
+
+	conn, server := net.Pipe()
+	go r.serve(server, rc, r.dialed)
+	return conn, nil
+}
+
+// Err returns the first mismatch between the sent commands and the recorded
+// commands, or an error if some recorded commands were not sent.
+func (r *Replayer) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	if n := len(r.conns) - r.dialed + r.pending; n > 0 {
+		return fmt.Errorf("redis: replay: %d of %d connections were not replayed", n, len(r.conns))
+	}
+	return nil
+}
+
+func (r *Replayer) setErr(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *Replayer) serve(server net.Conn, rc *recordedConn, id int) {
+	defer server.Close()
+
+	err := replayConn(server, rc, func() {
+		r.mu.Lock()
+		r.pending--
+		r.mu.Unlock()
+	})
+	if err != nil {
+		err = fmt.Errorf("redis: replay: conn %d: %w", id, err)
+		r.mu.Lock()
+		r.setErr(err)
+		r.mu.Unlock()
+
+		// Fail the command waiting for the reply, if any.
+		_ = server.SetWriteDeadline(time.Now().Add(time.Second))
+		_, _ = server.Write([]byte("-ERR " + strings.ReplaceAll(err.Error(), "\r\n", " ") + "\r\n"))
+	}
+}
+
+// replayConn serves the recorded connection until the recording and
+// the connection are done. It calls sent when the recorded commands were
+// sent, before the last replies are written.
+func replayConn(server net.Conn, rc *recordedConn, sent func()) error {
+	last := -1
+	for i, ev := range rc.events {
+		if ev.sent {
+			last = i
+		}
+	}
+	if last == -1 {
+		sent()
+	}
+
+	rd := proto.NewReader(server)
+	for i, ev := range rc.events {
+		if !ev.sent {
+			if _, err := server.Write(ev.data); err != nil {
+				return err
+			}
+			continue
+		}
+
+		recorded := proto.NewReader(bytes.NewReader(ev.data))
+		for {
+			want, err := recorded.ReadReply()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			got, err := rd.ReadReply()
+			if err != nil {
+				return fmt.Errorf("got %v, wanted command %v", err, want)
+			}
+			if !reflect.DeepEqual(got, want) {
+				return fmt.Errorf("got command %v, wanted %v", got, want)
+			}
+		}
+		if i == last {
+			sent()
+		}
+	}
+
+	// The client must close the connection without sending more commands.
+	got, err := rd.ReadReply()
+	if err == nil {
+		return fmt.Errorf("got unexpected command %v", got)
+	}
+	if err != io.EOF && !errors.Is(err, io.ErrClosedPipe) {
+		return err
+	}
+	return nil
+}
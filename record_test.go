@@ -0,0 +1,100 @@
+package redis
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func recordSession(t *testing.T, client *Client) {
+	if err := client.Set(ctx, "a", "1", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if val, err := client.Get(ctx, "a").Result(); err != nil || val != "1" {
+		t.Fatalf("got %q, %v", val, err)
+	}
+	if err := client.Get(ctx, "missing").Err(); err != Nil {
+		t.Fatalf("got %v, wanted Nil", err)
+	}
+	var incr *IntCmd
+	if _, err := client.Pipelined(ctx, func(pipe Pipeliner) error {
+		incr = pipe.IncrBy(ctx, "n", 2)
+		pipe.Del(ctx, "a")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if incr.Val() != 2 {
+		t.Fatalf("got %d, wanted 2", incr.Val())
+	}
+}
+
+func TestRecorderReplayer(t *testing.T) {
+	client := newKVServer(map[string]string{}).client()
+	rec := NewRecorder()
+	client.AddHook(rec)
+	recordSession(t, client)
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	name := filepath.Join(t.TempDir(), "session.golden")
+	if err := rec.Save(name); err != nil {
+		t.Fatal(err)
+	}
+	replayer, err := LoadReplayer(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client = NewClient(&Options{
+		Dialer:           replayer.Dialer,
+		DisableIndentity: true,
+	})
+	recordSession(t, client)
+	if err := replayer.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplayerMismatch(t *testing.T) {
+	client := newKVServer(map[string]string{"a": "1"}).client()
+	rec := NewRecorder()
+	client.AddHook(rec)
+	if err := client.Get(ctx, "a").Err(); err != nil {
+		t.Fatal(err)
+	}
+	_ = client.Close()
+
+	var buf bytes.Buffer
+	if _, err := rec.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `> "*2\r\n$3\r\nget\r\n$1\r\na\r\n"`) {
+		t.Fatalf("got recording %s", buf.String())
+	}
+	replayer, err := NewReplayer(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client = NewClient(&Options{
+		Dialer:           replayer.Dialer,
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	if err := replayer.Err(); err == nil {
+		t.Fatal("the connection is not replayed")
+	}
+	if err := client.Get(ctx, "b").Err(); err == nil || !strings.Contains(err.Error(), "got command [get b], wanted [get a]") {
+		t.Fatalf("got %v", err)
+	}
+	if err := replayer.Err(); err == nil || !strings.Contains(err.Error(), "conn 1") {
+		t.Fatalf("got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"sync"
+
+	"github.com/redis/go-redis/v9/internal/pool"
+)
+
+// credentialsHolder holds the credentials set by ReAuthenticate,
+// shared by all clones of the options.
+type credentialsHolder struct {
+	mu       sync.RWMutex
+	set      bool
+	username string
+	password string
+}
+
+func (h *credentialsHolder) store(username, password string) {
+	h.mu.Lock()
+	h.set = true
+	h.username = username
+	h.password = password
+	h.mu.Unlock()
+}
+
+// load returns the credentials and whether they were set.
+func (h *credentialsHolder) load() (username, password string, ok bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.username, h.password, h.set
+}
+
+// ReAuthenticate replaces the Username and Password of the client, e.g. after
+// a token was renewed, and recycles the connections, see RecycleConns.
+// CredentialsProvider and CredentialsProviderContext take precedence over
+// the credentials, they are consulted again by the new connections.
+func (c *Client) ReAuthenticate(username, password string) {
+	c.opt.credentials.store(username, password)
+	c.RecycleConns()
+}
+
+// ReAuthenticate replaces the credentials of the connections to all cluster nodes,
+// see Client.ReAuthenticate.
+func (c *ClusterClient) ReAuthenticate(username, password string) {
+	c.opt.credentials.store(username, password)
+	c.RecycleConns()
+}
+
+// RecycleConns replaces the connections of the client with new ones, e.g. after
+// the renewal of the TLS client certificate returned by GetClientCertificate.
+// Idle connections are closed when they are taken from the pool and connections
+// in use are closed once the command completes, so no command is interrupted.
+func (c *Client) RecycleConns() {
+	recyclePool(c.connPool)
+}
+
+// RecycleConns replaces the connections to all cluster nodes, see Client.RecycleConns.
+func (c *ClusterClient) RecycleConns() {
+	nodes, err := c.nodes.All()
+	if err != nil {
+		return
+	}
+	for _, node := range nodes {
+		recyclePool(node.Client.connPool)
+	}
+}
+
+// RecycleConns replaces the connections to all ring shards, see Client.RecycleConns.
+func (c *Ring) RecycleConns() {
+	for _, shard := range c.sharding.List() {
+		recyclePool(shard.Client.connPool)
+	}
+}
+
+func recyclePool(p pool.Pooler) {
+	if p, ok := p.(*pool.ConnPool); ok {
+		p.Recycle()
+	}
+}
@@ -0,0 +1,158 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type recordingConn struct {
+	*ConnStub
+	mu      *sync.Mutex
+	written *bytes.Buffer
+}
+
+func (c recordingConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	c.written.Write(b)
+	c.mu.Unlock()
+	return c.ConnStub.Write(b)
+}
+
+func TestReAuthenticate(t *testing.T) {
+	var mu sync.Mutex
+	var dials []*bytes.Buffer
+	client := NewClient(&Options{
+		Addr: "stub:6379",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			written := new(bytes.Buffer)
+			dials = append(dials, written)
+			stub := &ConnStub{init: initHello, resp: []byte("+PONG\r\n")}
+			return recordingConn{ConnStub: stub, mu: &mu, written: written}, nil
+		},
+		Username:         "user",
+		Password:         "old-token",
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+	client.ReAuthenticate("user", "new-token")
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dials) != 2 {
+		t.Fatalf("got %d dials, expected the idle conn to be replaced", len(dials))
+	}
+	if !bytes.Contains(dials[0].Bytes(), []byte("old-token")) {
+		t.Fatalf("got %q, expected the old credentials", dials[0])
+	}
+	if !bytes.Contains(dials[1].Bytes(), []byte("new-token")) {
+		t.Fatalf("got %q, expected the new credentials", dials[1])
+	}
+}
+
+func TestNoAuthRedial(t *testing.T) {
+	newClient := func(provider func() (string, string), resps ...string) (*Client, *int) {
+		var dials int
+		return NewClient(&Options{
+			Addr: "stub:6379",
+			Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				resp := resps[len(resps)-1]
+				if dials < len(resps) {
+					resp = resps[dials]
+				}
+				dials++
+				return &ConnStub{init: initHello, resp: []byte(resp)}, nil
+			},
+			CredentialsProvider: provider,
+			MinRetryBackoff:     -1,
+			MaxRetryBackoff:     -1,
+			DisableIndentity:    true,
+		}), &dials
+	}
+	provider := func() (string, string) { return "user", "token" }
+	const noAuth = "-NOAUTH Authentication required.\r\n"
+
+	// The authentication of the first conn expires.
+	client, dials := newClient(provider, noAuth, "+PONG\r\n")
+	defer client.Close()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if *dials != 2 {
+		t.Fatalf("got %d dials, expected 2", *dials)
+	}
+
+	// NOAUTH is retried at most once.
+	client, dials = newClient(provider, noAuth)
+	defer client.Close()
+	if err := client.Ping(ctx).Err(); err == nil || !isNoAuthError(err) {
+		t.Fatalf("got %v, expected NOAUTH", err)
+	}
+	if *dials != 2 {
+		t.Fatalf("got %d dials, expected 2", *dials)
+	}
+
+	// Without rotating credentials the retry would fail again.
+	client, dials = newClient(nil, noAuth, "+PONG\r\n")
+	defer client.Close()
+	if err := client.Ping(ctx).Err(); err == nil || !isNoAuthError(err) {
+		t.Fatalf("got %v, expected NOAUTH", err)
+	}
+	if *dials != 1 {
+		t.Fatalf("got %d dials, expected 1", *dials)
+	}
+}
+
+func TestRecycleConnsClientCertificate(t *testing.T) {
+	cert := testCertificate()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequestClientCert,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	dialStatsServer(ln)
+
+	var certRequests int32
+	client := NewClient(&Options{
+		Addr: ln.Addr().String(),
+		TLSConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				atomic.AddInt32(&certRequests, 1)
+				return &cert, nil
+			},
+		},
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+	client.RecycleConns()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The renewed certificate is requested by the new connection.
+	if n := atomic.LoadInt32(&certRequests); n != 2 {
+		t.Fatalf("got %d certificate requests, expected 2", n)
+	}
+}
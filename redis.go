@@ -26,6 +26,12 @@ func SetLogger(logger internal.Logging) {
 	internal.Logger = logger
 }
 
+// LeveledLogger is a structured logger with levels, e.g. an adapter for
+// log/slog or zap. keysAndValues are alternating keys and values.
+// It is set per client with Options.Logger. By default the Info and higher
+// levels are printed with the logger set by SetLogger.
+type LeveledLogger = internal.LeveledLogging
+
 //------------------------------------------------------------------------------
 
 type Hook interface {
@@ -226,11 +232,13 @@ func (c *baseClient) newConn(ctx context.Context) (*pool.Conn, error) {
 		return nil, err
 	}
 
+	start := time.Now()
 	err = c.initConn(ctx, cn)
 	if err != nil {
 		_ = c.connPool.CloseConn(cn)
 		return nil, err
 	}
+	c.reportDial(ctx, cn, time.Since(start))
 
 	return cn, nil
 }
@@ -264,6 +272,7 @@ func (c *baseClient) _getConn(ctx context.Context) (*pool.Conn, error) {
 		return cn, nil
 	}
 
+	start := time.Now()
 	if err := c.initConn(ctx, cn); err != nil {
 		c.connPool.Remove(ctx, cn, err)
 		if err := errors.Unwrap(err); err != nil {
@@ -271,6 +280,13 @@ func (c *baseClient) _getConn(ctx context.Context) (*pool.Conn, error) {
 		}
 		return nil, err
 	}
+	c.reportDial(ctx, cn, time.Since(start))
+
+	// Only pooled command connections consume push messages,
+	// PubSub connections receive them as replies.
+	if c.opt.pushRouter != nil {
+		cn.SetPushHandler(c.opt.pushRouter.dispatch)
+	}
 
 	return cn, nil
 }
@@ -283,6 +299,9 @@ func (c *baseClient) initConn(ctx context.Context, cn *pool.Conn) error {
 
 	var err error
 	username, password := c.opt.Username, c.opt.Password
+	if u, p, ok := c.opt.credentials.load(); ok {
+		username, password = u, p
+	}
 	if c.opt.CredentialsProviderContext != nil {
 		if username, password, err = c.opt.CredentialsProviderContext(ctx); err != nil {
 			return err
@@ -292,7 +311,7 @@ func (c *baseClient) initConn(ctx context.Context, cn *pool.Conn) error {
 	}
 
 	connPool := pool.NewSingleConnPool(c.connPool, cn)
-	conn := newConn(c.opt, connPool)
+	conn := newConn(c.opt.unguarded(), connPool)
 
 	var auth bool
 	protocol := c.opt.Protocol
@@ -302,9 +321,22 @@ func (c *baseClient) initConn(ctx context.Context, cn *pool.Conn) error {
 	}
 
 	// for redis-server versions that do not support the HELLO command,
-	// RESP2 will continue to be used.
-	if err = conn.Hello(ctx, protocol, username, password, "").Err(); err == nil {
+	// RESP2 will continue to be used, unless RESP3 was explicitly requested.
+	// The capabilities are detected again on every connection,
+	// so they are up to date after a failover or an upgrade.
+	addr := connAddr(cn)
+	var caps *ServerCapabilities
+	hello := conn.Hello(ctx, protocol, username, password, "")
+	if err = hello.Err(); err == nil {
 		auth = true
+		caps = newHelloCapabilities(hello.Val())
+		c.opt.capabilities.store(addr, caps)
+	} else if c.opt.Protocol == 3 && isRedisError(err) {
+		// The other errors, e.g. WRONGPASS or NOPERM, are returned as is.
+		if isUnsupportedHelloError(err) {
+			err = &resp3RequiredError{err: err}
+		}
+		return err
 	} else if !isRedisError(err) {
 		// When the server responds with the RESP protocol and the result is not a normal
 		// execution result of the HELLO command, we consider it to be an indication that
@@ -343,7 +375,23 @@ func (c *baseClient) initConn(ctx context.Context, cn *pool.Conn) error {
 		return err
 	}
 
-	if !c.opt.DisableIndentity {
+	if caps == nil {
+		caps = c.opt.capabilities.loadAddr(addr)
+	}
+	if caps == nil {
+		// The server does not support HELLO: detect the version using INFO,
+		// unless it was already detected on this address.
+		// Errors are ignored, e.g. INFO may be disabled by the server.
+		if info, err := conn.Info(ctx, "server").Result(); err == nil {
+			caps = newInfoCapabilities(info)
+		} else {
+			caps = &ServerCapabilities{Protocol: 2}
+		}
+		c.opt.capabilities.store(addr, caps)
+	}
+
+	// CLIENT SETINFO is not supported before Redis 7.2.
+	if !c.opt.DisableIndentity && (caps == nil || caps.SupportsClientSetInfo()) {
 		libName := ""
 		libVer := Version()
 		if c.opt.IdentitySuffix != "" {
@@ -396,16 +444,28 @@ func (c *baseClient) dial(ctx context.Context, network, addr string) (net.Conn,
 }
 
 func (c *baseClient) process(ctx context.Context, cmd Cmder) error {
+	if err := c.checkCmds(ctx, cmd); err != nil {
+		return err
+	}
+	c.opt.RetryBudget.deposit()
+
+	timer := newSlowCmdTimer(c.opt, cmd)
+	defer timer.report(c.opt, cmd)
+
 	var lastErr error
+	if c.opt.audit != nil {
+		start := time.Now()
+		defer func() { c.opt.audit.add(cmd, lastErr, time.Since(start)) }()
+	}
+
 	for attempt := 0; attempt <= c.opt.MaxRetries; attempt++ {
 		attempt := attempt
 
-		retry, err := c._process(ctx, cmd, attempt)
+		retry, err := c._process(ctx, cmd, attempt, lastErr)
+		lastErr = err
 		if err == nil || !retry {
 			return err
 		}
-
-		lastErr = err
 	}
 	return lastErr
 }
@@ -423,27 +483,40 @@ func (c *baseClient) assertUnstableCommand(cmd Cmder) bool {
 	}
 }
 
-func (c *baseClient) _process(ctx context.Context, cmd Cmder, attempt int) (bool, error) {
+func (c *baseClient) _process(
+	ctx context.Context, cmd Cmder, attempt int, lastErr error,
+) (bool, error) {
 	if attempt > 0 {
-		if err := internal.Sleep(ctx, c.retryBackoff(attempt)); err != nil {
+		if err := internal.SleepClock(ctx, c.opt.Clock, c.retryBackoffAfter(attempt, lastErr)); err != nil {
 			return false, err
 		}
 	}
 
+	timer := newAttemptTimer(c.opt.cmdStatsEnabled())
+	defer timer.addStats(cmd)
+
 	retryTimeout := uint32(0)
 	if err := c.withConn(ctx, func(ctx context.Context, cn *pool.Conn) error {
-		if err := cn.WithWriter(c.context(ctx), c.opt.WriteTimeout, func(wr *proto.Writer) error {
+		timer.connect()
+		if err := cn.WithWriter(c.context(ctx), writeTimeout(ctx, c.opt.WriteTimeout), func(wr *proto.Writer) error {
 			return writeCmd(wr, cmd)
 		}); err != nil {
 			atomic.StoreUint32(&retryTimeout, 1)
 			return err
 		}
+		timer.write()
+		c.logWireCmds(ctx, cn, []Cmder{cmd})
 		readReplyFunc := cmd.readReply
 		// Apply unstable RESP3 search module.
 		if c.opt.Protocol != 2 && c.assertUnstableCommand(cmd) {
 			readReplyFunc = cmd.readRawReply
 		}
-		if err := cn.WithReader(c.context(ctx), c.cmdTimeout(cmd), readReplyFunc); err != nil {
+		err := cn.WithReader(c.context(ctx), c.cmdTimeout(ctx, cmd), readReplyFunc)
+		if c.opt.wireDebug.enabled() {
+			cmd.SetErr(err)
+			c.logWireReplies(ctx, cn, []Cmder{cmd})
+		}
+		if err != nil {
 			if cmd.readTimeout() == nil {
 				atomic.StoreUint32(&retryTimeout, 1)
 			} else {
@@ -454,24 +527,52 @@ func (c *baseClient) _process(ctx context.Context, cmd Cmder, attempt int) (bool
 
 		return nil
 	}); err != nil {
-		retry := shouldRetry(err, atomic.LoadUint32(&retryTimeout) == 1)
+		c.opt.errStats.add(err, timer.hasConn)
+		retry := c.shouldRetry(err, atomic.LoadUint32(&retryTimeout) == 1, attempt) &&
+			attempt < c.opt.MaxRetries && retryAllowed(c.opt.ShouldRetry, err, attempt, cmd)
+		if retry && !c.opt.RetryBudget.withdraw() {
+			return false, retryBudgetError{err: err}
+		}
 		return retry, err
 	}
 
 	return false, nil
 }
 
+// shouldRetry reports whether the command that failed on the attempt is retried.
+// The connection failing with NOAUTH is closed, so the first attempt is retried
+// on a new connection if the credentials can rotate, see ReAuthenticate and
+// CredentialsProvider. The same credentials would fail again.
+func (c *baseClient) shouldRetry(err error, retryTimeout bool, attempt int) bool {
+	if isRedisError(err) && isNoAuthError(err) {
+		return attempt == 0 && c.credentialsRotate()
+	}
+	return c.opt.ServerErrorBackoff.shouldRetry(err, retryTimeout)
+}
+
+func (c *baseClient) credentialsRotate() bool {
+	if c.opt.CredentialsProvider != nil || c.opt.CredentialsProviderContext != nil {
+		return true
+	}
+	_, _, ok := c.opt.credentials.load()
+	return ok
+}
+
 func (c *baseClient) retryBackoff(attempt int) time.Duration {
 	return internal.RetryBackoff(attempt, c.opt.MinRetryBackoff, c.opt.MaxRetryBackoff)
 }
 
-func (c *baseClient) cmdTimeout(cmd Cmder) time.Duration {
+// retryBackoffAfter returns the backoff before retrying a command that failed with the err.
+func (c *baseClient) retryBackoffAfter(attempt int, err error) time.Duration {
+	return c.opt.ServerErrorBackoff.retryBackoff(attempt, err, c.opt.MinRetryBackoff, c.opt.MaxRetryBackoff)
+}
+
+func (c *baseClient) cmdTimeout(ctx context.Context, cmd Cmder) time.Duration {
+	if timeout, ok := contextTimeout(ctx, readTimeoutKey{}); ok {
+		return timeout
+	}
 	if timeout := cmd.readTimeout(); timeout != nil {
-		t := *timeout
-		if t == 0 {
-			return 0
-		}
-		return t + 10*time.Second
+		return blockingTimeout(*timeout)
 	}
 	return c.opt.ReadTimeout
 }
@@ -511,15 +612,25 @@ func (c *baseClient) processTxPipeline(ctx context.Context, cmds []Cmder) error
 	return cmdsFirstErr(cmds)
 }
 
-type pipelineProcessor func(context.Context, *pool.Conn, []Cmder) (bool, error)
+type pipelineProcessor func(context.Context, *pool.Conn, []Cmder, *attemptTimer) (bool, error)
 
 func (c *baseClient) generalProcessPipeline(
 	ctx context.Context, cmds []Cmder, p pipelineProcessor,
 ) error {
+	if err := c.checkCmds(ctx, cmds...); err != nil {
+		setCmdsErr(cmds, err)
+		return err
+	}
+	c.opt.RetryBudget.deposit()
+	if c.opt.audit != nil {
+		start := time.Now()
+		defer func() { c.opt.audit.addCmds(cmds, time.Since(start)) }()
+	}
+
 	var lastErr error
 	for attempt := 0; attempt <= c.opt.MaxRetries; attempt++ {
 		if attempt > 0 {
-			if err := internal.Sleep(ctx, c.retryBackoff(attempt)); err != nil {
+			if err := internal.SleepClock(ctx, c.opt.Clock, c.retryBackoffAfter(attempt, lastErr)); err != nil {
 				setCmdsErr(cmds, err)
 				return err
 			}
@@ -527,31 +638,46 @@ func (c *baseClient) generalProcessPipeline(
 
 		// Enable retries by default to retry dial errors returned by withConn.
 		canRetry := true
+		timer := newAttemptTimer(c.opt.cmdStatsEnabled())
 		lastErr = c.withConn(ctx, func(ctx context.Context, cn *pool.Conn) error {
+			timer.connect()
 			var err error
-			canRetry, err = p(ctx, cn, cmds)
+			canRetry, err = p(ctx, cn, cmds, &timer)
 			return err
 		})
-		if lastErr == nil || !canRetry || !shouldRetry(lastErr, true) {
+		if !isRedisError(lastErr) {
+			c.opt.errStats.add(lastErr, timer.hasConn)
+		}
+		c.opt.errStats.addCmds(cmds)
+		timer.addStats(cmds...)
+		if lastErr == nil || !canRetry || !c.shouldRetry(lastErr, true, attempt) || attempt == c.opt.MaxRetries ||
+			!retryAllowed(c.opt.ShouldRetry, lastErr, attempt, cmds...) {
 			return lastErr
 		}
+		if !c.opt.RetryBudget.withdraw() {
+			return retryBudgetError{err: lastErr}
+		}
 	}
 	return lastErr
 }
 
 func (c *baseClient) pipelineProcessCmds(
-	ctx context.Context, cn *pool.Conn, cmds []Cmder,
+	ctx context.Context, cn *pool.Conn, cmds []Cmder, timer *attemptTimer,
 ) (bool, error) {
-	if err := cn.WithWriter(c.context(ctx), c.opt.WriteTimeout, func(wr *proto.Writer) error {
+	if err := cn.WithBatchWriter(c.context(ctx), writeTimeout(ctx, c.opt.WriteTimeout), func(wr *proto.Writer) error {
 		return writeCmds(wr, cmds)
 	}); err != nil {
 		setCmdsErr(cmds, err)
 		return true, err
 	}
+	timer.write()
+	c.logWireCmds(ctx, cn, cmds)
 
-	if err := cn.WithReader(c.context(ctx), c.opt.ReadTimeout, func(rd *proto.Reader) error {
+	err := cn.WithReader(c.context(ctx), pipelineTimeout(ctx, c.opt.ReadTimeout, cmds), func(rd *proto.Reader) error {
 		return pipelineReadCmds(rd, cmds)
-	}); err != nil {
+	})
+	c.logWireReplies(ctx, cn, cmds)
+	if err != nil {
 		return true, err
 	}
 
@@ -572,16 +698,19 @@ func pipelineReadCmds(rd *proto.Reader, cmds []Cmder) error {
 }
 
 func (c *baseClient) txPipelineProcessCmds(
-	ctx context.Context, cn *pool.Conn, cmds []Cmder,
+	ctx context.Context, cn *pool.Conn, cmds []Cmder, timer *attemptTimer,
 ) (bool, error) {
-	if err := cn.WithWriter(c.context(ctx), c.opt.WriteTimeout, func(wr *proto.Writer) error {
+	if err := cn.WithBatchWriter(c.context(ctx), writeTimeout(ctx, c.opt.WriteTimeout), func(wr *proto.Writer) error {
 		return writeCmds(wr, cmds)
 	}); err != nil {
 		setCmdsErr(cmds, err)
 		return true, err
 	}
+	timer.write()
+	c.logWireCmds(ctx, cn, cmds)
+	defer c.logWireReplies(ctx, cn, cmds)
 
-	if err := cn.WithReader(c.context(ctx), c.opt.ReadTimeout, func(rd *proto.Reader) error {
+	if err := cn.WithReader(c.context(ctx), readTimeout(ctx, c.opt.ReadTimeout), func(rd *proto.Reader) error {
 		statusCmd := cmds[0].(*StatusCmd)
 		// Trim multi and exec.
 		trimmedCmds := cmds[1 : len(cmds)-1]
@@ -646,6 +775,9 @@ type Client struct {
 	*baseClient
 	cmdable
 	hooksMixin
+
+	autoPipeliner *autoPipeliner
+	inflight      *inflight
 }
 
 // NewClient returns a client to the Redis Server specified by Options.
@@ -656,18 +788,30 @@ func NewClient(opt *Options) *Client {
 		baseClient: &baseClient{
 			opt: opt,
 		},
+		inflight: newInflight(),
 	}
 	c.init()
 	c.connPool = newConnPool(opt, c.dialHook)
+	c.initCmdsInfo()
 
 	return &c
 }
 
 func (c *Client) init() {
 	c.cmdable = c.Process
+
+	process := c.baseClient.process
+	if c.opt.AutoPipelineWindow > 0 {
+		c.autoPipeliner = newAutoPipeliner(c.opt, c.baseClient.processPipeline, c.baseClient.process)
+		process = c.autoPipeliner.processCmd
+	}
+	if c.opt.MaxKeysPerCommand > 0 {
+		process = chunkedProcess(c.opt.MaxKeysPerCommand, process, c.baseClient.processPipeline)
+	}
+
 	c.initHooks(hooks{
 		dial:       c.baseClient.dial,
-		process:    c.baseClient.process,
+		process:    process,
 		pipeline:   c.baseClient.processPipeline,
 		txPipeline: c.baseClient.processTxPipeline,
 	})
@@ -692,7 +836,9 @@ func (c *Client) Do(ctx context.Context, args ...interface{}) *Cmd {
 }
 
 func (c *Client) Process(ctx context.Context, cmd Cmder) error {
-	err := c.processHook(ctx, cmd)
+	err := c.inflight.track(func() error {
+		return c.processHook(ctx, cmd)
+	})
 	cmd.SetErr(err)
 	return err
 }
@@ -716,12 +862,24 @@ func (c *Client) Pipelined(ctx context.Context, fn func(Pipeliner) error) ([]Cmd
 
 func (c *Client) Pipeline() Pipeliner {
 	pipe := Pipeline{
-		exec: pipelineExecer(c.processPipelineHook),
+		exec: func(ctx context.Context, cmds []Cmder) error {
+			return c.inflight.track(func() error {
+				return c.processPipelineHook(ctx, cmds)
+			})
+		},
 	}
 	pipe.init()
 	return &pipe
 }
 
+// PipelineWithOptions is like Pipeline, but the pipeline is flushed automatically
+// when a PipelineOptions threshold is reached.
+func (c *Client) PipelineWithOptions(opt PipelineOptions) Pipeliner {
+	pipe := c.Pipeline().(*Pipeline)
+	pipe.opt = opt
+	return pipe
+}
+
 func (c *Client) TxPipelined(ctx context.Context, fn func(Pipeliner) error) ([]Cmder, error) {
 	return c.TxPipeline().Pipelined(ctx, fn)
 }
@@ -730,8 +888,10 @@ func (c *Client) TxPipelined(ctx context.Context, fn func(Pipeliner) error) ([]C
 func (c *Client) TxPipeline() Pipeliner {
 	pipe := Pipeline{
 		exec: func(ctx context.Context, cmds []Cmder) error {
-			cmds = wrapMultiExec(ctx, cmds)
-			return c.processTxPipelineHook(ctx, cmds)
+			return c.inflight.track(func() error {
+				cmds = wrapMultiExec(ctx, cmds)
+				return c.processTxPipelineHook(ctx, cmds)
+			})
 		},
 	}
 	pipe.init()
@@ -748,6 +908,7 @@ func (c *Client) pubSub() *PubSub {
 		closeConn: c.connPool.CloseConn,
 	}
 	pubsub.init()
+	c.inflight.addPubSub(pubsub)
 	return pubsub
 }
 
@@ -0,0 +1,157 @@
+package redis
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9/internal"
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+// ReplyReader reads the RESP reply passed to a ReplyParser.
+type ReplyReader struct {
+	rd *proto.Reader
+}
+
+// PeekReplyType returns the RESP type of the next reply without consuming it,
+// e.g. '*' for an array or '%' for a map.
+func (r *ReplyReader) PeekReplyType() (byte, error) {
+	return r.rd.PeekReplyType()
+}
+
+// ReadReply reads the next reply as a generic value, like Cmd.Val.
+func (r *ReplyReader) ReadReply() (interface{}, error) {
+	return r.rd.ReadReply()
+}
+
+// ReadString reads a string reply, converting the numbers and booleans.
+func (r *ReplyReader) ReadString() (string, error) {
+	return r.rd.ReadString()
+}
+
+// ReadInt reads an integer reply.
+func (r *ReplyReader) ReadInt() (int64, error) {
+	return r.rd.ReadInt()
+}
+
+// ReadUint reads an unsigned integer reply.
+func (r *ReplyReader) ReadUint() (uint64, error) {
+	return r.rd.ReadUint()
+}
+
+// ReadFloat reads a floating-point reply.
+func (r *ReplyReader) ReadFloat() (float64, error) {
+	return r.rd.ReadFloat()
+}
+
+// ReadBool reads a boolean reply.
+func (r *ReplyReader) ReadBool() (bool, error) {
+	return r.rd.ReadBool()
+}
+
+// ReadSlice reads an array reply as generic values.
+func (r *ReplyReader) ReadSlice() ([]interface{}, error) {
+	return r.rd.ReadSlice()
+}
+
+// ReadArrayLen reads the length of an array or set reply,
+// whose elements are read next.
+func (r *ReplyReader) ReadArrayLen() (int, error) {
+	return r.rd.ReadArrayLen()
+}
+
+// ReadFixedArrayLen reads the length of an array reply
+// and returns an error if it is not n.
+func (r *ReplyReader) ReadFixedArrayLen(n int) error {
+	return r.rd.ReadFixedArrayLen(n)
+}
+
+// ReadMapLen reads the number of the key-value pairs of a map reply,
+// which is also accepted as a flat RESP2 array.
+func (r *ReplyReader) ReadMapLen() (int, error) {
+	return r.rd.ReadMapLen()
+}
+
+// ReadFixedMapLen reads the number of the key-value pairs of a map reply
+// and returns an error if it is not n.
+func (r *ReplyReader) ReadFixedMapLen(n int) error {
+	return r.rd.ReadFixedMapLen(n)
+}
+
+// DiscardNext skips the next reply.
+func (r *ReplyReader) DiscardNext() error {
+	return r.rd.DiscardNext()
+}
+
+// ReplyParser reads the reply of a command and returns the value
+// that is available via Cmd.Val. The parser must consume the whole reply,
+// otherwise the connection is left in an undefined state.
+// A Redis error returned by the parser is stored as the command error.
+type ReplyParser func(rd *ReplyReader) (interface{}, error)
+
+type replyParserRegistry struct {
+	n       int32 // atomic
+	mu      sync.RWMutex
+	parsers map[string]ReplyParser
+}
+
+var replyParsers = &replyParserRegistry{
+	parsers: make(map[string]ReplyParser),
+}
+
+func (r *replyParserRegistry) set(name string, parser ReplyParser) {
+	name = internal.ToLower(name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if parser == nil {
+		delete(r.parsers, name)
+	} else {
+		r.parsers[name] = parser
+	}
+	atomic.StoreInt32(&r.n, int32(len(r.parsers)))
+}
+
+func (r *replyParserRegistry) get(cmd Cmder) ReplyParser {
+	if atomic.LoadInt32(&r.n) == 0 {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if parser, ok := r.parsers[cmd.FullName()]; ok {
+		return parser
+	}
+	return r.parsers[cmd.Name()]
+}
+
+// RegisterReplyParser registers the parser for replies of the command with
+// the given name, e.g. "topk.list" or "cluster links". It is used by the
+// generic commands created with Do and NewCmd, including in pipelines, so
+// exotic module replies can be parsed without native support in go-redis.
+// A nil parser unregisters it.
+//
+// RegisterReplyParser is usually called once from an init function.
+func RegisterReplyParser(name string, parser ReplyParser) {
+	replyParsers.set(name, parser)
+}
+
+// NewReplyReader returns a ReplyReader reading the replies from rd,
+// e.g. to test a ReplyParser with captured replies.
+func NewReplyReader(rd io.Reader) *ReplyReader {
+	return &ReplyReader{rd: proto.NewReader(rd)}
+}
+
+// ParseReply parses the data as the reply of the command received from
+// the server and sets the value or the error of the command, which is
+// returned. A malformed reply is returned as an error, so ParseReply is
+// also the entry point for fuzzing the parsing of the replies.
+func ParseReply(cmd Cmder, data []byte) error {
+	err := cmd.readReply(proto.NewReader(bytes.NewReader(data)))
+	cmd.SetErr(err)
+	return err
+}
@@ -0,0 +1,131 @@
+package redis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReplyParser(t *testing.T) {
+	client := NewClientStub([]byte("*2\r\n+a\r\n:1\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	pairParser := func(rd *ReplyReader) (interface{}, error) {
+		if err := rd.ReadFixedArrayLen(2); err != nil {
+			return nil, err
+		}
+		name, err := rd.ReadString()
+		if err != nil {
+			return nil, err
+		}
+		n, err := rd.ReadInt()
+		if err != nil {
+			return nil, err
+		}
+		return KeyValue{Key: name, Value: string(rune('0' + n))}, nil
+	}
+
+	RegisterReplyParser("CUSTOM.PAIR", pairParser)
+	defer RegisterReplyParser("custom.pair", nil)
+
+	val, err := client.Do(ctx, "custom.pair").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val != (KeyValue{Key: "a", Value: "1"}) {
+		t.Fatalf("got %v, wanted {a 1}", val)
+	}
+
+	// Per-call parsers take precedence.
+	cmd := NewCmd(ctx, "custom.pair")
+	cmd.SetReplyParser(func(rd *ReplyReader) (interface{}, error) {
+		return rd.ReadSlice()
+	})
+	if err := client.Process(ctx, cmd); err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := cmd.Val().([]interface{}); !ok || len(s) != 2 {
+		t.Fatalf("got %v, wanted a slice", cmd.Val())
+	}
+}
+
+func TestParseReply(t *testing.T) {
+	cmd := NewMapStringStringCmd(ctx, "hgetall", "key")
+	if err := ParseReply(cmd, []byte("%1\r\n$1\r\na\r\n$1\r\nb\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	if val := cmd.Val(); len(val) != 1 || val["a"] != "b" {
+		t.Fatalf("got %v", val)
+	}
+
+	cmd = NewMapStringStringCmd(ctx, "hgetall", "key")
+	if err := ParseReply(cmd, []byte("$9223372036854775807\r\n")); err == nil || cmd.Err() != err {
+		t.Fatalf("got %v", err)
+	}
+}
+
+// fuzzCmds are the commands parsing the replies in FuzzParseReply.
+var fuzzCmds = []func(ctx context.Context) Cmder{
+	func(ctx context.Context) Cmder { return NewCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewSliceCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewStatusCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewIntCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewIntSliceCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewFloatCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewFloatSliceCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewStringCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewStringSliceCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewBoolCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewBoolSliceCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewKeyValueSliceCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewKeyValuesCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewMapStringStringCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewMapStringIntCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewMapStringInterfaceCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewMapStringInterfaceSliceCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewStringStructMapCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewTimeCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewZSliceCmd(ctx, "cmd", "withscores") },
+	func(ctx context.Context) Cmder { return NewZWithKeyCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewXMessageSliceCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewXStreamSliceCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewXPendingCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewXPendingExtCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewXAutoClaimCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewXInfoStreamFullCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewClusterSlotsCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewClusterShardsCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewClusterLinksCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewCommandsInfoCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewGeoPosCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewSlowLogCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewClientInfoCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewACLLogCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewFunctionListCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewFunctionStatsCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewInfoCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewKeyFlagsCmd(ctx, "cmd") },
+	func(ctx context.Context) Cmder { return NewRankWithScoreCmd(ctx, "cmd") },
+}
+
+// FuzzParseReply parses the malformed replies with the commands selected
+// by the first byte of the input.
+func FuzzParseReply(f *testing.F) {
+	for i, reply := range []string{
+		"+OK\r\n",
+		":1\r\n",
+		"$5\r\nhello\r\n",
+		"*2\r\n$1\r\na\r\n$1\r\nb\r\n",
+		"%1\r\n$1\r\na\r\n:1\r\n",
+		"*1\r\n*2\r\n$3\r\n1-0\r\n*2\r\n$1\r\nf\r\n$1\r\nv\r\n",
+		"*1\r\n*3\r\n:0\r\n:5460\r\n*3\r\n$9\r\n127.0.0.1\r\n:7000\r\n$2\r\nid\r\n",
+		"-ERR oops\r\n",
+		"_\r\n",
+	} {
+		f.Add(byte(i), []byte(reply))
+	}
+
+	f.Fuzz(func(t *testing.T, which byte, data []byte) {
+		cmd := fuzzCmds[int(which)%len(fuzzCmds)](ctx)
+		_ = ParseReply(cmd, data)
+	})
+}
@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrRetryBudgetExhausted is matched by errors.Is when a failed command
+// was not retried, because the RetryBudget was exhausted.
+var ErrRetryBudgetExhausted = errors.New("redis: retry budget exhausted")
+
+// retryBudgetError wraps the error of the last attempt of a command
+// that was not retried, because the RetryBudget was exhausted.
+type retryBudgetError struct {
+	err error
+}
+
+func (e retryBudgetError) Error() string {
+	return ErrRetryBudgetExhausted.Error() + ": " + e.err.Error()
+}
+
+func (e retryBudgetError) Unwrap() error {
+	return e.err
+}
+
+func (e retryBudgetError) Is(target error) bool {
+	return target == ErrRetryBudgetExhausted
+}
+
+// RetryBudget caps retries at a fraction of the requests, so that during
+// an outage retries don't multiply the load on the servers.
+// It is a token bucket: every command or pipeline, including the commands
+// that initialize connections, adds ratio tokens and every retry takes
+// one token. A RetryBudget can be shared by several clients.
+type RetryBudget struct {
+	ratio float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+
+	retries   uint64 // atomic
+	exhausted uint64 // atomic
+}
+
+// NewRetryBudget returns a RetryBudget that allows to retry the ratio
+// of requests, e.g. 0.1 allows to retry 10% of the requests, and at most
+// burst retries in a row. The budget starts full.
+func NewRetryBudget(ratio float64, burst int) *RetryBudget {
+	return &RetryBudget{
+		ratio:  ratio,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// RetryBudgetStats contains the accumulated stats of a RetryBudget.
+type RetryBudgetStats struct {
+	Retries   uint64 // number of retries allowed by the budget
+	Exhausted uint64 // number of retries denied, because the budget was exhausted
+}
+
+// Stats returns the retry budget stats.
+func (b *RetryBudget) Stats() *RetryBudgetStats {
+	return &RetryBudgetStats{
+		Retries:   atomic.LoadUint64(&b.retries),
+		Exhausted: atomic.LoadUint64(&b.exhausted),
+	}
+}
+
+// deposit records a request.
+func (b *RetryBudget) deposit() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.tokens += b.ratio
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.mu.Unlock()
+}
+
+// withdraw reports whether a retry is allowed and takes a token if it is.
+func (b *RetryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	ok := b.tokens >= 1
+	if ok {
+		b.tokens--
+	}
+	b.mu.Unlock()
+
+	if ok {
+		atomic.AddUint64(&b.retries, 1)
+	} else {
+		atomic.AddUint64(&b.exhausted, 1)
+	}
+	return ok
+}
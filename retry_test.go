@@ -0,0 +1,195 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShouldRetry(t *testing.T) {
+	var dials int32
+	var calls []string
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			atomic.AddInt32(&dials, 1)
+			// Every command fails with io.EOF.
+			return &ConnStub{init: initHello}, nil
+		},
+		MinRetryBackoff: -1,
+		MaxRetryBackoff: -1,
+		ShouldRetry: func(err error, attempt int, cmd Cmder) bool {
+			if err != io.EOF {
+				t.Errorf("got %v, expected io.EOF", err)
+			}
+			calls = append(calls, cmd.Name())
+			return cmd.Name() != "incr"
+		},
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	if err := client.Incr(ctx, "counter").Err(); err != io.EOF {
+		t.Fatalf("got %v, expected io.EOF", err)
+	}
+	if n := atomic.LoadInt32(&dials); n != 1 {
+		t.Fatalf("INCR was attempted %d times, expected 1", n)
+	}
+
+	atomic.StoreInt32(&dials, 0)
+	if err := client.Get(ctx, "key").Err(); err != io.EOF {
+		t.Fatalf("got %v, expected io.EOF", err)
+	}
+	if n := atomic.LoadInt32(&dials); n != 4 {
+		t.Fatalf("GET was attempted %d times, expected 4", n)
+	}
+
+	atomic.StoreInt32(&dials, 0)
+	pipe := client.Pipeline()
+	pipe.Get(ctx, "key")
+	pipe.Incr(ctx, "counter")
+	if _, err := pipe.Exec(ctx); err != io.EOF {
+		t.Fatalf("got %v, expected io.EOF", err)
+	}
+	if n := atomic.LoadInt32(&dials); n != 1 {
+		t.Fatalf("pipeline with INCR was attempted %d times, expected 1", n)
+	}
+
+	if len(calls) != 6 {
+		t.Fatalf("ShouldRetry was called for %v", calls)
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	var dials int32
+	budget := NewRetryBudget(0.1, 1)
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			atomic.AddInt32(&dials, 1)
+			// Every command fails with io.EOF.
+			return &ConnStub{init: initHello}, nil
+		},
+		MinRetryBackoff:  -1,
+		MaxRetryBackoff:  -1,
+		RetryBudget:      budget,
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	// The full budget allows a single retry.
+	err := client.Get(ctx, "key").Err()
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("got %v, expected ErrRetryBudgetExhausted", err)
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Fatalf("got %v, expected the error to wrap io.EOF", err)
+	}
+	if n := atomic.LoadInt32(&dials); n != 2 {
+		t.Fatalf("GET was attempted %d times, expected 2", n)
+	}
+
+	// GET and HELLO did not refill the budget.
+	atomic.StoreInt32(&dials, 0)
+	if err := client.Get(ctx, "key").Err(); !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("got %v, expected ErrRetryBudgetExhausted", err)
+	}
+	if n := atomic.LoadInt32(&dials); n != 1 {
+		t.Fatalf("GET was attempted %d times, expected 1", n)
+	}
+
+	stats := budget.Stats()
+	if stats.Retries != 1 || stats.Exhausted != 2 {
+		t.Fatalf("got %+v, expected 1 retry and 2 exhausted", stats)
+	}
+}
+
+type writeCountingConn struct {
+	*ConnStub
+	writes *int32
+}
+
+func (c writeCountingConn) Write(b []byte) (int, error) {
+	atomic.AddInt32(c.writes, 1)
+	return c.ConnStub.Write(b)
+}
+
+func TestServerErrorBackoff(t *testing.T) {
+	newClient := func(writes *int32, backoff ServerErrorBackoff) *Client {
+		return NewClient(&Options{
+			Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				stub := &ConnStub{init: initHello, resp: []byte("-BUSY Redis is busy running a script\r\n")}
+				return writeCountingConn{ConnStub: stub, writes: writes}, nil
+			},
+			MaxRetries:         2,
+			ServerErrorBackoff: backoff,
+			DisableIndentity:   true,
+		})
+	}
+
+	var writes int32
+	client := newClient(&writes, ServerErrorBackoff{Busy: 50 * time.Millisecond})
+	defer client.Close()
+
+	start := time.Now()
+	if err := client.Get(ctx, "key").Err(); err == nil || !strings.HasPrefix(err.Error(), "BUSY ") {
+		t.Fatalf("got %v, expected BUSY error", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("took %s, expected to back off 2 times", elapsed)
+	}
+	// HELLO and 3 attempts of GET.
+	if n := atomic.LoadInt32(&writes); n != 4 {
+		t.Fatalf("got %d writes, expected 4", n)
+	}
+
+	writes = 0
+	client = newClient(&writes, ServerErrorBackoff{Busy: -1})
+	defer client.Close()
+
+	if err := client.Get(ctx, "key").Err(); err == nil || !strings.HasPrefix(err.Error(), "BUSY ") {
+		t.Fatalf("got %v, expected BUSY error", err)
+	}
+	if n := atomic.LoadInt32(&writes); n != 2 {
+		t.Fatalf("got %d writes, expected BUSY not to be retried", n)
+	}
+}
+
+func TestClusterPipelineServerErrorBackoff(t *testing.T) {
+	var writes int32
+	client := NewClusterClient(&ClusterOptions{
+		Addrs: []string{":6379"},
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			stub := &ConnStub{init: initHello, resp: []byte("-LOADING Redis is loading the dataset in memory\r\n")}
+			return writeCountingConn{ConnStub: stub, writes: &writes}, nil
+		},
+		ClusterSlots: func(context.Context) ([]ClusterSlot, error) {
+			return []ClusterSlot{{Start: 0, End: 16383, Nodes: []ClusterNode{{Addr: "127.0.0.1:6379"}}}}, nil
+		},
+		MaxRedirects:       2,
+		MinRetryBackoff:    time.Millisecond,
+		MaxRetryBackoff:    time.Millisecond,
+		ServerErrorBackoff: ServerErrorBackoff{Loading: 50 * time.Millisecond},
+		DisableIndentity:   true,
+	})
+	defer client.Close()
+
+	start := time.Now()
+	_, err := client.Pipelined(ctx, func(pipe Pipeliner) error {
+		pipe.Get(ctx, "key")
+		return nil
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "LOADING ") {
+		t.Fatalf("got %v, expected LOADING error", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("took %s, expected to back off 2 times", elapsed)
+	}
+	// HELLO and 3 attempts of the pipeline.
+	if n := atomic.LoadInt32(&writes); n != 4 {
+		t.Fatalf("got %d writes, expected 4", n)
+	}
+}
@@ -78,12 +78,21 @@ type RingOptions struct {
 	MaxRetries      int
 	MinRetryBackoff time.Duration
 	MaxRetryBackoff time.Duration
+	ShouldRetry     func(err error, attempt int, cmd Cmder) bool
+	RetryBudget     *RetryBudget // shared by all nodes
+
+	ServerErrorBackoff ServerErrorBackoff
 
 	DialTimeout           time.Duration
 	ReadTimeout           time.Duration
 	WriteTimeout          time.Duration
 	ContextTimeoutEnabled bool
 
+	AutoPipelineWindow   time.Duration
+	AutoPipelineMaxBatch int
+
+	MaxKeysPerCommand int
+
 	// PoolFIFO uses FIFO mode for each node connection pool GET/PUT (default LIFO).
 	PoolFIFO bool
 
@@ -95,15 +104,54 @@ type RingOptions struct {
 	ConnMaxIdleTime time.Duration
 	ConnMaxLifetime time.Duration
 
+	MaxReplyBufferSize int
+	MaxInternedReplies int
+	MinReadBufferSize  int
+	MaxReadBufferSize  int
+
 	TLSConfig *tls.Config
 	Limiter   Limiter
+	Logger    LeveledLogger
+	Clock     Clock
+	WireDebug bool
+
+	CmdStatsEnabled      bool
+	OnSlowCommand        func(cmd CmdInfo, dur time.Duration)
+	SlowCommandThreshold time.Duration
+	OnDial               func(ctx context.Context, addr string, stats DialStats)
+	AuditSampleRate      float64
+	AuditBufferSize      int
+	ReadOnlyClient       bool
+	Authorize            func(cmd CmdInfo) error
+	Codec                Codec
 
 	DisableIndentity bool
 	IdentitySuffix   string
 	UnstableResp3    bool
+
+	pushRouter *pushRouter
+	errStats   *errStats
+	dialStats  *dialStats
+	audit      *auditLog
 }
 
 func (opt *RingOptions) init() {
+	if opt.pushRouter == nil {
+		opt.pushRouter = newPushRouter()
+	}
+	if opt.errStats == nil {
+		opt.errStats = new(errStats)
+	}
+	if opt.dialStats == nil {
+		opt.dialStats = new(dialStats)
+	}
+	if opt.AuditBufferSize == 0 {
+		opt.AuditBufferSize = 1000
+	}
+	if opt.audit == nil && opt.AuditSampleRate > 0 {
+		opt.audit = newAuditLog(opt.AuditSampleRate, opt.AuditBufferSize)
+	}
+
 	if opt.NewClient == nil {
 		opt.NewClient = func(opt *Options) *Client {
 			return NewClient(opt)
@@ -135,6 +183,7 @@ func (opt *RingOptions) init() {
 	case 0:
 		opt.MaxRetryBackoff = 512 * time.Millisecond
 	}
+	opt.ServerErrorBackoff.init()
 }
 
 func (opt *RingOptions) clientOptions() *Options {
@@ -148,28 +197,58 @@ func (opt *RingOptions) clientOptions() *Options {
 		Password: opt.Password,
 		DB:       opt.DB,
 
-		MaxRetries: -1,
+		MaxRetries:  -1,
+		RetryBudget: opt.RetryBudget,
+
+		ServerErrorBackoff: opt.ServerErrorBackoff,
 
 		DialTimeout:           opt.DialTimeout,
 		ReadTimeout:           opt.ReadTimeout,
 		WriteTimeout:          opt.WriteTimeout,
 		ContextTimeoutEnabled: opt.ContextTimeoutEnabled,
 
-		PoolFIFO:        opt.PoolFIFO,
-		PoolSize:        opt.PoolSize,
-		PoolTimeout:     opt.PoolTimeout,
-		MinIdleConns:    opt.MinIdleConns,
-		MaxIdleConns:    opt.MaxIdleConns,
-		MaxActiveConns:  opt.MaxActiveConns,
-		ConnMaxIdleTime: opt.ConnMaxIdleTime,
-		ConnMaxLifetime: opt.ConnMaxLifetime,
+		AutoPipelineWindow:   opt.AutoPipelineWindow,
+		AutoPipelineMaxBatch: opt.AutoPipelineMaxBatch,
+
+		MaxKeysPerCommand: opt.MaxKeysPerCommand,
+
+		PoolFIFO:           opt.PoolFIFO,
+		PoolSize:           opt.PoolSize,
+		PoolTimeout:        opt.PoolTimeout,
+		MinIdleConns:       opt.MinIdleConns,
+		MaxIdleConns:       opt.MaxIdleConns,
+		MaxActiveConns:     opt.MaxActiveConns,
+		ConnMaxIdleTime:    opt.ConnMaxIdleTime,
+		ConnMaxLifetime:    opt.ConnMaxLifetime,
+		MaxReplyBufferSize: opt.MaxReplyBufferSize,
+		MaxInternedReplies: opt.MaxInternedReplies,
+		MinReadBufferSize:  opt.MinReadBufferSize,
+		MaxReadBufferSize:  opt.MaxReadBufferSize,
 
 		TLSConfig: opt.TLSConfig,
 		Limiter:   opt.Limiter,
+		Logger:    opt.Logger,
+		Clock:     opt.Clock,
+		WireDebug: opt.WireDebug,
+
+		CmdStatsEnabled:      opt.CmdStatsEnabled,
+		OnSlowCommand:        opt.OnSlowCommand,
+		SlowCommandThreshold: opt.SlowCommandThreshold,
+		OnDial:               opt.OnDial,
+		AuditSampleRate:      opt.AuditSampleRate,
+		AuditBufferSize:      opt.AuditBufferSize,
+		ReadOnlyClient:       opt.ReadOnlyClient,
+		Authorize:            opt.Authorize,
+		Codec:                opt.Codec,
 
 		DisableIndentity: opt.DisableIndentity,
 		IdentitySuffix:   opt.IdentitySuffix,
 		UnstableResp3:    opt.UnstableResp3,
+
+		pushRouter: opt.pushRouter,
+		errStats:   opt.errStats,
+		dialStats:  opt.dialStats,
+		audit:      opt.audit,
 	}
 }
 
@@ -273,7 +352,8 @@ func (c *ringSharding) SetAddrs(addrs map[string]string) {
 	cleanup := func(shards map[string]*ringShard) {
 		for addr, shard := range shards {
 			if err := shard.Client.Close(); err != nil {
-				internal.Logger.Printf(context.Background(), "shard.Close %s failed: %s", addr, err)
+				internal.Leveled(c.opt.Logger).Warn(context.Background(), "redis: shard.Close failed",
+					"addr", addr, "err", err)
 			}
 		}
 	}
@@ -413,7 +493,7 @@ func (c *ringSharding) Heartbeat(ctx context.Context, frequency time.Duration) {
 				err := shard.Client.Ping(ctx).Err()
 				isUp := err == nil || err == pool.ErrPoolTimeout
 				if shard.Vote(isUp) {
-					internal.Logger.Printf(ctx, "ring shard state changed: %s", shard)
+					internal.Leveled(c.opt.Logger).Info(ctx, "redis: ring shard state changed", "shard", shard)
 					rebalance = true
 				}
 			}
@@ -563,6 +643,11 @@ func (c *Ring) retryBackoff(attempt int) time.Duration {
 	return internal.RetryBackoff(attempt, c.opt.MinRetryBackoff, c.opt.MaxRetryBackoff)
 }
 
+// retryBackoffAfter returns the backoff before retrying a command that failed with the err.
+func (c *Ring) retryBackoffAfter(attempt int, err error) time.Duration {
+	return c.opt.ServerErrorBackoff.retryBackoff(attempt, err, c.opt.MinRetryBackoff, c.opt.MaxRetryBackoff)
+}
+
 // PoolStats returns accumulated connection pool stats.
 func (c *Ring) PoolStats() *PoolStats {
 	shards := c.sharding.List()
@@ -695,7 +780,7 @@ func (c *Ring) process(ctx context.Context, cmd Cmder) error {
 	var lastErr error
 	for attempt := 0; attempt <= c.opt.MaxRetries; attempt++ {
 		if attempt > 0 {
-			if err := internal.Sleep(ctx, c.retryBackoff(attempt)); err != nil {
+			if err := internal.SleepClock(ctx, c.opt.Clock, c.retryBackoffAfter(attempt, lastErr)); err != nil {
 				return err
 			}
 		}
@@ -706,9 +791,13 @@ func (c *Ring) process(ctx context.Context, cmd Cmder) error {
 		}
 
 		lastErr = shard.Client.Process(ctx, cmd)
-		if lastErr == nil || !shouldRetry(lastErr, cmd.readTimeout() == nil) {
+		if lastErr == nil || !c.opt.ServerErrorBackoff.shouldRetry(lastErr, cmd.readTimeout() == nil) || attempt == c.opt.MaxRetries ||
+			!retryAllowed(c.opt.ShouldRetry, lastErr, attempt, cmd) {
 			return lastErr
 		}
+		if !c.opt.RetryBudget.withdraw() {
+			return retryBudgetError{err: lastErr}
+		}
 	}
 	return lastErr
 }
@@ -725,6 +814,14 @@ func (c *Ring) Pipeline() Pipeliner {
 	return &pipe
 }
 
+// PipelineWithOptions is like Pipeline, but the pipeline is flushed automatically
+// when a PipelineOptions threshold is reached.
+func (c *Ring) PipelineWithOptions(opt PipelineOptions) Pipeliner {
+	pipe := c.Pipeline().(*Pipeline)
+	pipe.opt = opt
+	return pipe
+}
+
 func (c *Ring) TxPipelined(ctx context.Context, fn func(Pipeliner) error) ([]Cmder, error) {
 	return c.TxPipeline().Pipelined(ctx, fn)
 }
@@ -784,6 +881,14 @@ func (c *Ring) generalProcessPipeline(
 	return cmdsFirstErr(cmds)
 }
 
+// WatchRetry is like Watch, but retries fn when the transaction is aborted
+// with TxFailedErr. See Client.WatchRetry.
+func (c *Ring) WatchRetry(ctx context.Context, fn func(*Tx) error, keys ...string) error {
+	return watchRetry(ctx, c.opt.Clock, c.opt.MaxRetries, c.retryBackoff, func() error {
+		return c.Watch(ctx, fn, keys...)
+	})
+}
+
 func (c *Ring) Watch(ctx context.Context, fn func(*Tx) error, keys ...string) error {
 	if len(keys) == 0 {
 		return fmt.Errorf("redis: Watch requires at least one key")
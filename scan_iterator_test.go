@@ -0,0 +1,72 @@
+package redis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanIteratorDedup(t *testing.T) {
+	client := NewClientStub([]byte("*2\r\n$1\r\n0\r\n" +
+		"*4\r\n$1\r\na\r\n$1\r\nb\r\n$1\r\na\r\n$1\r\nc\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	var vals []string
+	iter := client.Scan(ctx, 0, "", 10).Iterator().Dedup()
+	for iter.Next(ctx) {
+		vals = append(vals, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vals, []string{"a", "b", "c"}) {
+		t.Fatalf("got %q", vals)
+	}
+}
+
+func TestHashScanIterator(t *testing.T) {
+	client := NewClientStub([]byte("*2\r\n$1\r\n0\r\n" +
+		"*6\r\n$1\r\na\r\n$1\r\n1\r\n$1\r\nb\r\n$1\r\n1\r\n$1\r\na\r\n$1\r\n2\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	var pairs []string
+	iter := client.HScan(ctx, "hash", 0, "", 10).HashIterator().Dedup()
+	for iter.Next(ctx) {
+		pairs = append(pairs, iter.Field()+"="+iter.Value())
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pairs, []string{"a=1", "b=1"}) {
+		t.Fatalf("got %q", pairs)
+	}
+}
+
+func TestZScanIterator(t *testing.T) {
+	client := NewClientStub([]byte("*2\r\n$1\r\n0\r\n" +
+		"*4\r\n$1\r\na\r\n$3\r\n1.5\r\n$1\r\nb\r\n$1\r\n2\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	var members []Z
+	iter := client.ZScan(ctx, "zset", 0, "", 10).ZIterator()
+	for iter.Next(ctx) {
+		members = append(members, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(members, []Z{{Score: 1.5, Member: "a"}, {Score: 2, Member: "b"}}) {
+		t.Fatalf("got %v", members)
+	}
+
+	client = NewClientStub([]byte("*2\r\n$1\r\n0\r\n" +
+		"*2\r\n$1\r\na\r\n$3\r\nabc\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	iter = client.ZScan(ctx, "zset", 0, "", 10).ZIterator()
+	if iter.Next(ctx) {
+		t.Fatal("expected no members")
+	}
+	if iter.Err() == nil {
+		t.Fatal("expected a score parsing error")
+	}
+}
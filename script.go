@@ -39,6 +39,8 @@ func (s *Script) Hash() string {
 	return s.hash
 }
 
+// Load loads the script into the script cache. ClusterClient loads
+// the script on every node of the cluster.
 func (s *Script) Load(ctx context.Context, c Scripter) *StringCmd {
 	return c.ScriptLoad(ctx, s.src)
 }
@@ -65,9 +67,21 @@ func (s *Script) EvalShaRO(ctx context.Context, c Scripter, keys []string, args
 
 // Run optimistically uses EVALSHA to run the script. If script does not exist
 // it is retried using EVAL.
+//
+// When c is a Pipeliner, EVALSHA is queued and the NOSCRIPT error can only be
+// observed after the pipeline is executed, so the script must be loaded before
+// using Load, e.g. once on startup:
+//
+//	if err := script.Load(ctx, rdb).Err(); err != nil {
+//		return err
+//	}
+//	_, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+//		script.Run(ctx, pipe, []string{"key"}, "value")
+//		return nil
+//	})
 func (s *Script) Run(ctx context.Context, c Scripter, keys []string, args ...interface{}) *Cmd {
 	r := s.EvalSha(ctx, c, keys, args...)
-	if HasErrorPrefix(r.Err(), "NOSCRIPT") {
+	if IsNoScript(r.Err()) {
 		return s.Eval(ctx, c, keys, args...)
 	}
 	return r
@@ -77,7 +91,7 @@ func (s *Script) Run(ctx context.Context, c Scripter, keys []string, args ...int
 // it is retried using EVAL_RO.
 func (s *Script) RunRO(ctx context.Context, c Scripter, keys []string, args ...interface{}) *Cmd {
 	r := s.EvalShaRO(ctx, c, keys, args...)
-	if HasErrorPrefix(r.Err(), "NOSCRIPT") {
+	if IsNoScript(r.Err()) {
 		return s.EvalRO(ctx, c, keys, args...)
 	}
 	return r
@@ -4,7 +4,10 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"net"
+	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -62,12 +65,21 @@ type FailoverOptions struct {
 	MaxRetries      int
 	MinRetryBackoff time.Duration
 	MaxRetryBackoff time.Duration
+	ShouldRetry     func(err error, attempt int, cmd Cmder) bool
+	RetryBudget     *RetryBudget
+
+	ServerErrorBackoff ServerErrorBackoff
 
 	DialTimeout           time.Duration
 	ReadTimeout           time.Duration
 	WriteTimeout          time.Duration
 	ContextTimeoutEnabled bool
 
+	AutoPipelineWindow   time.Duration
+	AutoPipelineMaxBatch int
+
+	MaxKeysPerCommand int
+
 	PoolFIFO bool
 
 	PoolSize        int
@@ -78,7 +90,20 @@ type FailoverOptions struct {
 	ConnMaxIdleTime time.Duration
 	ConnMaxLifetime time.Duration
 
+	MaxReplyBufferSize int
+	MaxInternedReplies int
+	MinReadBufferSize  int
+	MaxReadBufferSize  int
+
 	TLSConfig *tls.Config
+	Logger    LeveledLogger
+	Clock     Clock
+
+	// ReadOnlyClient, Authorize, Codec and auto-pipelining apply to the master
+	// and the replicas, not to the sentinels.
+	ReadOnlyClient bool
+	Authorize      func(cmd CmdInfo) error
+	Codec          Codec
 
 	DisableIndentity bool
 	IdentitySuffix   string
@@ -101,22 +126,41 @@ func (opt *FailoverOptions) clientOptions() *Options {
 		MaxRetries:      opt.MaxRetries,
 		MinRetryBackoff: opt.MinRetryBackoff,
 		MaxRetryBackoff: opt.MaxRetryBackoff,
+		ShouldRetry:     opt.ShouldRetry,
+		RetryBudget:     opt.RetryBudget,
+
+		ServerErrorBackoff: opt.ServerErrorBackoff,
 
 		DialTimeout:           opt.DialTimeout,
 		ReadTimeout:           opt.ReadTimeout,
 		WriteTimeout:          opt.WriteTimeout,
 		ContextTimeoutEnabled: opt.ContextTimeoutEnabled,
 
-		PoolFIFO:        opt.PoolFIFO,
-		PoolSize:        opt.PoolSize,
-		PoolTimeout:     opt.PoolTimeout,
-		MinIdleConns:    opt.MinIdleConns,
-		MaxIdleConns:    opt.MaxIdleConns,
-		MaxActiveConns:  opt.MaxActiveConns,
-		ConnMaxIdleTime: opt.ConnMaxIdleTime,
-		ConnMaxLifetime: opt.ConnMaxLifetime,
+		AutoPipelineWindow:   opt.AutoPipelineWindow,
+		AutoPipelineMaxBatch: opt.AutoPipelineMaxBatch,
+
+		MaxKeysPerCommand: opt.MaxKeysPerCommand,
+
+		PoolFIFO:           opt.PoolFIFO,
+		PoolSize:           opt.PoolSize,
+		PoolTimeout:        opt.PoolTimeout,
+		MinIdleConns:       opt.MinIdleConns,
+		MaxIdleConns:       opt.MaxIdleConns,
+		MaxActiveConns:     opt.MaxActiveConns,
+		ConnMaxIdleTime:    opt.ConnMaxIdleTime,
+		ConnMaxLifetime:    opt.ConnMaxLifetime,
+		MaxReplyBufferSize: opt.MaxReplyBufferSize,
+		MaxInternedReplies: opt.MaxInternedReplies,
+		MinReadBufferSize:  opt.MinReadBufferSize,
+		MaxReadBufferSize:  opt.MaxReadBufferSize,
 
 		TLSConfig: opt.TLSConfig,
+		Logger:    opt.Logger,
+		Clock:     opt.Clock,
+
+		ReadOnlyClient: opt.ReadOnlyClient,
+		Authorize:      opt.Authorize,
+		Codec:          opt.Codec,
 
 		DisableIndentity: opt.DisableIndentity,
 		IdentitySuffix:   opt.IdentitySuffix,
@@ -139,22 +183,34 @@ func (opt *FailoverOptions) sentinelOptions(addr string) *Options {
 		MaxRetries:      opt.MaxRetries,
 		MinRetryBackoff: opt.MinRetryBackoff,
 		MaxRetryBackoff: opt.MaxRetryBackoff,
+		ShouldRetry:     opt.ShouldRetry,
+		RetryBudget:     opt.RetryBudget,
+
+		ServerErrorBackoff: opt.ServerErrorBackoff,
 
 		DialTimeout:           opt.DialTimeout,
 		ReadTimeout:           opt.ReadTimeout,
 		WriteTimeout:          opt.WriteTimeout,
 		ContextTimeoutEnabled: opt.ContextTimeoutEnabled,
 
-		PoolFIFO:        opt.PoolFIFO,
-		PoolSize:        opt.PoolSize,
-		PoolTimeout:     opt.PoolTimeout,
-		MinIdleConns:    opt.MinIdleConns,
-		MaxIdleConns:    opt.MaxIdleConns,
-		MaxActiveConns:  opt.MaxActiveConns,
-		ConnMaxIdleTime: opt.ConnMaxIdleTime,
-		ConnMaxLifetime: opt.ConnMaxLifetime,
+		MaxKeysPerCommand: opt.MaxKeysPerCommand,
+
+		PoolFIFO:           opt.PoolFIFO,
+		PoolSize:           opt.PoolSize,
+		PoolTimeout:        opt.PoolTimeout,
+		MinIdleConns:       opt.MinIdleConns,
+		MaxIdleConns:       opt.MaxIdleConns,
+		MaxActiveConns:     opt.MaxActiveConns,
+		ConnMaxIdleTime:    opt.ConnMaxIdleTime,
+		ConnMaxLifetime:    opt.ConnMaxLifetime,
+		MaxReplyBufferSize: opt.MaxReplyBufferSize,
+		MaxInternedReplies: opt.MaxInternedReplies,
+		MinReadBufferSize:  opt.MinReadBufferSize,
+		MaxReadBufferSize:  opt.MaxReadBufferSize,
 
 		TLSConfig: opt.TLSConfig,
+		Logger:    opt.Logger,
+		Clock:     opt.Clock,
 
 		DisableIndentity: opt.DisableIndentity,
 		IdentitySuffix:   opt.IdentitySuffix,
@@ -180,28 +236,157 @@ func (opt *FailoverOptions) clusterOptions() *ClusterOptions {
 
 		MinRetryBackoff: opt.MinRetryBackoff,
 		MaxRetryBackoff: opt.MaxRetryBackoff,
+		ShouldRetry:     opt.ShouldRetry,
+		RetryBudget:     opt.RetryBudget,
+
+		ServerErrorBackoff: opt.ServerErrorBackoff,
 
 		DialTimeout:           opt.DialTimeout,
 		ReadTimeout:           opt.ReadTimeout,
 		WriteTimeout:          opt.WriteTimeout,
 		ContextTimeoutEnabled: opt.ContextTimeoutEnabled,
 
-		PoolFIFO:        opt.PoolFIFO,
-		PoolSize:        opt.PoolSize,
-		PoolTimeout:     opt.PoolTimeout,
-		MinIdleConns:    opt.MinIdleConns,
-		MaxIdleConns:    opt.MaxIdleConns,
-		MaxActiveConns:  opt.MaxActiveConns,
-		ConnMaxIdleTime: opt.ConnMaxIdleTime,
-		ConnMaxLifetime: opt.ConnMaxLifetime,
+		AutoPipelineWindow:   opt.AutoPipelineWindow,
+		AutoPipelineMaxBatch: opt.AutoPipelineMaxBatch,
+
+		MaxKeysPerCommand: opt.MaxKeysPerCommand,
+
+		PoolFIFO:           opt.PoolFIFO,
+		PoolSize:           opt.PoolSize,
+		PoolTimeout:        opt.PoolTimeout,
+		MinIdleConns:       opt.MinIdleConns,
+		MaxIdleConns:       opt.MaxIdleConns,
+		MaxActiveConns:     opt.MaxActiveConns,
+		ConnMaxIdleTime:    opt.ConnMaxIdleTime,
+		ConnMaxLifetime:    opt.ConnMaxLifetime,
+		MaxReplyBufferSize: opt.MaxReplyBufferSize,
+		MaxInternedReplies: opt.MaxInternedReplies,
+		MinReadBufferSize:  opt.MinReadBufferSize,
+		MaxReadBufferSize:  opt.MaxReadBufferSize,
 
 		TLSConfig: opt.TLSConfig,
+		Logger:    opt.Logger,
+		Clock:     opt.Clock,
+
+		ReadOnlyClient: opt.ReadOnlyClient,
+		Authorize:      opt.Authorize,
+		Codec:          opt.Codec,
 
 		DisableIndentity: opt.DisableIndentity,
 		IdentitySuffix:   opt.IdentitySuffix,
 	}
 }
 
+// ParseFailoverURL parses a URL into FailoverOptions that can be used to connect
+// to Redis through Sentinel. The scheme is redis-sentinel or rediss-sentinel,
+// the host lists the addresses of the sentinels, whose port defaults to 26379,
+// and the path holds the master name and optionally the database number:
+//
+//	redis-sentinel://<user>:<password>@<host1>:<port1>,<host2>:<port2>/<master>/<db>
+//
+// The user and password authenticate with the master and the replicas,
+// the "sentinel_username" and "sentinel_password" query parameters with
+// the sentinels. The other query parameters are the same as with ParseURL,
+// plus "route_by_latency", "route_randomly", "replica_only" and
+// "use_disconnected_replicas".
+//
+// Example:
+//
+//	redis-sentinel://:secret@10.0.0.1,10.0.0.2:26380/mymaster/2?dial_timeout=3&replica_only=true
+//	is equivalent to:
+//	&FailoverOptions{
+//		MasterName:    "mymaster",
+//		SentinelAddrs: []string{"10.0.0.1:26379", "10.0.0.2:26380"},
+//		Password:      "secret",
+//		DB:            2,
+//		DialTimeout:   3 * time.Second, // no time unit = seconds
+//		ReplicaOnly:   true,
+//	}
+func ParseFailoverURL(redisURL string) (*FailoverOptions, error) {
+	if !strings.HasPrefix(redisURL, "redis-sentinel://") && !strings.HasPrefix(redisURL, "rediss-sentinel://") {
+		if i := strings.Index(redisURL, "://"); i >= 0 {
+			return nil, fmt.Errorf("redis: invalid URL scheme: %s", redisURL[:i])
+		}
+		return nil, fmt.Errorf("redis: invalid URL scheme: %s", redisURL)
+	}
+
+	u, addrs, err := parseMultiHostURL(redisURL, "26379")
+	if err != nil {
+		return nil, err
+	}
+
+	o := &FailoverOptions{SentinelAddrs: addrs}
+	o.Username, o.Password = getUserPassword(u)
+
+	f := strings.FieldsFunc(u.Path, func(r rune) bool {
+		return r == '/'
+	})
+	switch len(f) {
+	case 0:
+		return nil, errors.New("redis: missing sentinel master name")
+	case 2:
+		if o.DB, err = strconv.Atoi(f[1]); err != nil {
+			return nil, fmt.Errorf("redis: invalid database number: %q", f[1])
+		}
+		fallthrough
+	case 1:
+		o.MasterName = f[0]
+	default:
+		return nil, fmt.Errorf("redis: invalid URL path: %s", u.Path)
+	}
+
+	if u.Scheme == "rediss-sentinel" {
+		o.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return setupFailoverQueryParams(u, o)
+}
+
+// setupFailoverQueryParams converts query parameters in u to option value in o.
+func setupFailoverQueryParams(u *url.URL, o *FailoverOptions) (*FailoverOptions, error) {
+	q := queryOptions{q: u.Query()}
+
+	if q.has("db") {
+		o.DB = q.int("db")
+	}
+	o.Protocol = q.int("protocol")
+	o.ClientName = q.string("client_name")
+	o.SentinelUsername = q.string("sentinel_username")
+	o.SentinelPassword = q.string("sentinel_password")
+	o.RouteByLatency = q.bool("route_by_latency")
+	o.RouteRandomly = q.bool("route_randomly")
+	o.ReplicaOnly = q.bool("replica_only")
+	o.UseDisconnectedReplicas = q.bool("use_disconnected_replicas")
+	o.MaxRetries = q.int("max_retries")
+	o.MinRetryBackoff = q.duration("min_retry_backoff")
+	o.MaxRetryBackoff = q.duration("max_retry_backoff")
+	o.DialTimeout = q.duration("dial_timeout")
+	o.ReadTimeout = q.duration("read_timeout")
+	o.WriteTimeout = q.duration("write_timeout")
+	o.PoolFIFO = q.bool("pool_fifo")
+	o.PoolSize = q.int("pool_size")
+	o.PoolTimeout = q.duration("pool_timeout")
+	o.MinIdleConns = q.int("min_idle_conns")
+	o.MaxIdleConns = q.int("max_idle_conns")
+	o.MaxActiveConns = q.int("max_active_conns")
+	o.ConnMaxIdleTime = q.duration("conn_max_idle_time")
+	o.ConnMaxLifetime = q.duration("conn_max_lifetime")
+	if o.TLSConfig != nil {
+		setupTLSParams(&q, o.TLSConfig)
+	}
+
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	// any parameters left?
+	if r := q.remaining(); len(r) > 0 {
+		return nil, fmt.Errorf("redis: unexpected option: %s", strings.Join(r, ", "))
+	}
+
+	return o, nil
+}
+
 // NewFailoverClient returns a Redis client that uses Redis Sentinel
 // for automatic failover. It's safe for concurrent use by multiple
 // goroutines.
@@ -235,6 +420,7 @@ func NewFailoverClient(failoverOpt *FailoverOptions) *Client {
 		baseClient: &baseClient{
 			opt: opt,
 		},
+		inflight: newInflight(),
 	}
 	rdb.init()
 
@@ -307,6 +493,7 @@ func NewSentinelClient(opt *Options) *SentinelClient {
 		process: c.baseClient.process,
 	})
 	c.connPool = newConnPool(opt, c.dialHook)
+	c.initCmdsInfo()
 
 	return c
 }
@@ -524,8 +711,8 @@ func (c *sentinelFailover) MasterAddr(ctx context.Context) (string, error) {
 				return "", err
 			}
 			// Continue on other errors
-			internal.Logger.Printf(ctx, "sentinel: GetMasterAddrByName name=%q failed: %s",
-				c.opt.MasterName, err)
+			internal.Leveled(c.opt.Logger).Warn(ctx, "sentinel: GetMasterAddrByName failed",
+				"name", c.opt.MasterName, "err", err)
 		} else {
 			return addr, nil
 		}
@@ -542,8 +729,8 @@ func (c *sentinelFailover) MasterAddr(ctx context.Context) (string, error) {
 				return "", err
 			}
 			// Continue on other errors
-			internal.Logger.Printf(ctx, "sentinel: GetMasterAddrByName name=%q failed: %s",
-				c.opt.MasterName, err)
+			internal.Leveled(c.opt.Logger).Warn(ctx, "sentinel: GetMasterAddrByName failed",
+				"name", c.opt.MasterName, "err", err)
 		} else {
 			return addr, nil
 		}
@@ -558,8 +745,8 @@ func (c *sentinelFailover) MasterAddr(ctx context.Context) (string, error) {
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 				return "", err
 			}
-			internal.Logger.Printf(ctx, "sentinel: GetMasterAddrByName master=%q failed: %s",
-				c.opt.MasterName, err)
+			internal.Leveled(c.opt.Logger).Warn(ctx, "sentinel: GetMasterAddrByName failed",
+				"master", c.opt.MasterName, "err", err)
 			continue
 		}
 
@@ -586,8 +773,8 @@ func (c *sentinelFailover) replicaAddrs(ctx context.Context, useDisconnected boo
 				return nil, err
 			}
 			// Continue on other errors
-			internal.Logger.Printf(ctx, "sentinel: Replicas name=%q failed: %s",
-				c.opt.MasterName, err)
+			internal.Leveled(c.opt.Logger).Warn(ctx, "sentinel: Replicas failed",
+				"name", c.opt.MasterName, "err", err)
 		} else if len(addrs) > 0 {
 			return addrs, nil
 		}
@@ -604,8 +791,8 @@ func (c *sentinelFailover) replicaAddrs(ctx context.Context, useDisconnected boo
 				return nil, err
 			}
 			// Continue on other errors
-			internal.Logger.Printf(ctx, "sentinel: Replicas name=%q failed: %s",
-				c.opt.MasterName, err)
+			internal.Leveled(c.opt.Logger).Warn(ctx, "sentinel: Replicas failed",
+				"name", c.opt.MasterName, "err", err)
 		} else if len(addrs) > 0 {
 			return addrs, nil
 		} else {
@@ -625,8 +812,8 @@ func (c *sentinelFailover) replicaAddrs(ctx context.Context, useDisconnected boo
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 				return nil, err
 			}
-			internal.Logger.Printf(ctx, "sentinel: Replicas master=%q failed: %s",
-				c.opt.MasterName, err)
+			internal.Leveled(c.opt.Logger).Warn(ctx, "sentinel: Replicas failed",
+				"master", c.opt.MasterName, "err", err)
 			continue
 		}
 		sentinelReachable = true
@@ -658,8 +845,8 @@ func (c *sentinelFailover) getMasterAddr(ctx context.Context, sentinel *Sentinel
 func (c *sentinelFailover) getReplicaAddrs(ctx context.Context, sentinel *SentinelClient) ([]string, error) {
 	addrs, err := sentinel.Replicas(ctx, c.opt.MasterName).Result()
 	if err != nil {
-		internal.Logger.Printf(ctx, "sentinel: Replicas name=%q failed: %s",
-			c.opt.MasterName, err)
+		internal.Leveled(c.opt.Logger).Warn(ctx, "sentinel: Replicas failed",
+			"name", c.opt.MasterName, "err", err)
 		return nil, err
 	}
 	return parseReplicaAddrs(addrs, false), nil
@@ -706,8 +893,8 @@ func (c *sentinelFailover) trySwitchMaster(ctx context.Context, addr string) {
 	}
 	c._masterAddr = addr
 
-	internal.Logger.Printf(ctx, "sentinel: new master=%q addr=%q",
-		c.opt.MasterName, addr)
+	internal.Leveled(c.opt.Logger).Info(ctx, "sentinel: new master",
+		"master", c.opt.MasterName, "addr", addr)
 	if c.onFailover != nil {
 		c.onFailover(ctx, addr)
 	}
@@ -727,7 +914,8 @@ func (c *sentinelFailover) setSentinel(ctx context.Context, sentinel *SentinelCl
 func (c *sentinelFailover) discoverSentinels(ctx context.Context) {
 	sentinels, err := c.sentinel.Sentinels(ctx, c.opt.MasterName).Result()
 	if err != nil {
-		internal.Logger.Printf(ctx, "sentinel: Sentinels master=%q failed: %s", c.opt.MasterName, err)
+		internal.Leveled(c.opt.Logger).Warn(ctx, "sentinel: Sentinels failed",
+			"master", c.opt.MasterName, "err", err)
 		return
 	}
 	for _, sentinel := range sentinels {
@@ -742,8 +930,8 @@ func (c *sentinelFailover) discoverSentinels(ctx context.Context) {
 		if ip != "" && port != "" {
 			sentinelAddr := net.JoinHostPort(ip, port)
 			if !contains(c.sentinelAddrs, sentinelAddr) {
-				internal.Logger.Printf(ctx, "sentinel: discovered new sentinel=%q for master=%q",
-					sentinelAddr, c.opt.MasterName)
+				internal.Leveled(c.opt.Logger).Info(ctx, "sentinel: discovered new sentinel",
+					"sentinel", sentinelAddr, "master", c.opt.MasterName)
 				c.sentinelAddrs = append(c.sentinelAddrs, sentinelAddr)
 			}
 		}
@@ -762,7 +950,8 @@ func (c *sentinelFailover) listen(pubsub *PubSub) {
 		if msg.Channel == "+switch-master" {
 			parts := strings.Split(msg.Payload, " ")
 			if parts[0] != c.opt.MasterName {
-				internal.Logger.Printf(pubsub.getContext(), "sentinel: ignore addr for master=%q", parts[0])
+				internal.Leveled(c.opt.Logger).Debug(pubsub.getContext(), "sentinel: ignore addr",
+					"master", parts[0])
 				continue
 			}
 			addr := net.JoinHostPort(parts[3], parts[4])
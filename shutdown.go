@@ -0,0 +1,134 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrShutdown is returned by the commands issued after Client.CloseGracefully
+// was called and by the receivers of the PubSubs closed by it.
+var ErrShutdown = errors.New("redis: client is shut down")
+
+// shutdownFlag is set in inflight.state when the client is shut down.
+// The other bits count the in-flight commands.
+const shutdownFlag = 1 << 62
+
+// inflight tracks the commands being executed by a client and its PubSubs,
+// so CloseGracefully can wait for the commands and close the PubSubs.
+// The commands are counted atomically, so they don't contend on a lock.
+type inflight struct {
+	state    int64         // atomic
+	idle     chan struct{} // closed when no command is in flight after shutdown
+	idleOnce sync.Once
+
+	mu       sync.Mutex
+	shutdown bool
+	pubsubs  map[*PubSub]struct{}
+}
+
+func newInflight() *inflight {
+	return &inflight{
+		idle:    make(chan struct{}),
+		pubsubs: make(map[*PubSub]struct{}),
+	}
+}
+
+// add registers a command, or returns ErrShutdown when the client is shut down.
+func (f *inflight) add() error {
+	if atomic.AddInt64(&f.state, 1)&shutdownFlag != 0 {
+		f.done()
+		return ErrShutdown
+	}
+	return nil
+}
+
+func (f *inflight) done() {
+	if atomic.AddInt64(&f.state, -1) == shutdownFlag {
+		f.signalIdle()
+	}
+}
+
+func (f *inflight) signalIdle() {
+	f.idleOnce.Do(func() {
+		close(f.idle)
+	})
+}
+
+// track runs fn as an in-flight command.
+func (f *inflight) track(fn func() error) error {
+	if err := f.add(); err != nil {
+		return err
+	}
+	defer f.done()
+	return fn()
+}
+
+// addPubSub registers the PubSub, which is closed right away when the client
+// is shut down.
+func (f *inflight) addPubSub(ps *PubSub) {
+	f.mu.Lock()
+	if f.shutdown {
+		f.mu.Unlock()
+		_ = ps.close(ErrShutdown)
+		return
+	}
+	ps.onClose = func() {
+		f.mu.Lock()
+		delete(f.pubsubs, ps)
+		f.mu.Unlock()
+	}
+	f.pubsubs[ps] = struct{}{}
+	f.mu.Unlock()
+}
+
+// stop stops accepting new commands and PubSubs, and returns the PubSubs.
+// It returns false if the client was already shut down.
+func (f *inflight) stop() ([]*PubSub, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.shutdown {
+		return nil, false
+	}
+	f.shutdown = true
+	if atomic.AddInt64(&f.state, shutdownFlag) == shutdownFlag {
+		f.signalIdle()
+	}
+	pubsubs := make([]*PubSub, 0, len(f.pubsubs))
+	for ps := range f.pubsubs {
+		pubsubs = append(pubsubs, ps)
+	}
+	return pubsubs, true
+}
+
+// CloseGracefully closes the client without failing the in-flight commands
+// (Shutdown is the SHUTDOWN command of the server). The commands issued after
+// CloseGracefully is called fail with ErrShutdown. CloseGracefully waits for
+// the in-flight commands to complete, closes the PubSubs, so their receivers
+// and channels get ErrShutdown, and then closes the connections.
+//
+// If ctx is done before the in-flight commands complete, the connections are
+// closed anyway, failing the remaining commands, and ctx.Err() is returned.
+// CloseGracefully can be called concurrently with the commands, unlike Close.
+func (c *Client) CloseGracefully(ctx context.Context) error {
+	pubsubs, ok := c.inflight.stop()
+	if !ok {
+		return ErrShutdown
+	}
+
+	var err error
+	select {
+	case <-c.inflight.idle:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	for _, ps := range pubsubs {
+		_ = ps.close(ErrShutdown)
+	}
+	if closeErr := c.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
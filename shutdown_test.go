@@ -0,0 +1,159 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockHook blocks the commands until release is closed.
+type blockHook struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockHook() *blockHook {
+	return &blockHook{
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+}
+
+func (h *blockHook) DialHook(next DialHook) DialHook {
+	return next
+}
+
+func (h *blockHook) ProcessHook(next ProcessHook) ProcessHook {
+	return func(ctx context.Context, cmd Cmder) error {
+		h.started <- struct{}{}
+		<-h.release
+		return next(ctx, cmd)
+	}
+}
+
+func (h *blockHook) ProcessPipelineHook(next ProcessPipelineHook) ProcessPipelineHook {
+	return next
+}
+
+func isShutdown(c *Client) bool {
+	c.inflight.mu.Lock()
+	defer c.inflight.mu.Unlock()
+	return c.inflight.shutdown
+}
+
+func TestCloseGracefully(t *testing.T) {
+	client := newKVServer(map[string]string{"a": "1"}).client()
+	hook := newBlockHook()
+	client.AddHook(hook)
+
+	pubsub := client.Subscribe(ctx)
+	received := make(chan error, 1)
+	go func() {
+		_, err := pubsub.Receive(ctx)
+		received <- err
+	}()
+
+	get := make(chan *StringCmd, 1)
+	go func() {
+		get <- client.Get(ctx, "a")
+	}()
+	<-hook.started
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- client.CloseGracefully(ctx)
+	}()
+
+	// The new commands are rejected while the in-flight one is waited for.
+	for !isShutdown(client) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := client.Set(ctx, "b", "2", 0).Err(); err != ErrShutdown {
+		t.Fatalf("got %v, wanted ErrShutdown", err)
+	}
+	if _, err := client.Pipelined(ctx, func(pipe Pipeliner) error {
+		pipe.Get(ctx, "a")
+		return nil
+	}); err != ErrShutdown {
+		t.Fatalf("got %v, wanted ErrShutdown", err)
+	}
+	select {
+	case err := <-closed:
+		t.Fatalf("CloseGracefully returned %v before the command completed", err)
+	default:
+	}
+
+	close(hook.release)
+	if val, err := (<-get).Result(); err != nil || val != "1" {
+		t.Fatalf("got %q, %v", val, err)
+	}
+	if err := <-closed; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-received; err != ErrShutdown {
+		t.Fatalf("got %v from the PubSub, wanted ErrShutdown", err)
+	}
+	if err := client.Subscribe(ctx).Subscribe(ctx, "ch"); err != ErrShutdown {
+		t.Fatalf("got %v, wanted ErrShutdown", err)
+	}
+	if err := client.CloseGracefully(ctx); err != ErrShutdown {
+		t.Fatalf("got %v, wanted ErrShutdown", err)
+	}
+}
+
+func TestCloseGracefullyTimeout(t *testing.T) {
+	client := newKVServer(map[string]string{"a": "1"}).client()
+
+	hook := newBlockHook()
+	client.AddHook(hook)
+	defer close(hook.release)
+	go func() {
+		_ = client.Get(ctx, "a").Err()
+	}()
+	<-hook.started
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := client.CloseGracefully(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("got %v, wanted DeadlineExceeded", err)
+	}
+	if err := client.Get(ctx, "a").Err(); err != ErrShutdown {
+		t.Fatalf("got %v, wanted ErrShutdown", err)
+	}
+}
+
+func TestPubSubChannelCloseGracefully(t *testing.T) {
+	client := newKVServer(map[string]string{}).client()
+
+	ch := client.Subscribe(ctx).Channel()
+	time.Sleep(10 * time.Millisecond)
+	if err := client.CloseGracefully(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("got a message")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the channel is not closed")
+	}
+}
+
+func TestCloseGracefullyConcurrentPubSubs(t *testing.T) {
+	client := NewClient(&Options{Addr: ":0"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = client.Subscribe(ctx).Close()
+		}()
+	}
+	if err := client.CloseGracefully(ctx); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+}
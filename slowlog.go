@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"time"
+)
+
+// CmdInfo describes a command passed to Options.OnSlowCommand
+// or Options.Authorize.
+type CmdInfo struct {
+	// Cmd is the executed command. It holds the arguments and the result.
+	Cmd Cmder
+	// Addr is the address of the server.
+	Addr string
+	// Attempts is the number of times the command was sent, including retries.
+	Attempts int
+	// PoolWait is the time spent waiting for a connection from the pool,
+	// including dialing new connections.
+	PoolWait time.Duration
+	// Network is the time spent writing the command and reading the reply.
+	Network time.Duration
+
+	// Keys are the keys accessed by the command, set for Options.Authorize.
+	// They are found using the key positions returned by COMMAND and
+	// the numkeys argument of EVAL, FCALL and their variants.
+	Keys []string
+	// KeysUnknown is set for Options.Authorize when Keys may be incomplete,
+	// i.e. for the commands unknown to the server and for the commands with
+	// movable keys that are not parsed, e.g. MIGRATE or SORT with BY or GET.
+	KeysUnknown bool
+	// Info is the info returned by COMMAND, set for Options.Authorize.
+	// It is nil for the commands unknown to the server.
+	Info *CommandInfo
+}
+
+// slowCmdTimer measures a command for Options.OnSlowCommand.
+type slowCmdTimer struct {
+	start time.Time
+	// Stats of the command before it was processed, e.g. by another cluster node.
+	stats CmdStats
+}
+
+func newSlowCmdTimer(opt *Options, cmd Cmder) *slowCmdTimer {
+	if opt.OnSlowCommand == nil {
+		return nil
+	}
+	return &slowCmdTimer{
+		start: time.Now(),
+		stats: cmd.Stats(),
+	}
+}
+
+// report calls Options.OnSlowCommand if the command exceeded Options.SlowCommandThreshold.
+func (t *slowCmdTimer) report(opt *Options, cmd Cmder) {
+	if t == nil {
+		return
+	}
+	if dur := time.Since(t.start); dur >= opt.SlowCommandThreshold {
+		stats := cmd.Stats().sub(t.stats)
+		opt.OnSlowCommand(CmdInfo{
+			Cmd:      cmd,
+			Addr:     opt.Addr,
+			Attempts: stats.Attempts,
+			PoolWait: stats.PoolWait,
+			Network:  stats.Network(),
+		}, dur)
+	}
+}
@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestOnSlowCommand(t *testing.T) {
+	var slow []CmdInfo
+	client := NewClient(&Options{
+		Addr: "stub:6379",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			time.Sleep(50 * time.Millisecond)
+			return &ConnStub{init: initHello, resp: []byte("+OK\r\n")}, nil
+		},
+		OnSlowCommand: func(cmd CmdInfo, dur time.Duration) {
+			if dur < cmd.PoolWait+cmd.Network {
+				t.Errorf("got %s, expected at least %s", dur, cmd.PoolWait+cmd.Network)
+			}
+			slow = append(slow, cmd)
+		},
+		SlowCommandThreshold: 20 * time.Millisecond,
+		DisableIndentity:     true,
+	})
+	defer client.Close()
+
+	// Dialing a new connection is slow.
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+	// The pooled connection is reused.
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(slow) != 1 {
+		t.Fatalf("got %d slow commands, expected 1", len(slow))
+	}
+	info := slow[0]
+	if info.Cmd.Name() != "ping" || info.Addr != "stub:6379" || info.Attempts != 1 {
+		t.Fatalf("got %+v, expected the slow PING", info)
+	}
+	if info.PoolWait < 50*time.Millisecond {
+		t.Fatalf("got %s, expected the pool wait to include dialing", info.PoolWait)
+	}
+}
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
@@ -17,12 +18,14 @@ type StringCmdable interface {
 	Incr(ctx context.Context, key string) *IntCmd
 	IncrBy(ctx context.Context, key string, value int64) *IntCmd
 	IncrByFloat(ctx context.Context, key string, value float64) *FloatCmd
+	IncrByDecimal(ctx context.Context, key string, value string) *StringCmd
 	LCS(ctx context.Context, q *LCSQuery) *LCSCmd
 	MGet(ctx context.Context, keys ...string) *SliceCmd
 	MSet(ctx context.Context, values ...interface{}) *StatusCmd
 	MSetNX(ctx context.Context, values ...interface{}) *BoolCmd
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd
 	SetArgs(ctx context.Context, key string, value interface{}, a SetArgs) *StatusCmd
+	SetFromReader(ctx context.Context, key string, r io.Reader, size int64, expiration time.Duration) *StatusCmd
 	SetEx(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd
 	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *BoolCmd
 	SetXX(ctx context.Context, key string, value interface{}, expiration time.Duration) *BoolCmd
@@ -112,6 +115,15 @@ func (c cmdable) IncrByFloat(ctx context.Context, key string, value float64) *Fl
 	return cmd
 }
 
+// IncrByDecimal is like IncrByFloat, but the increment and the reply are
+// decimal strings, so they are not rounded to float64, e.g. for monetary
+// counters. See StringCmd.BigFloat to parse the reply.
+func (c cmdable) IncrByDecimal(ctx context.Context, key string, value string) *StringCmd {
+	cmd := NewStringCmd(ctx, "incrbyfloat", key, value)
+	_ = c(ctx, cmd)
+	return cmd
+}
+
 func (c cmdable) LCS(ctx context.Context, q *LCSQuery) *LCSCmd {
 	cmd := NewLCSCmd(ctx, q)
 	_ = c(ctx, cmd)
@@ -183,6 +195,15 @@ func (c cmdable) Set(ctx context.Context, key string, value interface{}, expirat
 	return cmd
 }
 
+// SetFromReader is like Set, but the value of the given size is copied from r
+// to the connection in chunks instead of being materialized as a []byte first.
+// See ReaderArg for retry semantics.
+func (c cmdable) SetFromReader(
+	ctx context.Context, key string, r io.Reader, size int64, expiration time.Duration,
+) *StatusCmd {
+	return c.Set(ctx, key, &ReaderArg{R: r, Size: size}, expiration)
+}
+
 // SetArgs provides arguments for the SetArgs function.
 type SetArgs struct {
 	// Mode can be `NX` or `XX` or empty.
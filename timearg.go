@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"encoding"
+	"strconv"
+	"time"
+)
+
+// UnixTime is a time.Time encoded as the number of seconds since the Unix epoch
+// instead of time.RFC3339Nano used for the time.Time arguments, e.g. for EXPIREAT
+// or ZADD scores. It can be used as a scan destination as well:
+//
+//	rdb.Set(ctx, "updated_at", redis.UnixTime(time.Now()), 0)
+//
+//	var t redis.UnixTime
+//	err := rdb.Get(ctx, "updated_at").Scan(&t)
+type UnixTime time.Time
+
+var (
+	_ encoding.BinaryMarshaler   = UnixTime{}
+	_ encoding.BinaryUnmarshaler = (*UnixTime)(nil)
+)
+
+// Time returns t as a time.Time.
+func (t UnixTime) Time() time.Time {
+	return time.Time(t)
+}
+
+func (t UnixTime) MarshalBinary() ([]byte, error) {
+	return strconv.AppendInt(nil, time.Time(t).Unix(), 10), nil
+}
+
+func (t *UnixTime) UnmarshalBinary(b []byte) error {
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return err
+	}
+	*t = UnixTime(time.Unix(n, 0))
+	return nil
+}
+
+// UnixMilliTime is like UnixTime, but encoded as the number of milliseconds
+// since the Unix epoch.
+type UnixMilliTime time.Time
+
+var (
+	_ encoding.BinaryMarshaler   = UnixMilliTime{}
+	_ encoding.BinaryUnmarshaler = (*UnixMilliTime)(nil)
+)
+
+// Time returns t as a time.Time.
+func (t UnixMilliTime) Time() time.Time {
+	return time.Time(t)
+}
+
+func (t UnixMilliTime) MarshalBinary() ([]byte, error) {
+	return strconv.AppendInt(nil, time.Time(t).UnixMilli(), 10), nil
+}
+
+func (t *UnixMilliTime) UnmarshalBinary(b []byte) error {
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return err
+	}
+	*t = UnixMilliTime(time.UnixMilli(n))
+	return nil
+}
@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+func TestUnixTime(t *testing.T) {
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 6e6, time.UTC)
+
+	for _, c := range []struct {
+		arg    interface{}
+		wanted string
+	}{
+		{UnixTime(tm), "1704164645"},
+		{UnixMilliTime(tm), "1704164645006"},
+	} {
+		var buf bytes.Buffer
+		if err := proto.NewWriter(&buf).WriteArg(c.arg); err != nil {
+			t.Fatal(err)
+		}
+		if wanted := "$" + strconv.Itoa(len(c.wanted)) + "\r\n" + c.wanted + "\r\n"; buf.String() != wanted {
+			t.Fatalf("got %q, wanted %q", buf.String(), wanted)
+		}
+	}
+
+	var sec UnixTime
+	if err := NewStringResult("1704164645", nil).Scan(&sec); err != nil {
+		t.Fatal(err)
+	}
+	if !sec.Time().Equal(tm.Truncate(time.Second)) {
+		t.Fatalf("got %v", sec.Time())
+	}
+
+	var milli UnixMilliTime
+	if err := NewStringResult("1704164645006", nil).Scan(&milli); err != nil {
+		t.Fatal(err)
+	}
+	if !milli.Time().Equal(tm) {
+		t.Fatalf("got %v", milli.Time())
+	}
+}
+
+func TestCmdTime(t *testing.T) {
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	got, err := NewIntResult(tm.Unix(), nil).Time(time.Second)
+	if err != nil || !got.Equal(tm) {
+		t.Fatalf("got %v, %v", got, err)
+	}
+
+	got, err = NewDurationResult(time.Duration(tm.UnixMilli())*time.Millisecond, nil).Time()
+	if err != nil || !got.Equal(tm) {
+		t.Fatalf("got %v, %v", got, err)
+	}
+
+	got, err = NewDurationResult(-2, nil).Time()
+	if err != nil || !got.IsZero() {
+		t.Fatalf("got %v, %v, wanted the zero time", got, err)
+	}
+}
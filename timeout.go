@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	readTimeoutKey  struct{}
+	writeTimeoutKey struct{}
+)
+
+// blockingTimeoutSlack is added to the timeout of blocking commands like BLPOP
+// to give the server time to reply after the block expires.
+const blockingTimeoutSlack = 10 * time.Second
+
+// WithReadTimeout returns a copy of ctx that overrides Options.ReadTimeout
+// for the commands executed with it, e.g. for intrinsically slow commands:
+//
+//	ctx := redis.WithReadTimeout(ctx, time.Minute)
+//	rdb.Wait(ctx, 2, 0)
+//
+// The override also takes precedence over the timeout that is derived
+// from the timeout argument of blocking commands like BLPOP.
+// A timeout <= 0 disables the timeout.
+func WithReadTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, readTimeoutKey{}, timeout)
+}
+
+// WithWriteTimeout returns a copy of ctx that overrides Options.WriteTimeout
+// for the commands executed with it. A timeout <= 0 disables the timeout.
+func WithWriteTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, writeTimeoutKey{}, timeout)
+}
+
+// readTimeout returns the read timeout set by WithReadTimeout or timeout.
+func readTimeout(ctx context.Context, timeout time.Duration) time.Duration {
+	if d, ok := contextTimeout(ctx, readTimeoutKey{}); ok {
+		return d
+	}
+	return timeout
+}
+
+// writeTimeout returns the write timeout set by WithWriteTimeout or timeout.
+func writeTimeout(ctx context.Context, timeout time.Duration) time.Duration {
+	if d, ok := contextTimeout(ctx, writeTimeoutKey{}); ok {
+		return d
+	}
+	return timeout
+}
+
+// blockingTimeout returns the read timeout for a command that blocks
+// on the server for at most timeout. A zero timeout blocks forever.
+func blockingTimeout(timeout time.Duration) time.Duration {
+	if timeout == 0 {
+		return 0
+	}
+	return timeout + blockingTimeoutSlack
+}
+
+// pipelineTimeout returns the read timeout for a pipeline. It is extended
+// to cover the longest blocking command in the pipeline, so the read
+// does not time out before the server had a chance to reply.
+func pipelineTimeout(ctx context.Context, timeout time.Duration, cmds []Cmder) time.Duration {
+	if d, ok := contextTimeout(ctx, readTimeoutKey{}); ok {
+		return d
+	}
+	if timeout <= 0 {
+		return timeout
+	}
+	for _, cmd := range cmds {
+		t := cmd.readTimeout()
+		if t == nil {
+			continue
+		}
+		d := blockingTimeout(*t)
+		if d == 0 {
+			return 0
+		}
+		if d > timeout {
+			timeout = d
+		}
+	}
+	return timeout
+}
+
+func hasTimeoutOverride(ctx context.Context) bool {
+	_, read := contextTimeout(ctx, readTimeoutKey{})
+	_, write := contextTimeout(ctx, writeTimeoutKey{})
+	return read || write
+}
+
+func contextTimeout(ctx context.Context, key interface{}) (time.Duration, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	d, ok := ctx.Value(key).(time.Duration)
+	if !ok {
+		return 0, false
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
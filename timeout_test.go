@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestWithReadTimeout(t *testing.T) {
+	client := NewClient(&Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			cn, server := net.Pipe()
+			go func() { _, _ = io.Copy(io.Discard, server) }()
+			go func() { _, _ = server.Write(initHello) }()
+			return cn, nil
+		},
+		ReadTimeout:      time.Minute,
+		MaxRetries:       -1,
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	start := time.Now()
+	err := client.Get(WithReadTimeout(ctx, 20*time.Millisecond), "key").Err()
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+		t.Fatalf("got %v, expected a timeout error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("took %s, expected the read timeout override to be used", elapsed)
+	}
+}
+
+func TestContextTimeout(t *testing.T) {
+	if d := readTimeout(ctx, time.Second); d != time.Second {
+		t.Fatalf("got %s, expected the default timeout", d)
+	}
+	if d := readTimeout(WithReadTimeout(ctx, time.Minute), time.Second); d != time.Minute {
+		t.Fatalf("got %s, expected the override", d)
+	}
+	if d := writeTimeout(WithWriteTimeout(ctx, -1), time.Second); d != 0 {
+		t.Fatalf("got %s, expected no timeout", d)
+	}
+
+	c := &baseClient{opt: &Options{ReadTimeout: time.Second}}
+	cmd := NewStringSliceCmd(ctx, "blpop", "key", 0)
+	cmd.setReadTimeout(5 * time.Second)
+	if d := c.cmdTimeout(ctx, cmd); d != 15*time.Second {
+		t.Fatalf("got %s, expected the blocking command timeout", d)
+	}
+	if d := c.cmdTimeout(WithReadTimeout(ctx, time.Minute), cmd); d != time.Minute {
+		t.Fatalf("got %s, expected the override", d)
+	}
+}
+
+func TestPipelineTimeout(t *testing.T) {
+	get := NewStringCmd(ctx, "get", "key")
+	blpop := NewStringSliceCmd(ctx, "blpop", "key", 30)
+	blpop.setReadTimeout(30 * time.Second)
+	forever := NewStringSliceCmd(ctx, "blpop", "key", 0)
+	forever.setReadTimeout(0)
+
+	if d := pipelineTimeout(ctx, time.Second, []Cmder{get}); d != time.Second {
+		t.Fatalf("got %s, expected the default timeout", d)
+	}
+	if d := pipelineTimeout(ctx, time.Second, []Cmder{get, blpop}); d != 40*time.Second {
+		t.Fatalf("got %s, expected the blocking command timeout", d)
+	}
+	if d := pipelineTimeout(ctx, time.Minute, []Cmder{get, blpop}); d != time.Minute {
+		t.Fatalf("got %s, expected the default timeout", d)
+	}
+	if d := pipelineTimeout(ctx, time.Second, []Cmder{blpop, forever}); d != 0 {
+		t.Fatalf("got %s, expected no timeout", d)
+	}
+	if d := pipelineTimeout(ctx, -1, []Cmder{blpop}); d != -1 {
+		t.Fatalf("got %s, expected the deadline to be left unchanged", d)
+	}
+	if d := pipelineTimeout(WithReadTimeout(ctx, time.Millisecond), time.Second, []Cmder{blpop}); d != time.Millisecond {
+		t.Fatalf("got %s, expected the override", d)
+	}
+}
@@ -2,7 +2,10 @@ package redis
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/redis/go-redis/v9/internal"
 	"github.com/redis/go-redis/v9/internal/pool"
 	"github.com/redis/go-redis/v9/internal/proto"
 )
@@ -10,6 +13,16 @@ import (
 // TxFailedErr transaction redis failed.
 const TxFailedErr = proto.RedisError("redis: transaction failed")
 
+var (
+	// ErrTxDone is returned when a transaction is used after it was discarded
+	// with Tx.Discard or closed.
+	ErrTxDone = errors.New("redis: transaction has already been executed or discarded")
+
+	// ErrTxCommand is returned when MULTI, EXEC or DISCARD is sent using Tx.Process.
+	// Use Tx.TxPipelined and Tx.Discard instead.
+	ErrTxCommand = errors.New("redis: MULTI, EXEC and DISCARD are managed by Tx")
+)
+
 // Tx implements Redis transactions as described in
 // http://redis.io/topics/transactions. It's NOT safe for concurrent use
 // by multiple goroutines, because Exec resets list of watched keys.
@@ -20,6 +33,9 @@ type Tx struct {
 	cmdable
 	statefulCmdable
 	hooksMixin
+
+	// discarded is set by Discard and Close.
+	discarded bool
 }
 
 func (c *Client) newTx() *Tx {
@@ -47,6 +63,13 @@ func (c *Tx) init() {
 }
 
 func (c *Tx) Process(ctx context.Context, cmd Cmder) error {
+	switch cmd.Name() {
+	case "multi", "exec", "discard":
+		// Sending them directly would leave the sticky connection in an unknown state.
+		cmd.SetErr(ErrTxCommand)
+		return ErrTxCommand
+	}
+
 	err := c.processHook(ctx, cmd)
 	cmd.SetErr(err)
 	return err
@@ -57,22 +80,62 @@ func (c *Tx) Process(ctx context.Context, cmd Cmder) error {
 //
 // The transaction is automatically closed when fn exits.
 func (c *Client) Watch(ctx context.Context, fn func(*Tx) error, keys ...string) error {
-	tx := c.newTx()
-	defer tx.Close(ctx)
-	if len(keys) > 0 {
-		if err := tx.Watch(ctx, keys...).Err(); err != nil {
+	return c.inflight.track(func() error {
+		tx := c.newTx()
+		defer tx.Close(ctx)
+		if len(keys) > 0 {
+			if err := tx.Watch(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+		return fn(tx)
+	})
+}
+
+// WatchRetry is like Watch, but when the transaction is aborted because
+// a watched key was modified (TxFailedErr), fn is run again with backoff
+// up to MaxRetries times. fn must be idempotent: it is expected to read
+// the watched keys and queue the writes using Tx.TxPipelined.
+func (c *Client) WatchRetry(ctx context.Context, fn func(*Tx) error, keys ...string) error {
+	return watchRetry(ctx, c.opt.Clock, c.opt.MaxRetries, c.retryBackoff, func() error {
+		return c.Watch(ctx, fn, keys...)
+	})
+}
+
+func watchRetry(
+	ctx context.Context, clock Clock, maxRetries int, backoff func(attempt int) time.Duration, watch func() error,
+) error {
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := internal.SleepClock(ctx, clock, backoff(attempt)); err != nil {
+				return err
+			}
+		}
+
+		err = watch()
+		if !errors.Is(err, TxFailedErr) {
 			return err
 		}
 	}
-	return fn(tx)
+	return err
 }
 
 // Close closes the transaction, releasing any open resources.
 func (c *Tx) Close(ctx context.Context) error {
 	_ = c.Unwatch(ctx).Err()
+	c.discarded = true
 	return c.baseClient.Close()
 }
 
+// Discard abandons the transaction: the watched keys are unwatched and
+// any following attempt to execute the transaction returns ErrTxDone.
+func (c *Tx) Discard(ctx context.Context) *StatusCmd {
+	cmd := c.Unwatch(ctx)
+	c.discarded = true
+	return cmd
+}
+
 // Watch marks the keys to be watched for conditional execution
 // of a transaction.
 func (c *Tx) Watch(ctx context.Context, keys ...string) *StatusCmd {
@@ -82,6 +145,10 @@ func (c *Tx) Watch(ctx context.Context, keys ...string) *StatusCmd {
 		args[1+i] = key
 	}
 	cmd := NewStatusCmd(ctx, args...)
+	if c.discarded {
+		cmd.SetErr(ErrTxDone)
+		return cmd
+	}
 	_ = c.Process(ctx, cmd)
 	return cmd
 }
@@ -123,6 +190,10 @@ func (c *Tx) Pipelined(ctx context.Context, fn func(Pipeliner) error) ([]Cmder,
 // Exec always returns list of commands. If transaction fails
 // TxFailedErr is returned. Otherwise Exec returns an error of the first
 // failed command or nil.
+//
+// EXEC unwatches all keys, so the transactions executed again without
+// watching the keys again are not conditional. Executing a discarded
+// transaction returns ErrTxDone.
 func (c *Tx) TxPipelined(ctx context.Context, fn func(Pipeliner) error) ([]Cmder, error) {
 	return c.TxPipeline().Pipelined(ctx, fn)
 }
@@ -131,6 +202,10 @@ func (c *Tx) TxPipelined(ctx context.Context, fn func(Pipeliner) error) ([]Cmder
 func (c *Tx) TxPipeline() Pipeliner {
 	pipe := Pipeline{
 		exec: func(ctx context.Context, cmds []Cmder) error {
+			if c.discarded {
+				setCmdsErr(cmds, ErrTxDone)
+				return ErrTxDone
+			}
 			cmds = wrapMultiExec(ctx, cmds)
 			return c.processTxPipelineHook(ctx, cmds)
 		},
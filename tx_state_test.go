@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"testing"
+)
+
+func TestTxState(t *testing.T) {
+	client := NewClientStub([]byte("+OK\r\n" + // WATCH
+		"+OK\r\n+QUEUED\r\n*1\r\n+OK\r\n" + // MULTI, SET and EXEC
+		"+OK\r\n+QUEUED\r\n*1\r\n+OK\r\n" + // MULTI, SET and EXEC again
+		"+OK\r\n+OK\r\n+OK\r\n", // WATCH, UNWATCH and UNWATCH on Close
+	)).Cmdable.(*Client)
+	defer client.Close()
+
+	err := client.Watch(ctx, func(tx *Tx) error {
+		if err := tx.Process(ctx, NewStatusCmd(ctx, "multi")); err != ErrTxCommand {
+			t.Fatalf("got %v, expected ErrTxCommand", err)
+		}
+
+		if _, err := tx.TxPipelined(ctx, func(pipe Pipeliner) error {
+			pipe.Set(ctx, "key", "value", 0)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		// The transaction can be executed again after EXEC.
+		if _, err := tx.TxPipelined(ctx, func(pipe Pipeliner) error {
+			pipe.Set(ctx, "key", "value", 0)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := tx.Watch(ctx, "key").Err(); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Discard(ctx).Err(); err != nil {
+			t.Fatal(err)
+		}
+		var set *StatusCmd
+		_, err := tx.TxPipelined(ctx, func(pipe Pipeliner) error {
+			set = pipe.Set(ctx, "key", "value", 0)
+			return nil
+		})
+		if err != ErrTxDone || set.Err() != ErrTxDone {
+			t.Fatalf("got %v, expected ErrTxDone", err)
+		}
+		return tx.Watch(ctx, "key").Err()
+	}, "key")
+	if err != ErrTxDone {
+		t.Fatalf("got %v, expected ErrTxDone", err)
+	}
+}
@@ -64,6 +64,28 @@ var _ = Describe("Tx", func() {
 		Expect(n).To(Equal(int64(100)))
 	})
 
+	It("should WatchRetry", func() {
+		var attempts int
+		err := client.WatchRetry(ctx, func(tx *redis.Tx) error {
+			attempts++
+			if attempts == 1 {
+				// Modify the watched key to abort the transaction.
+				Expect(client.Set(ctx, "key", "other", 0).Err()).NotTo(HaveOccurred())
+			}
+			_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, "key", "value", 0)
+				return nil
+			})
+			return err
+		}, "key")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attempts).To(Equal(2))
+
+		val, err := client.Get(ctx, "key").Result()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(val).To(Equal("value"))
+	})
+
 	It("should discard", Label("NonRedisEnterprise"), func() {
 		err := client.Watch(ctx, func(tx *redis.Tx) error {
 			cmds, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
@@ -0,0 +1,51 @@
+package redis
+
+import "context"
+
+// ValueScanner is implemented by the commands that can scan their result
+// into a destination, e.g. StringCmd, SliceCmd and MapStringStringCmd.
+type ValueScanner interface {
+	Scan(dst interface{}) error
+}
+
+var (
+	_ ValueScanner = (*StringCmd)(nil)
+	_ ValueScanner = (*SliceCmd)(nil)
+	_ ValueScanner = (*MapStringStringCmd)(nil)
+)
+
+// ScanAs scans the result of the command into a new value of type T:
+//
+//	n, err := redis.ScanAs[int](rdb.Get(ctx, "counter"))
+//	user, err := redis.ScanAs[User](rdb.HGetAll(ctx, "user:1"))
+//
+// StringCmd supports the types supported by StringCmd.Scan, e.g. string,
+// numbers, bool, time.Time and the types implementing encoding.BinaryUnmarshaler
+// or encoding.TextUnmarshaler. SliceCmd and MapStringStringCmd support structs
+// with the fields tagged by `redis:"field"`.
+// The command must be executed, so ScanAs can't be used with pipelines
+// before they are executed.
+func ScanAs[T any](cmd ValueScanner) (T, error) {
+	var v T
+	err := cmd.Scan(&v)
+	return v, err
+}
+
+// GetAs gets the value of the key as a value of type T.
+// It returns Nil if the key does not exist. See ScanAs for the supported types.
+func GetAs[T any](ctx context.Context, c StringCmdable, key string) (T, error) {
+	return ScanAs[T](c.Get(ctx, key))
+}
+
+// HGetAllAs gets all the fields of the hash as a struct of type T
+// with the fields tagged by `redis:"field"`.
+// The zero value is returned if the key does not exist.
+func HGetAllAs[T any](ctx context.Context, c HashCmdable, key string) (T, error) {
+	return ScanAs[T](c.HGetAll(ctx, key))
+}
+
+// HMGetAs gets the fields of the hash as a struct of type T
+// with the fields tagged by `redis:"field"`.
+func HMGetAs[T any](ctx context.Context, c HashCmdable, key string, fields ...string) (T, error) {
+	return ScanAs[T](c.HMGet(ctx, key, fields...))
+}
@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetAs(t *testing.T) {
+	client := NewClientStub([]byte("$2\r\n42\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	n, err := GetAs[int](ctx, client, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 42 {
+		t.Fatalf("got %d, wanted 42", n)
+	}
+
+	client = NewClientStub([]byte("$-1\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	if _, err := GetAs[string](ctx, client, "key"); err != Nil {
+		t.Fatalf("got %v, wanted %v", err, Nil)
+	}
+}
+
+func TestHGetAllAs(t *testing.T) {
+	type user struct {
+		Name    string    `redis:"name"`
+		Age     int       `redis:"age"`
+		Admin   bool      `redis:"admin"`
+		Created time.Time `redis:"created"`
+	}
+
+	client := NewClientStub([]byte("%4\r\n" +
+		"$4\r\nname\r\n$5\r\nalice\r\n" +
+		"$3\r\nage\r\n$2\r\n30\r\n" +
+		"$5\r\nadmin\r\n$1\r\n1\r\n" +
+		"$7\r\ncreated\r\n$20\r\n2024-01-02T03:04:05Z\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	u, err := HGetAllAs[user](ctx, client, "user:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := user{
+		Name:    "alice",
+		Age:     30,
+		Admin:   true,
+		Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if u != wanted {
+		t.Fatalf("got %+v, wanted %+v", u, wanted)
+	}
+
+	client = NewClientStub([]byte("*2\r\n$5\r\nalice\r\n_\r\n")).Cmdable.(*Client)
+	defer client.Close()
+
+	u, err = HMGetAs[user](ctx, client, "user:1", "name", "age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "alice" || u.Age != 0 {
+		t.Fatalf("got %+v", u)
+	}
+}
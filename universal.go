@@ -35,12 +35,21 @@ type UniversalOptions struct {
 	MaxRetries      int
 	MinRetryBackoff time.Duration
 	MaxRetryBackoff time.Duration
+	ShouldRetry     func(err error, attempt int, cmd Cmder) bool
+	RetryBudget     *RetryBudget
+
+	ServerErrorBackoff ServerErrorBackoff
 
 	DialTimeout           time.Duration
 	ReadTimeout           time.Duration
 	WriteTimeout          time.Duration
 	ContextTimeoutEnabled bool
 
+	AutoPipelineWindow   time.Duration
+	AutoPipelineMaxBatch int
+
+	MaxKeysPerCommand int
+
 	// PoolFIFO uses FIFO mode for each node connection pool GET/PUT (default LIFO).
 	PoolFIFO bool
 
@@ -52,7 +61,18 @@ type UniversalOptions struct {
 	ConnMaxIdleTime time.Duration
 	ConnMaxLifetime time.Duration
 
+	MaxReplyBufferSize int
+	MaxInternedReplies int
+	MinReadBufferSize  int
+	MaxReadBufferSize  int
+
 	TLSConfig *tls.Config
+	Logger    LeveledLogger
+	Clock     Clock
+
+	ReadOnlyClient bool
+	Authorize      func(cmd CmdInfo) error
+	Codec          Codec
 
 	// Only cluster clients.
 
@@ -61,6 +81,9 @@ type UniversalOptions struct {
 	RouteByLatency bool
 	RouteRandomly  bool
 
+	FanOutParallelism   int
+	ReportAllNodeErrors bool
+
 	// The sentinel master name.
 	// Only failover clients.
 
@@ -92,26 +115,48 @@ func (o *UniversalOptions) Cluster() *ClusterOptions {
 		RouteByLatency: o.RouteByLatency,
 		RouteRandomly:  o.RouteRandomly,
 
+		FanOutParallelism:   o.FanOutParallelism,
+		ReportAllNodeErrors: o.ReportAllNodeErrors,
+
 		MaxRetries:      o.MaxRetries,
 		MinRetryBackoff: o.MinRetryBackoff,
 		MaxRetryBackoff: o.MaxRetryBackoff,
+		ShouldRetry:     o.ShouldRetry,
+		RetryBudget:     o.RetryBudget,
+
+		ServerErrorBackoff: o.ServerErrorBackoff,
 
 		DialTimeout:           o.DialTimeout,
 		ReadTimeout:           o.ReadTimeout,
 		WriteTimeout:          o.WriteTimeout,
 		ContextTimeoutEnabled: o.ContextTimeoutEnabled,
 
+		AutoPipelineWindow:   o.AutoPipelineWindow,
+		AutoPipelineMaxBatch: o.AutoPipelineMaxBatch,
+
+		MaxKeysPerCommand: o.MaxKeysPerCommand,
+
 		PoolFIFO: o.PoolFIFO,
 
-		PoolSize:        o.PoolSize,
-		PoolTimeout:     o.PoolTimeout,
-		MinIdleConns:    o.MinIdleConns,
-		MaxIdleConns:    o.MaxIdleConns,
-		MaxActiveConns:  o.MaxActiveConns,
-		ConnMaxIdleTime: o.ConnMaxIdleTime,
-		ConnMaxLifetime: o.ConnMaxLifetime,
+		PoolSize:           o.PoolSize,
+		PoolTimeout:        o.PoolTimeout,
+		MinIdleConns:       o.MinIdleConns,
+		MaxIdleConns:       o.MaxIdleConns,
+		MaxActiveConns:     o.MaxActiveConns,
+		ConnMaxIdleTime:    o.ConnMaxIdleTime,
+		ConnMaxLifetime:    o.ConnMaxLifetime,
+		MaxReplyBufferSize: o.MaxReplyBufferSize,
+		MaxInternedReplies: o.MaxInternedReplies,
+		MinReadBufferSize:  o.MinReadBufferSize,
+		MaxReadBufferSize:  o.MaxReadBufferSize,
 
 		TLSConfig: o.TLSConfig,
+		Logger:    o.Logger,
+		Clock:     o.Clock,
+
+		ReadOnlyClient: o.ReadOnlyClient,
+		Authorize:      o.Authorize,
+		Codec:          o.Codec,
 
 		DisableIndentity: o.DisableIndentity,
 		IdentitySuffix:   o.IdentitySuffix,
@@ -142,22 +187,41 @@ func (o *UniversalOptions) Failover() *FailoverOptions {
 		MaxRetries:      o.MaxRetries,
 		MinRetryBackoff: o.MinRetryBackoff,
 		MaxRetryBackoff: o.MaxRetryBackoff,
+		ShouldRetry:     o.ShouldRetry,
+		RetryBudget:     o.RetryBudget,
+
+		ServerErrorBackoff: o.ServerErrorBackoff,
 
 		DialTimeout:           o.DialTimeout,
 		ReadTimeout:           o.ReadTimeout,
 		WriteTimeout:          o.WriteTimeout,
 		ContextTimeoutEnabled: o.ContextTimeoutEnabled,
 
-		PoolFIFO:        o.PoolFIFO,
-		PoolSize:        o.PoolSize,
-		PoolTimeout:     o.PoolTimeout,
-		MinIdleConns:    o.MinIdleConns,
-		MaxIdleConns:    o.MaxIdleConns,
-		MaxActiveConns:  o.MaxActiveConns,
-		ConnMaxIdleTime: o.ConnMaxIdleTime,
-		ConnMaxLifetime: o.ConnMaxLifetime,
+		AutoPipelineWindow:   o.AutoPipelineWindow,
+		AutoPipelineMaxBatch: o.AutoPipelineMaxBatch,
+
+		MaxKeysPerCommand: o.MaxKeysPerCommand,
+
+		PoolFIFO:           o.PoolFIFO,
+		PoolSize:           o.PoolSize,
+		PoolTimeout:        o.PoolTimeout,
+		MinIdleConns:       o.MinIdleConns,
+		MaxIdleConns:       o.MaxIdleConns,
+		MaxActiveConns:     o.MaxActiveConns,
+		ConnMaxIdleTime:    o.ConnMaxIdleTime,
+		ConnMaxLifetime:    o.ConnMaxLifetime,
+		MaxReplyBufferSize: o.MaxReplyBufferSize,
+		MaxInternedReplies: o.MaxInternedReplies,
+		MinReadBufferSize:  o.MinReadBufferSize,
+		MaxReadBufferSize:  o.MaxReadBufferSize,
 
 		TLSConfig: o.TLSConfig,
+		Logger:    o.Logger,
+		Clock:     o.Clock,
+
+		ReadOnlyClient: o.ReadOnlyClient,
+		Authorize:      o.Authorize,
+		Codec:          o.Codec,
 
 		DisableIndentity: o.DisableIndentity,
 		IdentitySuffix:   o.IdentitySuffix,
@@ -186,22 +250,41 @@ func (o *UniversalOptions) Simple() *Options {
 		MaxRetries:      o.MaxRetries,
 		MinRetryBackoff: o.MinRetryBackoff,
 		MaxRetryBackoff: o.MaxRetryBackoff,
+		ShouldRetry:     o.ShouldRetry,
+		RetryBudget:     o.RetryBudget,
+
+		ServerErrorBackoff: o.ServerErrorBackoff,
 
 		DialTimeout:           o.DialTimeout,
 		ReadTimeout:           o.ReadTimeout,
 		WriteTimeout:          o.WriteTimeout,
 		ContextTimeoutEnabled: o.ContextTimeoutEnabled,
 
-		PoolFIFO:        o.PoolFIFO,
-		PoolSize:        o.PoolSize,
-		PoolTimeout:     o.PoolTimeout,
-		MinIdleConns:    o.MinIdleConns,
-		MaxIdleConns:    o.MaxIdleConns,
-		MaxActiveConns:  o.MaxActiveConns,
-		ConnMaxIdleTime: o.ConnMaxIdleTime,
-		ConnMaxLifetime: o.ConnMaxLifetime,
+		AutoPipelineWindow:   o.AutoPipelineWindow,
+		AutoPipelineMaxBatch: o.AutoPipelineMaxBatch,
+
+		MaxKeysPerCommand: o.MaxKeysPerCommand,
+
+		PoolFIFO:           o.PoolFIFO,
+		PoolSize:           o.PoolSize,
+		PoolTimeout:        o.PoolTimeout,
+		MinIdleConns:       o.MinIdleConns,
+		MaxIdleConns:       o.MaxIdleConns,
+		MaxActiveConns:     o.MaxActiveConns,
+		ConnMaxIdleTime:    o.ConnMaxIdleTime,
+		ConnMaxLifetime:    o.ConnMaxLifetime,
+		MaxReplyBufferSize: o.MaxReplyBufferSize,
+		MaxInternedReplies: o.MaxInternedReplies,
+		MinReadBufferSize:  o.MinReadBufferSize,
+		MaxReadBufferSize:  o.MaxReadBufferSize,
 
 		TLSConfig: o.TLSConfig,
+		Logger:    o.Logger,
+		Clock:     o.Clock,
+
+		ReadOnlyClient: o.ReadOnlyClient,
+		Authorize:      o.Authorize,
+		Codec:          o.Codec,
 
 		DisableIndentity: o.DisableIndentity,
 		IdentitySuffix:   o.IdentitySuffix,
@@ -0,0 +1,126 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9/internal"
+	"github.com/redis/go-redis/v9/internal/pool"
+)
+
+const (
+	wireArgLimit   = 64
+	wireLineLimit  = 512
+	wireReplyLimit = 256
+)
+
+// wireDebug switches wire-level debug logging, shared by all clones of the options.
+type wireDebug struct {
+	on uint32 // atomic
+}
+
+func (d *wireDebug) set(on bool) {
+	var v uint32
+	if on {
+		v = 1
+	}
+	atomic.StoreUint32(&d.on, v)
+}
+
+func (d *wireDebug) enabled() bool {
+	return d != nil && atomic.LoadUint32(&d.on) == 1
+}
+
+// SetWireDebug enables or disables logging of every command sent by the client
+// and of its reply, see Options.WireDebug. It is safe to call at any time.
+func (c *Client) SetWireDebug(on bool) {
+	c.opt.wireDebug.set(on)
+}
+
+// SetWireDebug enables or disables wire-level debug logging on all cluster nodes,
+// see Options.WireDebug. It is safe to call at any time.
+func (c *ClusterClient) SetWireDebug(on bool) {
+	c.opt.wireDebug.set(on)
+}
+
+// logWireCmds logs the commands written to the connection.
+func (c *baseClient) logWireCmds(ctx context.Context, cn *pool.Conn, cmds []Cmder) {
+	if !c.opt.wireDebug.enabled() {
+		return
+	}
+	logger := internal.LeveledDebug(c.opt.Logger)
+	for _, cmd := range cmds {
+		logger.Debug(ctx, "redis: wire >", "addr", c.opt.Addr, "conn", cn.ID(), "cmd", wireCmdString(cmd))
+	}
+}
+
+// logWireReplies logs the replies of the commands read from the connection.
+func (c *baseClient) logWireReplies(ctx context.Context, cn *pool.Conn, cmds []Cmder) {
+	if !c.opt.wireDebug.enabled() {
+		return
+	}
+	logger := internal.LeveledDebug(c.opt.Logger)
+	for _, cmd := range cmds {
+		logger.Debug(ctx, "redis: wire <", "addr", c.opt.Addr, "conn", cn.ID(), "reply", wireReplyString(cmd))
+	}
+}
+
+// wireCmdString formats the command arguments, truncating long arguments
+// and quoting the non-printable characters. The credentials of AUTH and
+// HELLO are replaced with "?".
+func wireCmdString(cmd Cmder) string {
+	b := make([]byte, 0, 64)
+	var redact int
+	if cmd.Name() == "auth" {
+		redact = len(cmd.Args())
+	}
+	for i, arg := range cmd.Args() {
+		if i > 0 {
+			b = append(b, ' ')
+		}
+		if len(b) >= wireLineLimit {
+			b = append(b, "..."...)
+			break
+		}
+		if i > 0 && redact > 0 {
+			redact--
+			b = append(b, '?')
+			continue
+		}
+		s := string(internal.AppendArg(nil, arg))
+		if cmd.Name() == "hello" && strings.EqualFold(s, "auth") {
+			// HELLO protover AUTH username password
+			redact = 2
+		}
+		b = append(b, truncateWire(s, wireArgLimit)...)
+	}
+	return string(b)
+}
+
+// wireReplyString formats the reply or the error of the command.
+func wireReplyString(cmd Cmder) string {
+	if err := cmd.Err(); err != nil {
+		return truncateWire("error: "+err.Error(), wireReplyLimit)
+	}
+	s := cmd.String()
+	// String formats the arguments followed by ": " and the value.
+	if prefix := cmdString(cmd, nil) + ": "; len(s) > len(prefix) && s[:len(prefix)] == prefix {
+		s = s[len(prefix):]
+	}
+	return truncateWire(s, wireReplyLimit)
+}
+
+func truncateWire(s string, limit int) string {
+	truncated := len(s) > limit
+	if truncated {
+		s = s[:limit]
+	}
+	q := strconv.Quote(s)
+	q = q[1 : len(q)-1]
+	if truncated {
+		q += "..."
+	}
+	return q
+}
@@ -0,0 +1,132 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9/internal/proto"
+)
+
+func TestWireDebug(t *testing.T) {
+	logger := new(testLogger)
+	client := NewClient(&Options{
+		Addr: "stub:6379",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &ConnStub{init: initHello, resp: []byte("$5\r\nhello\r\n")}, nil
+		},
+		Logger:           logger,
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	wireEntries := func() []string {
+		logger.mu.Lock()
+		defer logger.mu.Unlock()
+		var entries []string
+		for _, e := range logger.entries {
+			if strings.Contains(e, "redis: wire") {
+				entries = append(entries, e)
+			}
+		}
+		logger.entries = nil
+		return entries
+	}
+
+	if err := client.Get(ctx, "key").Err(); err != nil {
+		t.Fatal(err)
+	}
+	if entries := wireEntries(); len(entries) != 0 {
+		t.Fatalf("got %q, wanted no wire entries", entries)
+	}
+
+	client.SetWireDebug(true)
+	if err := client.Get(ctx, "key\n"+strings.Repeat("x", 100)).Err(); err != nil {
+		t.Fatal(err)
+	}
+	entries := wireEntries()
+	if len(entries) != 2 {
+		t.Fatalf("got %q, wanted 2 entries", entries)
+	}
+	// Connection ids are global, so the id is replaced before comparing.
+	connID := regexp.MustCompile(`conn [1-9][0-9]*`)
+	for i := range entries {
+		entries[i] = connID.ReplaceAllString(entries[i], "conn N")
+	}
+	wantCmd := "DEBUG redis: wire > [addr stub:6379 conn N cmd get key\\n" + strings.Repeat("x", 60) + "...]"
+	if entries[0] != wantCmd {
+		t.Fatalf("got %q, wanted %q", entries[0], wantCmd)
+	}
+	wantReply := "DEBUG redis: wire < [addr stub:6379 conn N reply hello]"
+	if entries[1] != wantReply {
+		t.Fatalf("got %q, wanted %q", entries[1], wantReply)
+	}
+
+	client.SetWireDebug(false)
+	if err := client.Get(ctx, "key").Err(); err != nil {
+		t.Fatal(err)
+	}
+	if entries := wireEntries(); len(entries) != 0 {
+		t.Fatalf("got %q, wanted no wire entries", entries)
+	}
+}
+
+func TestWireDebugCredentials(t *testing.T) {
+	logger := new(testLogger)
+	client := NewClient(&Options{
+		Addr: "stub:6379",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &ConnStub{init: initHello, resp: []byte("+PONG\r\n")}, nil
+		},
+		Username:         "u",
+		Password:         "s3cret",
+		Logger:           logger,
+		WireDebug:        true,
+		DisableIndentity: true,
+	})
+	defer client.Close()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	var hello bool
+	for _, e := range logger.entries {
+		if strings.Contains(e, "s3cret") {
+			t.Fatalf("got the password logged in %q", e)
+		}
+		hello = hello || strings.Contains(e, "cmd hello 3 auth ? ?")
+	}
+	if !hello {
+		t.Fatalf("got %q, wanted the redacted HELLO", logger.entries)
+	}
+
+	for _, test := range []struct {
+		cmd  Cmder
+		want string
+	}{
+		{NewStatusCmd(ctx, "auth", "s3cret"), "auth ?"},
+		{NewStatusCmd(ctx, "auth", "u", "s3cret"), "auth ? ?"},
+		{NewMapStringInterfaceCmd(ctx, "hello", 2, "AUTH", "u", "s3cret", "setname", "app"), "hello 2 AUTH ? ? setname app"},
+	} {
+		if got := wireCmdString(test.cmd); got != test.want {
+			t.Fatalf("got %q, wanted %q", got, test.want)
+		}
+	}
+}
+
+func TestWireReplyString(t *testing.T) {
+	cmd := NewStringCmd(ctx, "get", "key")
+	cmd.SetVal(strings.Repeat("a", 300))
+	if got := wireReplyString(cmd); got != strings.Repeat("a", wireReplyLimit)+"..." {
+		t.Fatalf("got %q", got)
+	}
+
+	cmd.SetErr(proto.RedisError("ERR unknown"))
+	if got := wireReplyString(cmd); got != "error: ERR unknown" {
+		t.Fatalf("got %q", got)
+	}
+}